@@ -0,0 +1,372 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+)
+
+// ConversionDataAnnotation keeps the fields of a v1beta1 QuotaProfile that have no v1alpha1 equivalent,
+// so that reading a profile as v1alpha1 and writing it back does not lose them.
+const ConversionDataAnnotation = "quota.dev.operator/v1beta1-conversion-data"
+
+// conversionData holds the v1beta1 fields that cannot be derived from the v1alpha1 spec.
+type conversionData struct {
+	// LabelSelector is the v1beta1 selector when it has neither matchLabels nor matchExpressions
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// ResourceQuotaNames, LimitRangeNames and ObjectNames are the entry names when they differ from the generated ones
+	ResourceQuotaNames []string `json:"resourceQuotaNames,omitempty"`
+	LimitRangeNames    []string `json:"limitRangeNames,omitempty"`
+	ObjectNames        []string `json:"objectNames,omitempty"`
+}
+
+var _ conversion.Convertible = &QuotaProfile{}
+
+// ConvertTo converts this QuotaProfile to the hub version v1beta1.
+func (src *QuotaProfile) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.QuotaProfile)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 QuotaProfile but got %T", dstRaw)
+	}
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	data := conversionData{}
+	if raw, found := dst.Annotations[ConversionDataAnnotation]; found {
+		if err := json.Unmarshal([]byte(raw), &data); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", ConversionDataAnnotation, err)
+		}
+		delete(dst.Annotations, ConversionDataAnnotation)
+	}
+
+	dst.Spec = v1beta1.QuotaProfileSpec{
+		Precedence:     int32(src.Spec.Precedence),
+		DeletionPolicy: v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+		ShrinkPolicy:   v1beta1.QuotaShrinkPolicy(src.Spec.ShrinkPolicy),
+		ConflictPolicy: v1beta1.ConflictPolicy(src.Spec.ConflictPolicy),
+	}
+	if src.Spec.NamespaceSelector.MatchName != nil {
+		dst.Spec.NamespaceSelector.Name = lo.ToPtr(*src.Spec.NamespaceSelector.MatchName)
+	}
+	dst.Spec.NamespaceSelector.Annotations = maps.Clone(src.Spec.NamespaceSelector.MatchAnnotations)
+	if src.Spec.NamespaceSelector.MatchNamePrefix != nil {
+		dst.Spec.NamespaceSelector.NamePrefix = lo.ToPtr(*src.Spec.NamespaceSelector.MatchNamePrefix)
+	}
+	if src.Spec.NamespaceSelector.MinAge != nil {
+		dst.Spec.NamespaceSelector.MinAge = lo.ToPtr(*src.Spec.NamespaceSelector.MinAge)
+	}
+
+	// the kept selector only applies while no labels were set through v1alpha1
+	switch selector := src.Spec.NamespaceSelector; {
+	case len(selector.MatchLabels) > 0 || len(selector.MatchExpressions) > 0:
+		dst.Spec.NamespaceSelector.LabelSelector = &metav1.LabelSelector{
+			MatchLabels:      maps.Clone(selector.MatchLabels),
+			MatchExpressions: deepCopyRequirements(selector.MatchExpressions),
+		}
+	case data.LabelSelector != nil:
+		dst.Spec.NamespaceSelector.LabelSelector = data.LabelSelector
+	}
+
+	for i, spec := range src.Spec.ResourceQuotaSpecs {
+		dst.Spec.ResourceQuotas = append(dst.Spec.ResourceQuotas, v1beta1.ResourceQuotaTemplate{
+			Name:              entryName(data.ResourceQuotaNames, resourceQuotaNamePrefix, i),
+			ResourceQuotaSpec: *spec.DeepCopy(),
+		})
+	}
+	for i, spec := range src.Spec.LimitRangeSpecs {
+		dst.Spec.LimitRanges = append(dst.Spec.LimitRanges, v1beta1.LimitRangeTemplate{
+			Name:           entryName(data.LimitRangeNames, limitRangeNamePrefix, i),
+			LimitRangeSpec: *spec.DeepCopy(),
+		})
+	}
+	for i, template := range src.Spec.ObjectTemplates {
+		dst.Spec.Objects = append(dst.Spec.Objects, v1beta1.ObjectTemplate{
+			Name:     entryName(data.ObjectNames, objectNamePrefix, i),
+			Template: *template.Template.DeepCopy(),
+		})
+	}
+
+	dst.Spec.AggregateHard = src.Spec.AggregateHard.DeepCopy()
+
+	if recommendation := src.Spec.Recommendation; recommendation != nil {
+		dst.Spec.Recommendation = &v1beta1.RecommendationPolicy{
+			HeadroomPercent: recommendation.HeadroomPercent,
+			Min:             recommendation.Min.DeepCopy(),
+			Max:             recommendation.Max.DeepCopy(),
+			AutoApply:       recommendation.AutoApply,
+		}
+	}
+
+	if requests := src.Spec.QuotaRequests; requests != nil {
+		dst.Spec.QuotaRequests = &v1beta1.QuotaRequestPolicy{
+			Ceiling:     requests.Ceiling.DeepCopy(),
+			AutoApprove: requests.AutoApprove.DeepCopy(),
+		}
+	}
+
+	if budget := src.Spec.Budget; budget != nil {
+		dst.Spec.Budget = &v1beta1.QuotaBudget{
+			Hard:        budget.Hard.DeepCopy(),
+			Strategy:    v1beta1.BudgetStrategy(budget.Strategy),
+			WeightLabel: budget.WeightLabel,
+		}
+		if budget.Allocations != nil {
+			dst.Spec.Budget.Allocations = make(map[string]v1.ResourceList, len(budget.Allocations))
+			for name, allocation := range budget.Allocations {
+				dst.Spec.Budget.Allocations[name] = allocation.DeepCopy()
+			}
+		}
+	}
+
+	if rollout := src.Spec.Rollout; rollout != nil {
+		dst.Spec.Rollout = &v1beta1.RolloutStrategy{
+			MaxNamespacesPerInterval: rollout.MaxNamespacesPerInterval,
+			Interval:                 rollout.Interval,
+			CanarySelector:           rollout.CanarySelector.DeepCopy(),
+			MaxFailedAdmissions:      rollout.MaxFailedAdmissions,
+		}
+	}
+
+	dst.Status = v1beta1.QuotaProfileStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		BoundNamespaces:    src.Status.BoundNamespaces,
+		Conditions:         slices.Clone(src.Status.Conditions),
+	}
+	if rollout := src.Status.Rollout; rollout != nil {
+		dst.Status.Rollout = &v1beta1.RolloutStatus{
+			ObservedGeneration: rollout.ObservedGeneration,
+			StartTime:          rollout.StartTime.DeepCopy(),
+			LastBatchTime:      rollout.LastBatchTime.DeepCopy(),
+			UpdatedNamespaces:  rollout.UpdatedNamespaces,
+			TotalNamespaces:    rollout.TotalNamespaces,
+			FailedAdmissions:   rollout.FailedAdmissions,
+			Paused:             rollout.Paused,
+			Message:            rollout.Message,
+		}
+	}
+	if drift := src.Status.Drift; drift != nil {
+		dst.Status.Drift = &v1beta1.DriftStatus{
+			LastCheckTime:  *drift.LastCheckTime.DeepCopy(),
+			DriftedObjects: drift.DriftedObjects,
+			Corrected:      drift.Corrected,
+			Objects:        slices.Clone(drift.Objects),
+		}
+	}
+	for _, conflict := range src.Status.Conflicts {
+		dst.Status.Conflicts = append(dst.Status.Conflicts, v1beta1.NamespaceConflict{
+			Namespace: conflict.Namespace,
+			BoundTo:   conflict.BoundTo,
+			Trace:     slices.Clone(conflict.Trace),
+		})
+	}
+	for _, member := range src.Status.MemberClusters {
+		dst.Status.MemberClusters = append(dst.Status.MemberClusters, v1beta1.MemberClusterStatus{
+			Name:               member.Name,
+			ObservedGeneration: member.ObservedGeneration,
+			BoundNamespaces:    member.BoundNamespaces,
+			Error:              member.Error,
+			LastSyncTime:       member.LastSyncTime.DeepCopy(),
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version v1beta1 to this QuotaProfile.
+func (dst *QuotaProfile) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.QuotaProfile)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 QuotaProfile but got %T", srcRaw)
+	}
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	delete(dst.Annotations, ConversionDataAnnotation)
+	data := conversionData{}
+
+	dst.Spec = QuotaProfileSpec{
+		Precedence:     uint16(src.Spec.Precedence),
+		DeletionPolicy: DeletionPolicy(src.Spec.DeletionPolicy),
+		ShrinkPolicy:   QuotaShrinkPolicy(src.Spec.ShrinkPolicy),
+		ConflictPolicy: ConflictPolicy(src.Spec.ConflictPolicy),
+	}
+	if src.Spec.NamespaceSelector.Name != nil {
+		dst.Spec.NamespaceSelector.MatchName = lo.ToPtr(*src.Spec.NamespaceSelector.Name)
+	}
+	dst.Spec.NamespaceSelector.MatchAnnotations = maps.Clone(src.Spec.NamespaceSelector.Annotations)
+	if src.Spec.NamespaceSelector.NamePrefix != nil {
+		dst.Spec.NamespaceSelector.MatchNamePrefix = lo.ToPtr(*src.Spec.NamespaceSelector.NamePrefix)
+	}
+	if src.Spec.NamespaceSelector.MinAge != nil {
+		dst.Spec.NamespaceSelector.MinAge = lo.ToPtr(*src.Spec.NamespaceSelector.MinAge)
+	}
+	if selector := src.Spec.NamespaceSelector.LabelSelector; selector != nil {
+		dst.Spec.NamespaceSelector.MatchLabels = maps.Clone(selector.MatchLabels)
+		dst.Spec.NamespaceSelector.MatchExpressions = deepCopyRequirements(selector.MatchExpressions)
+		if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+			data.LabelSelector = selector.DeepCopy()
+		}
+	}
+
+	for i, entry := range src.Spec.ResourceQuotas {
+		dst.Spec.ResourceQuotaSpecs = append(dst.Spec.ResourceQuotaSpecs, *entry.ResourceQuotaSpec.DeepCopy())
+		if entry.Name != entryName(nil, resourceQuotaNamePrefix, i) {
+			data.ResourceQuotaNames = lo.Map(src.Spec.ResourceQuotas, func(e v1beta1.ResourceQuotaTemplate, _ int) string { return e.Name })
+		}
+	}
+	for i, entry := range src.Spec.LimitRanges {
+		dst.Spec.LimitRangeSpecs = append(dst.Spec.LimitRangeSpecs, *entry.LimitRangeSpec.DeepCopy())
+		if entry.Name != entryName(nil, limitRangeNamePrefix, i) {
+			data.LimitRangeNames = lo.Map(src.Spec.LimitRanges, func(e v1beta1.LimitRangeTemplate, _ int) string { return e.Name })
+		}
+	}
+	for i, entry := range src.Spec.Objects {
+		dst.Spec.ObjectTemplates = append(dst.Spec.ObjectTemplates, ObjectTemplate{Template: *entry.Template.DeepCopy()})
+		if entry.Name != entryName(nil, objectNamePrefix, i) {
+			data.ObjectNames = lo.Map(src.Spec.Objects, func(e v1beta1.ObjectTemplate, _ int) string { return e.Name })
+		}
+	}
+
+	dst.Spec.AggregateHard = src.Spec.AggregateHard.DeepCopy()
+
+	if recommendation := src.Spec.Recommendation; recommendation != nil {
+		dst.Spec.Recommendation = &RecommendationPolicy{
+			HeadroomPercent: recommendation.HeadroomPercent,
+			Min:             recommendation.Min.DeepCopy(),
+			Max:             recommendation.Max.DeepCopy(),
+			AutoApply:       recommendation.AutoApply,
+		}
+	}
+
+	if requests := src.Spec.QuotaRequests; requests != nil {
+		dst.Spec.QuotaRequests = &QuotaRequestPolicy{
+			Ceiling:     requests.Ceiling.DeepCopy(),
+			AutoApprove: requests.AutoApprove.DeepCopy(),
+		}
+	}
+
+	if budget := src.Spec.Budget; budget != nil {
+		dst.Spec.Budget = &QuotaBudget{
+			Hard:        budget.Hard.DeepCopy(),
+			Strategy:    BudgetStrategy(budget.Strategy),
+			WeightLabel: budget.WeightLabel,
+		}
+		if budget.Allocations != nil {
+			dst.Spec.Budget.Allocations = make(map[string]v1.ResourceList, len(budget.Allocations))
+			for name, allocation := range budget.Allocations {
+				dst.Spec.Budget.Allocations[name] = allocation.DeepCopy()
+			}
+		}
+	}
+
+	if rollout := src.Spec.Rollout; rollout != nil {
+		dst.Spec.Rollout = &RolloutStrategy{
+			MaxNamespacesPerInterval: rollout.MaxNamespacesPerInterval,
+			Interval:                 rollout.Interval,
+			CanarySelector:           rollout.CanarySelector.DeepCopy(),
+			MaxFailedAdmissions:      rollout.MaxFailedAdmissions,
+		}
+	}
+
+	dst.Status = QuotaProfileStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		BoundNamespaces:    src.Status.BoundNamespaces,
+		Conditions:         slices.Clone(src.Status.Conditions),
+	}
+	if rollout := src.Status.Rollout; rollout != nil {
+		dst.Status.Rollout = &RolloutStatus{
+			ObservedGeneration: rollout.ObservedGeneration,
+			StartTime:          rollout.StartTime.DeepCopy(),
+			LastBatchTime:      rollout.LastBatchTime.DeepCopy(),
+			UpdatedNamespaces:  rollout.UpdatedNamespaces,
+			TotalNamespaces:    rollout.TotalNamespaces,
+			FailedAdmissions:   rollout.FailedAdmissions,
+			Paused:             rollout.Paused,
+			Message:            rollout.Message,
+		}
+	}
+	if drift := src.Status.Drift; drift != nil {
+		dst.Status.Drift = &DriftStatus{
+			LastCheckTime:  *drift.LastCheckTime.DeepCopy(),
+			DriftedObjects: drift.DriftedObjects,
+			Corrected:      drift.Corrected,
+			Objects:        slices.Clone(drift.Objects),
+		}
+	}
+	for _, conflict := range src.Status.Conflicts {
+		dst.Status.Conflicts = append(dst.Status.Conflicts, NamespaceConflict{
+			Namespace: conflict.Namespace,
+			BoundTo:   conflict.BoundTo,
+			Trace:     slices.Clone(conflict.Trace),
+		})
+	}
+	for _, member := range src.Status.MemberClusters {
+		dst.Status.MemberClusters = append(dst.Status.MemberClusters, MemberClusterStatus{
+			Name:               member.Name,
+			ObservedGeneration: member.ObservedGeneration,
+			BoundNamespaces:    member.BoundNamespaces,
+			Error:              member.Error,
+			LastSyncTime:       member.LastSyncTime.DeepCopy(),
+		})
+	}
+
+	if data.LabelSelector == nil && data.ResourceQuotaNames == nil && data.LimitRangeNames == nil && data.ObjectNames == nil {
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[ConversionDataAnnotation] = string(raw)
+	return nil
+}
+
+const (
+	resourceQuotaNamePrefix = "rq"
+	limitRangeNamePrefix    = "lr"
+	objectNamePrefix        = "obj"
+)
+
+// deepCopyRequirements copies label selector requirements, keeping nil as nil.
+func deepCopyRequirements(requirements []metav1.LabelSelectorRequirement) []metav1.LabelSelectorRequirement {
+	if requirements == nil {
+		return nil
+	}
+	return lo.Map(requirements, func(r metav1.LabelSelectorRequirement, _ int) metav1.LabelSelectorRequirement {
+		return *r.DeepCopy()
+	})
+}
+
+// entryName returns the v1beta1 name of the unnamed v1alpha1 entry at index i, which is the kept name
+// when there is one and otherwise generated from its position, e.g. rq-0.
+func entryName(names []string, prefix string, i int) string {
+	if i < len(names) {
+		return names[i]
+	}
+	return fmt.Sprintf("%s-%d", prefix, i)
+}
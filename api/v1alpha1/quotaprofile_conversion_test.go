@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	fuzz "github.com/google/gofuzz"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+)
+
+const fuzzIterations = 1000
+
+func newFuzzer(t *testing.T) *fuzz.Fuzzer {
+	seed := rand.Int63()
+	t.Logf("fuzzer seed %d", seed)
+
+	scheme := runtime.NewScheme()
+	lo.Must0(AddToScheme(scheme))
+	lo.Must0(v1beta1.AddToScheme(scheme))
+
+	return fuzzer.FuzzerFor(fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, conversionFuzzerFuncs),
+		rand.NewSource(seed), serializer.NewCodecFactory(scheme))
+}
+
+// conversionFuzzerFuncs keeps the fuzzed values within what the API server accepts.
+func conversionFuzzerFuncs(_ serializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(spec *v1beta1.QuotaProfileSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(spec)
+			spec.Precedence = c.Int31n(65536)
+		},
+		func(hard *v1.ResourceList, c fuzz.Continue) {
+			*hard = v1.ResourceList{}
+			for range c.Intn(3) {
+				(*hard)[v1.ResourceName(c.RandString())] = *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)
+			}
+		},
+	}
+}
+
+func TestConvertRoundTripFromV1alpha1(t *testing.T) {
+	f := newFuzzer(t)
+	for range fuzzIterations {
+		original := &QuotaProfile{}
+		f.Fuzz(original)
+
+		hub := &v1beta1.QuotaProfile{}
+		if err := original.DeepCopy().ConvertTo(hub); err != nil {
+			t.Fatalf("failed to convert to v1beta1: %v", err)
+		}
+		converted := &QuotaProfile{}
+		if err := converted.ConvertFrom(hub); err != nil {
+			t.Fatalf("failed to convert from v1beta1: %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("v1alpha1 changed in the round trip (-original +converted):\n%s", cmp.Diff(original, converted))
+		}
+	}
+}
+
+func TestConvertRoundTripFromV1beta1(t *testing.T) {
+	f := newFuzzer(t)
+	for range fuzzIterations {
+		original := &v1beta1.QuotaProfile{}
+		f.Fuzz(original)
+
+		spoke := &QuotaProfile{}
+		if err := spoke.ConvertFrom(original.DeepCopy()); err != nil {
+			t.Fatalf("failed to convert from v1beta1: %v", err)
+		}
+		converted := &v1beta1.QuotaProfile{}
+		if err := spoke.ConvertTo(converted); err != nil {
+			t.Fatalf("failed to convert to v1beta1: %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(original, converted) {
+			t.Fatalf("v1beta1 changed in the round trip (-original +converted):\n%s", cmp.Diff(original, converted))
+		}
+	}
+}
+
+func TestConvertKeepsLabelExpressionsAndEntryNames(t *testing.T) {
+	expressions := []metav1.LabelSelectorRequirement{
+		{Key: "environment", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev", "test"}},
+	}
+	original := &v1beta1.QuotaProfile{Spec: v1beta1.QuotaProfileSpec{
+		NamespaceSelector: v1beta1.NamespaceSelector{LabelSelector: &metav1.LabelSelector{
+			MatchLabels:      map[string]string{"team": "a"},
+			MatchExpressions: expressions,
+		}},
+		ResourceQuotas: []v1beta1.ResourceQuotaTemplate{{Name: "compute"}},
+	}}
+
+	spoke := &QuotaProfile{}
+	if err := spoke.ConvertFrom(original); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(spoke.Spec.NamespaceSelector.MatchExpressions, expressions) {
+		t.Errorf("expected expressions %v, got %v", expressions, spoke.Spec.NamespaceSelector.MatchExpressions)
+	}
+	if _, found := spoke.Annotations[ConversionDataAnnotation]; !found {
+		t.Fatalf("expected the %s annotation", ConversionDataAnnotation)
+	}
+
+	spoke.Spec.NamespaceSelector.MatchLabels = map[string]string{"team": "b"}
+	spoke.Spec.ResourceQuotaSpecs = append(spoke.Spec.ResourceQuotaSpecs, v1.ResourceQuotaSpec{})
+	converted := &v1beta1.QuotaProfile{}
+	if err := spoke.ConvertTo(converted); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}, MatchExpressions: expressions}
+	if !equality.Semantic.DeepEqual(converted.Spec.NamespaceSelector.LabelSelector, expectedSelector) {
+		t.Errorf("expected selector %v, got %v", expectedSelector, converted.Spec.NamespaceSelector.LabelSelector)
+	}
+	names := lo.Map(converted.Spec.ResourceQuotas, func(e v1beta1.ResourceQuotaTemplate, _ int) string { return e.Name })
+	if !equality.Semantic.DeepEqual(names, []string{"compute", "rq-1"}) {
+		t.Errorf("expected resource quota names [compute rq-1], got %v", names)
+	}
+	if _, found := converted.Annotations[ConversionDataAnnotation]; found {
+		t.Errorf("expected the %s annotation to be removed", ConversionDataAnnotation)
+	}
+}
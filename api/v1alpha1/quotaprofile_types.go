@@ -19,6 +19,8 @@ package v1alpha1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
@@ -30,32 +32,470 @@ const (
 
 	// QuotaProfileLastUpdateTimestamp is used to track when the namespace quota configuration was last updated. Label is added to the namespace when the quota profile is applied.
 	QuotaProfileLastUpdateTimestamp = "quota.dev.operator/profile-last-update-timestamp"
+
+	// QuotaProfileRetainedLabelKey marks a namespace whose profile was deleted with the Retain policy.
+	// The managed resources of such a namespace are kept until another profile binds to it.
+	QuotaProfileRetainedLabelKey = "quota.dev.operator/profile-retained"
+
+	// QuotaProfileGenerationLabelKey records the profile generation that has been rolled out to the namespace.
+	// It is only used when the profile has a rollout strategy.
+	QuotaProfileGenerationLabelKey = "quota.dev.operator/profile-generation"
+
+	// ObjectTemplateHashAnnotationKey records the hash of the template an object was last rendered from,
+	// so that fields removed from the template are also removed from the object.
+	ObjectTemplateHashAnnotationKey = "quota.dev.operator/template-hash"
+
+	// QuotaProfileDistributeLabelKey marks a QuotaProfile in the hub cluster that is applied to all member
+	// clusters. Its value must be "true".
+	QuotaProfileDistributeLabelKey = "quota.dev.operator/distribute"
+
+	// QuotaProfileDistributedLabelKey marks the copy of a distributed QuotaProfile in a member cluster. Only
+	// copies with this label are updated and deleted by the hub.
+	QuotaProfileDistributedLabelKey = "quota.dev.operator/distributed"
+
+	// QuotaProfileHubFinalizer keeps a distributed QuotaProfile until its copies are deleted from the member clusters.
+	QuotaProfileHubFinalizer = "quota.dev.operator/hub-finalizer"
+
+	// MemberClusterLabelKey marks a Secret in the hub cluster that holds the kubeconfig of a member cluster under
+	// MemberClusterKubeconfigKey. The name of the Secret is the name of the member cluster.
+	MemberClusterLabelKey = "quota.dev.operator/member-cluster"
+
+	// MemberClusterKubeconfigKey is the key of the kubeconfig in the Secret of a member cluster.
+	MemberClusterKubeconfigKey = "kubeconfig"
+
+	// NamespaceStatusConfigMapName is the ConfigMap in every bound namespace that describes the quota profile
+	// in effect for tenants that cannot read QuotaProfiles.
+	NamespaceStatusConfigMapName = "quota-profile-status"
+)
+
+// Condition types of a QuotaProfile, following the kstatus conventions so that GitOps tools like Argo CD and Flux
+// can tell whether the profile reached all namespaces it selects. Reconciling and Stalled are only present while
+// they are true.
+const (
+	// QuotaProfileReady is true once every selected namespace is bound and rolled out to the observed generation.
+	QuotaProfileReady = "Ready"
+
+	// QuotaProfileReconciling is true while namespaces are still being bound or rolled out.
+	QuotaProfileReconciling = "Reconciling"
+
+	// QuotaProfileStalled is true when the rollout cannot progress without a change to the profile.
+	QuotaProfileStalled = "Stalled"
+)
+
+// Reasons of the QuotaProfile conditions.
+const (
+	// QuotaProfileReconciledReason means that all selected namespaces are bound and rolled out.
+	QuotaProfileReconciledReason = "Reconciled"
+
+	// QuotaProfileBindFailedReason means that some of the selected namespaces could not be bound yet.
+	QuotaProfileBindFailedReason = "BindFailed"
+
+	// QuotaProfileReconcileFailedReason means that the namespaces could not be reconciled, e.g. because they could
+	// not be listed. The reconciliation is retried.
+	QuotaProfileReconcileFailedReason = "ReconcileFailed"
+
+	// QuotaProfileRollingOutReason means that a staged rollout has not reached all bound namespaces yet.
+	QuotaProfileRollingOutReason = "RollingOut"
+
+	// QuotaProfileRolloutPausedReason means that the staged rollout was paused after quota admission failures.
+	QuotaProfileRolloutPausedReason = "RolloutPaused"
+)
+
+// ObjectTemplateKinds are the kinds of objects that QuotaProfiles can template besides ResourceQuotas and LimitRanges.
+// The CRD schema, the RBAC of the operator and the managed object webhook must allow the same kinds.
+var ObjectTemplateKinds = []schema.GroupVersionKind{
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+}
+
+// DeletionPolicy describes what happens to the managed resources of bound namespaces when a QuotaProfile is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the managed ResourceQuotas and LimitRanges together with the profile.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan leaves the ResourceQuotas and LimitRanges in place as unmanaged objects.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+
+	// DeletionPolicyRetain keeps the ResourceQuotas and LimitRanges managed until a replacement profile binds.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// QuotaShrinkPolicy describes how quota changes are handled when a namespace already uses more than the new hard limit.
+// +kubebuilder:validation:Enum=Warn;Block;Max
+type QuotaShrinkPolicy string
+
+const (
+	// QuotaShrinkPolicyWarn applies the new hard limits and reports the exceeded namespaces as warnings.
+	QuotaShrinkPolicyWarn QuotaShrinkPolicy = "Warn"
+
+	// QuotaShrinkPolicyBlock rejects profile changes that set hard limits below the current usage.
+	QuotaShrinkPolicyBlock QuotaShrinkPolicy = "Block"
+
+	// QuotaShrinkPolicyMax applies the larger of the current usage and the new hard limit.
+	QuotaShrinkPolicyMax QuotaShrinkPolicy = "Max"
+)
+
+// ConflictPolicy describes how a QuotaProfile treats other profiles that may select the same namespaces with the
+// same precedence.
+// +kubebuilder:validation:Enum=Allow;Reject
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyAllow admits overlapping profiles with a warning, the namespaces selected by both are bound
+	// according to the specificity rules.
+	ConflictPolicyAllow ConflictPolicy = "Allow"
+
+	// ConflictPolicyReject refuses profiles that overlap with this profile at the same precedence.
+	ConflictPolicyReject ConflictPolicy = "Reject"
 )
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // QuotaProfileSpec defines the desired state of QuotaProfile.
+// +kubebuilder:validation:XValidation:rule="(has(self.resourceQuotaSpecs) && size(self.resourceQuotaSpecs) > 0) || (has(self.limitRangeSpecs) && size(self.limitRangeSpecs) > 0) || (has(self.objectTemplates) && size(self.objectTemplates) > 0) || has(self.budget) || (has(self.aggregateHard) && size(self.aggregateHard) > 0)",message="at least one of resourceQuotaSpecs, limitRangeSpecs, objectTemplates, budget or aggregateHard must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.resourceQuotaSpecs) || self.resourceQuotaSpecs.all(s, has(s.hard) && size(s.hard) > 0)",message="every resourceQuotaSpecs entry must set hard limits"
+// +kubebuilder:validation:XValidation:rule="!has(self.limitRangeSpecs) || self.limitRangeSpecs.all(s, size(s.limits) > 0)",message="every limitRangeSpecs entry must set limits"
 type QuotaProfileSpec struct {
-	NamespaceSelector  NamespaceSelector      `json:"namespaceSelector"`
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector"`
+
+	// Precedence decides between equally specific profiles selecting the same namespace, the highest one wins.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
 	Precedence         uint16                 `json:"precedence,omitempty"`
 	ResourceQuotaSpecs []v1.ResourceQuotaSpec `json:"resourceQuotaSpecs,omitempty"`
 	LimitRangeSpecs    []v1.LimitRangeSpec    `json:"limitRangeSpecs,omitempty"`
+
+	// ObjectTemplates are rendered as one object each in every bound namespace, e.g. a default NetworkPolicy.
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	ObjectTemplates []ObjectTemplate `json:"objectTemplates,omitempty"`
+
+	// Budget splits a total of hard limits across the bound namespaces, in addition to the resourceQuotaSpecs
+	// that every bound namespace gets in full.
+	// +optional
+	Budget *QuotaBudget `json:"budget,omitempty"`
+
+	// AggregateHard caps the total usage of Pods and PersistentVolumeClaims across all bound namespaces.
+	// Creations that would exceed it are denied by the operator's admission webhook.
+	// +optional
+	AggregateHard v1.ResourceList `json:"aggregateHard,omitempty"`
+
+	// Recommendation samples the usage of the ResourceQuotas of the resourceQuotaSpecs and writes recommended hard
+	// limits into a QuotaRecommendation in every bound namespace.
+	// +optional
+	Recommendation *RecommendationPolicy `json:"recommendation,omitempty"`
+
+	// QuotaRequests lets tenants raise the hard limits of the ResourceQuotas of the resourceQuotaSpecs in their
+	// namespace with QuotaRequests, up to the ceiling of the policy.
+	// +optional
+	QuotaRequests *QuotaRequestPolicy `json:"quotaRequests,omitempty"`
+
+	// DeletionPolicy controls what happens to the managed resources when this profile is deleted.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ShrinkPolicy controls what happens when a namespace already uses more than a new hard limit.
+	// +kubebuilder:default=Warn
+	// +optional
+	ShrinkPolicy QuotaShrinkPolicy `json:"shrinkPolicy,omitempty"`
+
+	// ConflictPolicy controls whether other profiles may select the same namespaces with the same precedence.
+	// With Reject, such profiles are refused by the validating webhook, whether they are created before or
+	// after this one.
+	// +kubebuilder:default=Allow
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Rollout stages changes of this profile across the bound namespaces in batches.
+	// When not set, every bound namespace is updated at once.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
 }
 
-type NamespaceSelector struct {
+// RecommendationPolicy configures the usage-driven recommendations for the hard limits of the ResourceQuotas of
+// a QuotaProfile.
+type RecommendationPolicy struct {
+	// HeadroomPercent is added to the peak usage in the window to get the recommended hard limit.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	HeadroomPercent int32 `json:"headroomPercent,omitempty"`
+
+	// Min are the lowest hard limits that are recommended.
+	// +optional
+	Min v1.ResourceList `json:"min,omitempty"`
+
+	// Max are the highest hard limits that are recommended. Resources without a max are never recommended
+	// above the hard limit of the profile.
+	// +optional
+	Max v1.ResourceList `json:"max,omitempty"`
+
+	// AutoApply replaces the hard limits of the profile with the recommended ones in the managed ResourceQuotas,
+	// once the samples cover the whole window.
+	// +optional
+	AutoApply bool `json:"autoApply,omitempty"`
+}
+
+// QuotaRequestPolicy configures which QuotaRequests of the bound namespaces are approved.
+// +kubebuilder:validation:XValidation:rule="size(self.ceiling) > 0",message="ceiling must not be empty"
+type QuotaRequestPolicy struct {
+	// Ceiling is the most that the approved QuotaRequests of a namespace may add to the hard limits together.
+	// Resources without a ceiling cannot be requested.
+	Ceiling v1.ResourceList `json:"ceiling"`
 
-	// NOTE: only one the these selectors can be used
-	// All of the labels mentioned in this field will be required to select the namespace
+	// AutoApprove are the amounts up to which QuotaRequests are approved without an approver. Requests for more
+	// of any resource need the quota.dev.operator/approved-by annotation.
+	// +optional
+	AutoApprove v1.ResourceList `json:"autoApprove,omitempty"`
+}
+
+// BudgetStrategy describes how the budget of a QuotaProfile is split across the bound namespaces.
+// +kubebuilder:validation:Enum=Equal;Weighted;Fixed
+type BudgetStrategy string
+
+const (
+	// BudgetStrategyEqual gives every bound namespace the same share of the budget.
+	BudgetStrategyEqual BudgetStrategy = "Equal"
+
+	// BudgetStrategyWeighted splits the budget in proportion to the weights in the namespace label weightLabel.
+	BudgetStrategyWeighted BudgetStrategy = "Weighted"
+
+	// BudgetStrategyFixed gives the namespaces in allocations their fixed amounts and splits the rest equally.
+	BudgetStrategyFixed BudgetStrategy = "Fixed"
+)
+
+// QuotaBudget is a total of hard limits that is split across the bound namespaces, which get one
+// ResourceQuota each with their share. The shares are rebalanced when namespaces bind or unbind.
+// +kubebuilder:validation:XValidation:rule="size(self.hard) > 0",message="hard must not be empty"
+// +kubebuilder:validation:XValidation:rule="!has(self.strategy) || self.strategy != 'Weighted' || has(self.weightLabel)",message="weightLabel must be set for the Weighted strategy"
+// +kubebuilder:validation:XValidation:rule="(has(self.strategy) && self.strategy == 'Fixed') || !has(self.allocations)",message="allocations can only be set for the Fixed strategy"
+type QuotaBudget struct {
+	// Hard is the total of the hard limits of all bound namespaces.
+	Hard v1.ResourceList `json:"hard"`
+
+	// Strategy decides how the budget is split.
+	// +kubebuilder:default=Equal
+	// +optional
+	Strategy BudgetStrategy `json:"strategy,omitempty"`
+
+	// WeightLabel is the namespace label that holds the weight of a namespace for the Weighted strategy.
+	// Namespaces without a non-negative integer weight have a weight of 1.
+	// +optional
+	WeightLabel string `json:"weightLabel,omitempty"`
+
+	// Allocations are the fixed hard limits of namespaces by name for the Fixed strategy. The bound
+	// namespaces without an allocation for a resource split the rest of its budget equally.
+	// +kubebuilder:validation:MaxProperties=256
+	// +optional
+	Allocations map[string]v1.ResourceList `json:"allocations,omitempty"`
+}
+
+// ObjectTemplate is a namespaced object rendered into every bound namespace besides the ResourceQuotas and LimitRanges.
+// The supported kinds are listed in ObjectTemplateKinds.
+// +kubebuilder:validation:XValidation:rule="(self.template.apiVersion == 'networking.k8s.io/v1' && self.template.kind == 'NetworkPolicy') || (self.template.apiVersion == 'rbac.authorization.k8s.io/v1' && self.template.kind == 'RoleBinding') || (self.template.apiVersion == 'policy/v1' && self.template.kind == 'PodDisruptionBudget')",message="only NetworkPolicy, RoleBinding and PodDisruptionBudget objects are supported"
+// +kubebuilder:validation:XValidation:rule="!has(self.template.metadata) || !has(self.template.metadata.name)",message="the name of the object is set by the operator"
+type ObjectTemplate struct {
+	// Template is the object, apiVersion and kind are required. Its labels and annotations are copied to the object.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:EmbeddedResource
+	Template runtime.RawExtension `json:"template"`
+}
+
+// RolloutStrategy controls how changes to a QuotaProfile are propagated to the bound namespaces.
+type RolloutStrategy struct {
+	// MaxNamespacesPerInterval is the maximum number of namespaces updated in one batch.
+	// +kubebuilder:validation:Minimum=1
+	MaxNamespacesPerInterval int32 `json:"maxNamespacesPerInterval"`
+
+	// Interval is the time to wait between two batches.
+	// +kubebuilder:default="5m"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// CanarySelector selects the namespaces that are updated before all others.
+	// +optional
+	CanarySelector *metav1.LabelSelector `json:"canarySelector,omitempty"`
+
+	// MaxFailedAdmissions pauses the rollout when more pod creations than this are rejected by quota
+	// admission in the namespaces that were already updated. Zero disables the check.
+	// Rejections are counted from the FailedCreate events whose message contains "exceeded quota",
+	// which only workload controllers such as ReplicaSets, Jobs and StatefulSets emit. Pods created
+	// directly by users are not counted, and neither are events that expired before they were seen.
+	// Only event series that started after the rollout are counted, so a controller that was already
+	// failing before does not pause it.
+	// +optional
+	MaxFailedAdmissions int32 `json:"maxFailedAdmissions,omitempty"`
+}
+
+// NamespaceSelector selects namespaces either by name, or by labels, annotations, name prefix and age, which
+// must all match.
+// +kubebuilder:validation:XValidation:rule="has(self.matchLabels) || has(self.matchExpressions) || has(self.matchName) || has(self.matchAnnotations) || has(self.matchNamePrefix)",message="one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions, namespaceSelector.matchName, namespaceSelector.matchAnnotations or namespaceSelector.matchNamePrefix must be set"
+// +kubebuilder:validation:XValidation:rule="!((has(self.matchLabels) || has(self.matchExpressions)) && has(self.matchName))",message="only one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions or namespaceSelector.matchName can be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.matchName) || !(has(self.matchAnnotations) || has(self.matchNamePrefix) || has(self.minAge))",message="namespaceSelector.matchName cannot be combined with matchAnnotations, matchNamePrefix or minAge"
+type NamespaceSelector struct {
+	// MatchLabels selects the namespaces that have all of these labels.
+	// +kubebuilder:validation:MinProperties=1
+	// +optional
 	MatchLabels map[string]string `json:"matchLabels,omitempty"`
 
+	// MatchExpressions selects the namespaces whose labels meet all of these requirements, with the operators
+	// In, NotIn, Exists and DoesNotExist of a Kubernetes label selector.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+
 	// ResourceQuota will be applied to the namespace with the specified name
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
 	MatchName *string `json:"matchName,omitempty"`
+
+	// MatchAnnotations selects the namespaces that have all of these annotations, e.g. owner or cost-center.
+	// +kubebuilder:validation:MinProperties=1
+	// +optional
+	MatchAnnotations map[string]string `json:"matchAnnotations,omitempty"`
+
+	// MatchNamePrefix selects the namespaces whose kubernetes.io/metadata.name label, i.e. their name, starts
+	// with this prefix.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	MatchNamePrefix *string `json:"matchNamePrefix,omitempty"`
+
+	// MinAge only selects namespaces that were created at least this long ago. Namespaces are bound once they
+	// reach the age.
+	// +optional
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
 }
 
 // QuotaProfileStatus defines the observed state of QuotaProfile.
 type QuotaProfileStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the profile generation the namespaces were last reconciled for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BoundNamespaces is the number of namespaces bound to the profile.
+	// +optional
+	BoundNamespaces int32 `json:"boundNamespaces,omitempty"`
+
+	// Rollout tracks the progress of the current staged rollout.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// Drift reports the managed objects that the last periodic resync found to differ from the profile.
+	// +optional
+	Drift *DriftStatus `json:"drift,omitempty"`
+
+	// Conflicts are the namespaces the profile selects that other profiles select as well, with the decision
+	// trace of each. At most 20 are listed.
+	// +optional
+	Conflicts []NamespaceConflict `json:"conflicts,omitempty"`
+
+	// MemberClusters reports the member clusters a profile labelled for distribution is applied to, only set in
+	// the hub cluster.
+	// +optional
+	MemberClusters []MemberClusterStatus `json:"memberClusters,omitempty"`
+
+	// Conditions are the Ready, Reconciling and Stalled conditions of the profile for the observed generation.
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NamespaceConflict describes how a namespace selected by several profiles was bound.
+type NamespaceConflict struct {
+	// Namespace is the name of the namespace.
+	Namespace string `json:"namespace"`
+
+	// BoundTo is the profile the namespace is bound to, as <namespace>/<name>.
+	BoundTo string `json:"boundTo"`
+
+	// Trace explains for every other profile selecting the namespace which rule decided for the winner.
+	// +optional
+	Trace []string `json:"trace,omitempty"`
+}
+
+// RolloutStatus describes the progress of a staged rollout.
+type RolloutStatus struct {
+	// ObservedGeneration is the profile generation being rolled out.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartTime is when the rollout of the observed generation started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// LastBatchTime is when the last batch of namespaces was updated.
+	// +optional
+	LastBatchTime *metav1.Time `json:"lastBatchTime,omitempty"`
+
+	// UpdatedNamespaces is the number of bound namespaces running the observed generation.
+	UpdatedNamespaces int32 `json:"updatedNamespaces"`
+
+	// TotalNamespaces is the number of namespaces bound to the profile.
+	TotalNamespaces int32 `json:"totalNamespaces"`
+
+	// FailedAdmissions is the number of pod creations rejected by quota admission in the
+	// updated namespaces since the rollout started.
+	FailedAdmissions int32 `json:"failedAdmissions,omitempty"`
+
+	// Paused is set when the rollout was stopped automatically. Changing the profile resumes it.
+	Paused bool `json:"paused,omitempty"`
+
+	// Message is a human readable description of the rollout state.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MemberClusterStatus reports a distributed profile in a member cluster.
+type MemberClusterStatus struct {
+	// Name is the name of the member cluster, i.e. of its kubeconfig Secret in the hub cluster.
+	Name string `json:"name"`
+
+	// ObservedGeneration is the generation of the profile that was last applied to the member cluster.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BoundNamespaces is the number of namespaces bound to the profile in the member cluster, as reported by the
+	// operator running there.
+	// +optional
+	BoundNamespaces int32 `json:"boundNamespaces,omitempty"`
+
+	// Error is why the profile could not be applied to the member cluster or its status not be read, empty when
+	// the last sync succeeded.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// LastSyncTime is when a sync last changed the status of the member cluster, a sync without changes does not
+	// update the status.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// DriftStatus describes the drift found in the bound namespaces by a periodic resync.
+type DriftStatus struct {
+	// LastCheckTime is when a resync last found a different drift, a resync that finds the same drift does not
+	// update the status.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// DriftedObjects is the number of managed objects that differed from the profile.
+	DriftedObjects int32 `json:"driftedObjects"`
+
+	// Corrected is set when the drifted objects were changed back to match the profile.
+	// It is not set when the operator runs in report only mode.
+	// +optional
+	Corrected bool `json:"corrected,omitempty"`
+
+	// Objects describes the drifted objects, at most 20 are listed.
+	// +optional
+	Objects []string `json:"objects,omitempty"`
 }
 
 // +kubebuilder:object:root=true
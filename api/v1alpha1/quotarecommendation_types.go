@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuotaRecommendationName is the name of the QuotaRecommendation the operator writes in every bound namespace
+// whose profile enables recommendations.
+const QuotaRecommendationName = "quota-profile-recommendation"
+
+// QuotaRecommendationSpec defines the namespace and profile a QuotaRecommendation is computed for.
+type QuotaRecommendationSpec struct {
+	// QuotaProfile is the profile the namespace is bound to, as <namespace>/<name>.
+	QuotaProfile string `json:"quotaProfile"`
+}
+
+// QuotaRecommendationStatus holds the sampled usage and the recommended hard limits.
+type QuotaRecommendationStatus struct {
+	// LastSampleTime is when the usage of the managed ResourceQuotas was last sampled.
+	// +optional
+	LastSampleTime *metav1.Time `json:"lastSampleTime,omitempty"`
+
+	// Ready is set when the samples cover the whole window, only then are recommendations applied automatically.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// ResourceQuotas are the recommendations for the managed ResourceQuotas rendered from the resourceQuotaSpecs.
+	// +optional
+	ResourceQuotas []ResourceQuotaRecommendation `json:"resourceQuotas,omitempty"`
+}
+
+// ResourceQuotaRecommendation is the recommendation for a single managed ResourceQuota.
+type ResourceQuotaRecommendation struct {
+	// Name is the name of the managed ResourceQuota.
+	Name string `json:"name"`
+
+	// Hard are the hard limits declared by the profile.
+	// +optional
+	Hard v1.ResourceList `json:"hard,omitempty"`
+
+	// Recommended are the suggested hard limits: the peak usage in the window plus the headroom of the
+	// profile, within its min and max bounds.
+	// +optional
+	Recommended v1.ResourceList `json:"recommended,omitempty"`
+
+	// Samples is the rolling window of the sampled usage, oldest first. Windows of more than 100 samples are stored
+	// as the peak usage of runs of consecutive samples, at the time of the first sample of each run.
+	// +optional
+	Samples []UsageSample `json:"samples,omitempty"`
+}
+
+// UsageSample is the usage of a ResourceQuota at a point in time.
+type UsageSample struct {
+	// Time is when the usage was sampled.
+	Time metav1.Time `json:"time"`
+
+	// Used is the status.used of the ResourceQuota.
+	Used v1.ResourceList `json:"used"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Profile",type=string,JSONPath=`.spec.quotaProfile`
+// +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`
+// +kubebuilder:printcolumn:name="Last Sample",type=date,JSONPath=`.status.lastSampleTime`
+
+// QuotaRecommendation is the Schema for the quotarecommendations API. The operator keeps one in every bound
+// namespace whose QuotaProfile enables recommendations.
+type QuotaRecommendation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuotaRecommendationSpec   `json:"spec,omitempty"`
+	Status QuotaRecommendationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuotaRecommendationList contains a list of QuotaRecommendation.
+type QuotaRecommendationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuotaRecommendation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuotaRecommendation{}, &QuotaRecommendationList{})
+}
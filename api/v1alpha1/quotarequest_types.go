@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuotaRequestApprovedByAnnotationKey is the annotation an approver sets on a QuotaRequest to approve it. Its
+// value must be the username of the approver, who needs the approve verb on quotarequests in the namespace.
+const QuotaRequestApprovedByAnnotationKey = "quota.dev.operator/approved-by"
+
+// QuotaRequestPhase is the outcome of a QuotaRequest.
+// +kubebuilder:validation:Enum=Pending;Approved;Denied
+type QuotaRequestPhase string
+
+const (
+	// QuotaRequestPending is waiting for an approver.
+	QuotaRequestPending QuotaRequestPhase = "Pending"
+
+	// QuotaRequestApproved has been approved, its resources are added to the managed ResourceQuotas.
+	QuotaRequestApproved QuotaRequestPhase = "Approved"
+
+	// QuotaRequestDenied has been denied, e.g. because it exceeds the ceiling of the profile.
+	QuotaRequestDenied QuotaRequestPhase = "Denied"
+)
+
+// QuotaRequestSpec defines the extra resources requested for the namespace.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new QuotaRequest instead"
+type QuotaRequestSpec struct {
+	// Resources are added to the hard limits of the managed ResourceQuotas that limit them once the request
+	// is approved.
+	// +kubebuilder:validation:XValidation:rule="size(self) > 0",message="resources must not be empty"
+	Resources v1.ResourceList `json:"resources"`
+
+	// Reason tells the approvers why the resources are needed.
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// QuotaRequestStatus shows the outcome of a QuotaRequest.
+type QuotaRequestStatus struct {
+	// Phase is Pending until the request is approved or denied, both are final.
+	// +optional
+	Phase QuotaRequestPhase `json:"phase,omitempty"`
+
+	// QuotaProfile is the profile the request was decided for, as <namespace>/<name>. Approved requests only
+	// apply while the namespace stays bound to it.
+	// +optional
+	QuotaProfile string `json:"quotaProfile,omitempty"`
+
+	// ApprovedBy is the approver of the request, empty for requests that were approved automatically.
+	// +optional
+	ApprovedBy string `json:"approvedBy,omitempty"`
+
+	// DecisionTime is when the request was approved or denied.
+	// +optional
+	DecisionTime *metav1.Time `json:"decisionTime,omitempty"`
+
+	// Message explains the phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Approved By",type=string,JSONPath=`.status.approvedBy`
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// QuotaRequest is the Schema for the quotarequests API. Tenants create them in their namespace to ask for more
+// resources than the QuotaProfile of the namespace grants.
+type QuotaRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuotaRequestSpec   `json:"spec,omitempty"`
+	Status QuotaRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuotaRequestList contains a list of QuotaRequest.
+type QuotaRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuotaRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuotaRequest{}, &QuotaRequestList{})
+}
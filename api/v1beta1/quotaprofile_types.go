@@ -0,0 +1,479 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeletionPolicy describes what happens to the managed resources of bound namespaces when a QuotaProfile is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the managed ResourceQuotas and LimitRanges together with the profile.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan leaves the ResourceQuotas and LimitRanges in place as unmanaged objects.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+
+	// DeletionPolicyRetain keeps the ResourceQuotas and LimitRanges managed until a replacement profile binds.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// QuotaShrinkPolicy describes how quota changes are handled when a namespace already uses more than the new hard limit.
+// +kubebuilder:validation:Enum=Warn;Block;Max
+type QuotaShrinkPolicy string
+
+const (
+	// QuotaShrinkPolicyWarn applies the new hard limits and reports the exceeded namespaces as warnings.
+	QuotaShrinkPolicyWarn QuotaShrinkPolicy = "Warn"
+
+	// QuotaShrinkPolicyBlock rejects profile changes that set hard limits below the current usage.
+	QuotaShrinkPolicyBlock QuotaShrinkPolicy = "Block"
+
+	// QuotaShrinkPolicyMax applies the larger of the current usage and the new hard limit.
+	QuotaShrinkPolicyMax QuotaShrinkPolicy = "Max"
+)
+
+// ConflictPolicy describes how a QuotaProfile treats other profiles that may select the same namespaces with the
+// same precedence.
+// +kubebuilder:validation:Enum=Allow;Reject
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyAllow admits overlapping profiles with a warning, the namespaces selected by both are bound
+	// according to the specificity rules.
+	ConflictPolicyAllow ConflictPolicy = "Allow"
+
+	// ConflictPolicyReject refuses profiles that overlap with this profile at the same precedence.
+	ConflictPolicyReject ConflictPolicy = "Reject"
+)
+
+// QuotaProfileSpec defines the desired state of QuotaProfile.
+// +kubebuilder:validation:XValidation:rule="(has(self.resourceQuotas) && size(self.resourceQuotas) > 0) || (has(self.limitRanges) && size(self.limitRanges) > 0) || (has(self.objects) && size(self.objects) > 0) || has(self.budget) || (has(self.aggregateHard) && size(self.aggregateHard) > 0)",message="at least one of resourceQuotas, limitRanges, objects, budget or aggregateHard must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.resourceQuotas) || self.resourceQuotas.all(q, has(q.hard) && size(q.hard) > 0)",message="every resourceQuotas entry must set hard limits"
+// +kubebuilder:validation:XValidation:rule="!has(self.limitRanges) || self.limitRanges.all(l, size(l.limits) > 0)",message="every limitRanges entry must set limits"
+type QuotaProfileSpec struct {
+	// NamespaceSelector selects the namespaces this profile applies to.
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector"`
+
+	// Precedence decides between equally specific profiles selecting the same namespace, the highest one wins.
+	// Profiles selecting a namespace by exact name rank first, then profiles selecting it by a name prefix, then
+	// profiles with more label and annotation terms. Equal precedences are decided by the lexical order of
+	// <namespace>/<name> of the profiles.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Precedence int32 `json:"precedence,omitempty"`
+
+	// ResourceQuotas are rendered as one ResourceQuota each in every bound namespace.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ResourceQuotas []ResourceQuotaTemplate `json:"resourceQuotas,omitempty"`
+
+	// LimitRanges are rendered as one LimitRange each in every bound namespace.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	LimitRanges []LimitRangeTemplate `json:"limitRanges,omitempty"`
+
+	// Objects are rendered as one object each in every bound namespace, e.g. a default NetworkPolicy.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	Objects []ObjectTemplate `json:"objects,omitempty"`
+
+	// Budget splits a total of hard limits across the bound namespaces, in addition to the resourceQuotas
+	// that every bound namespace gets in full.
+	// +optional
+	Budget *QuotaBudget `json:"budget,omitempty"`
+
+	// AggregateHard caps the total usage of Pods and PersistentVolumeClaims across all bound namespaces.
+	// Creations that would exceed it are denied by the operator's admission webhook.
+	// +optional
+	AggregateHard v1.ResourceList `json:"aggregateHard,omitempty"`
+
+	// Recommendation samples the usage of the ResourceQuotas of the resourceQuotas and writes recommended hard
+	// limits into a QuotaRecommendation in every bound namespace.
+	// +optional
+	Recommendation *RecommendationPolicy `json:"recommendation,omitempty"`
+
+	// QuotaRequests lets tenants raise the hard limits of the ResourceQuotas of the resourceQuotas in their
+	// namespace with QuotaRequests, up to the ceiling of the policy.
+	// +optional
+	QuotaRequests *QuotaRequestPolicy `json:"quotaRequests,omitempty"`
+
+	// DeletionPolicy controls what happens to the managed resources when this profile is deleted.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ShrinkPolicy controls what happens when a namespace already uses more than a new hard limit.
+	// +kubebuilder:default=Warn
+	// +optional
+	ShrinkPolicy QuotaShrinkPolicy `json:"shrinkPolicy,omitempty"`
+
+	// ConflictPolicy controls whether other profiles may select the same namespaces with the same precedence.
+	// With Reject, such profiles are refused by the validating webhook, whether they are created before or
+	// after this one.
+	// +kubebuilder:default=Allow
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Rollout stages changes of this profile across the bound namespaces in batches.
+	// When not set, every bound namespace is updated at once.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+}
+
+// NamespaceSelector selects namespaces either by name, or by labels, annotations, name prefix and age, which
+// must all match.
+// +kubebuilder:validation:XValidation:rule="has(self.name) || has(self.labelSelector) || has(self.annotations) || has(self.namePrefix)",message="one of namespaceSelector.name, namespaceSelector.labelSelector, namespaceSelector.annotations or namespaceSelector.namePrefix must be set"
+// +kubebuilder:validation:XValidation:rule="!(has(self.name) && has(self.labelSelector))",message="only one of namespaceSelector.name or namespaceSelector.labelSelector can be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.name) || !(has(self.annotations) || has(self.namePrefix) || has(self.minAge))",message="namespaceSelector.name cannot be combined with annotations, namePrefix or minAge"
+// +kubebuilder:validation:XValidation:rule="!has(self.labelSelector) || (has(self.labelSelector.matchLabels) && size(self.labelSelector.matchLabels) > 0) || (has(self.labelSelector.matchExpressions) && size(self.labelSelector.matchExpressions) > 0)",message="labelSelector must contain at least one of matchLabels or matchExpressions"
+type NamespaceSelector struct {
+	// Name selects the namespace with this name.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// LabelSelector selects the namespaces whose labels match all of its matchLabels and matchExpressions.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// Annotations selects the namespaces that have all of these annotations, e.g. owner or cost-center.
+	// +kubebuilder:validation:MinProperties=1
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// NamePrefix selects the namespaces whose kubernetes.io/metadata.name label, i.e. their name, starts with
+	// this prefix.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	NamePrefix *string `json:"namePrefix,omitempty"`
+
+	// MinAge only selects namespaces that were created at least this long ago. Namespaces are bound once they
+	// reach the age.
+	// +optional
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
+}
+
+// ResourceQuotaTemplate is a named ResourceQuota spec.
+type ResourceQuotaTemplate struct {
+	// Name identifies the entry within the profile. It is not part of the name of the ResourceQuota, which is
+	// derived from the position of the entry, e.g. <profile-namespace>-<profile-name>-0-rq.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	v1.ResourceQuotaSpec `json:",inline"`
+}
+
+// LimitRangeTemplate is a named LimitRange spec.
+type LimitRangeTemplate struct {
+	// Name identifies the entry within the profile. It is not part of the name of the LimitRange, which is
+	// derived from the position of the entry, e.g. <profile-namespace>-<profile-name>-0-lr.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	v1.LimitRangeSpec `json:",inline"`
+}
+
+// RecommendationPolicy configures the usage-driven recommendations for the hard limits of the ResourceQuotas of
+// a QuotaProfile.
+type RecommendationPolicy struct {
+	// HeadroomPercent is added to the peak usage in the window to get the recommended hard limit.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	HeadroomPercent int32 `json:"headroomPercent,omitempty"`
+
+	// Min are the lowest hard limits that are recommended.
+	// +optional
+	Min v1.ResourceList `json:"min,omitempty"`
+
+	// Max are the highest hard limits that are recommended. Resources without a max are never recommended
+	// above the hard limit of the profile.
+	// +optional
+	Max v1.ResourceList `json:"max,omitempty"`
+
+	// AutoApply replaces the hard limits of the profile with the recommended ones in the managed ResourceQuotas,
+	// once the samples cover the whole window.
+	// +optional
+	AutoApply bool `json:"autoApply,omitempty"`
+}
+
+// QuotaRequestPolicy configures which QuotaRequests of the bound namespaces are approved.
+// +kubebuilder:validation:XValidation:rule="size(self.ceiling) > 0",message="ceiling must not be empty"
+type QuotaRequestPolicy struct {
+	// Ceiling is the most that the approved QuotaRequests of a namespace may add to the hard limits together.
+	// Resources without a ceiling cannot be requested.
+	Ceiling v1.ResourceList `json:"ceiling"`
+
+	// AutoApprove are the amounts up to which QuotaRequests are approved without an approver. Requests for more
+	// of any resource need the quota.dev.operator/approved-by annotation.
+	// +optional
+	AutoApprove v1.ResourceList `json:"autoApprove,omitempty"`
+}
+
+// BudgetStrategy describes how the budget of a QuotaProfile is split across the bound namespaces.
+// +kubebuilder:validation:Enum=Equal;Weighted;Fixed
+type BudgetStrategy string
+
+const (
+	// BudgetStrategyEqual gives every bound namespace the same share of the budget.
+	BudgetStrategyEqual BudgetStrategy = "Equal"
+
+	// BudgetStrategyWeighted splits the budget in proportion to the weights in the namespace label weightLabel.
+	BudgetStrategyWeighted BudgetStrategy = "Weighted"
+
+	// BudgetStrategyFixed gives the namespaces in allocations their fixed amounts and splits the rest equally.
+	BudgetStrategyFixed BudgetStrategy = "Fixed"
+)
+
+// QuotaBudget is a total of hard limits that is split across the bound namespaces, which get one
+// ResourceQuota each with their share. The shares are rebalanced when namespaces bind or unbind.
+// +kubebuilder:validation:XValidation:rule="size(self.hard) > 0",message="hard must not be empty"
+// +kubebuilder:validation:XValidation:rule="!has(self.strategy) || self.strategy != 'Weighted' || has(self.weightLabel)",message="weightLabel must be set for the Weighted strategy"
+// +kubebuilder:validation:XValidation:rule="(has(self.strategy) && self.strategy == 'Fixed') || !has(self.allocations)",message="allocations can only be set for the Fixed strategy"
+type QuotaBudget struct {
+	// Hard is the total of the hard limits of all bound namespaces.
+	Hard v1.ResourceList `json:"hard"`
+
+	// Strategy decides how the budget is split.
+	// +kubebuilder:default=Equal
+	// +optional
+	Strategy BudgetStrategy `json:"strategy,omitempty"`
+
+	// WeightLabel is the namespace label that holds the weight of a namespace for the Weighted strategy.
+	// Namespaces without a non-negative integer weight have a weight of 1.
+	// +optional
+	WeightLabel string `json:"weightLabel,omitempty"`
+
+	// Allocations are the fixed hard limits of namespaces by name for the Fixed strategy. The bound
+	// namespaces without an allocation for a resource split the rest of its budget equally.
+	// +kubebuilder:validation:MaxProperties=256
+	// +optional
+	Allocations map[string]v1.ResourceList `json:"allocations,omitempty"`
+}
+
+// ObjectTemplate is a named namespaced object, the supported kinds are NetworkPolicy, RoleBinding and PodDisruptionBudget.
+// +kubebuilder:validation:XValidation:rule="(self.template.apiVersion == 'networking.k8s.io/v1' && self.template.kind == 'NetworkPolicy') || (self.template.apiVersion == 'rbac.authorization.k8s.io/v1' && self.template.kind == 'RoleBinding') || (self.template.apiVersion == 'policy/v1' && self.template.kind == 'PodDisruptionBudget')",message="only NetworkPolicy, RoleBinding and PodDisruptionBudget objects are supported"
+// +kubebuilder:validation:XValidation:rule="!has(self.template.metadata) || !has(self.template.metadata.name)",message="the name of the object is set by the operator"
+type ObjectTemplate struct {
+	// Name identifies the entry within the profile. It is not part of the name of the object, which is derived
+	// from the position of the entry among the objects of the same kind, e.g. <profile-namespace>-<profile-name>-0-networkpolicy.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Template is the object, apiVersion and kind are required. Its labels and annotations are copied to the object.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:EmbeddedResource
+	Template runtime.RawExtension `json:"template"`
+}
+
+// RolloutStrategy controls how changes to a QuotaProfile are propagated to the bound namespaces.
+type RolloutStrategy struct {
+	// MaxNamespacesPerInterval is the maximum number of namespaces updated in one batch.
+	// +kubebuilder:validation:Minimum=1
+	MaxNamespacesPerInterval int32 `json:"maxNamespacesPerInterval"`
+
+	// Interval is the time to wait between two batches.
+	// +kubebuilder:default="5m"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// CanarySelector selects the namespaces that are updated before all others.
+	// +optional
+	CanarySelector *metav1.LabelSelector `json:"canarySelector,omitempty"`
+
+	// MaxFailedAdmissions pauses the rollout when more pod creations than this are rejected by quota
+	// admission in the namespaces that were already updated. Zero disables the check.
+	// Rejections are counted from the FailedCreate events whose message contains "exceeded quota",
+	// which only workload controllers such as ReplicaSets, Jobs and StatefulSets emit. Pods created
+	// directly by users are not counted, and neither are events that expired before they were seen.
+	// Only event series that started after the rollout are counted, so a controller that was already
+	// failing before does not pause it.
+	// +optional
+	MaxFailedAdmissions int32 `json:"maxFailedAdmissions,omitempty"`
+}
+
+// QuotaProfileStatus defines the observed state of QuotaProfile.
+type QuotaProfileStatus struct {
+	// ObservedGeneration is the profile generation the namespaces were last reconciled for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BoundNamespaces is the number of namespaces bound to the profile.
+	// +optional
+	BoundNamespaces int32 `json:"boundNamespaces,omitempty"`
+
+	// Rollout tracks the progress of the current staged rollout.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// Drift reports the managed objects that the last periodic resync found to differ from the profile.
+	// +optional
+	Drift *DriftStatus `json:"drift,omitempty"`
+
+	// Conflicts are the namespaces the profile selects that other profiles select as well, with the decision
+	// trace of each. At most 20 are listed.
+	// +optional
+	Conflicts []NamespaceConflict `json:"conflicts,omitempty"`
+
+	// MemberClusters reports the member clusters a profile labelled for distribution is applied to, only set in
+	// the hub cluster.
+	// +optional
+	MemberClusters []MemberClusterStatus `json:"memberClusters,omitempty"`
+
+	// Conditions are the Ready, Reconciling and Stalled conditions of the profile for the observed generation.
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NamespaceConflict describes how a namespace selected by several profiles was bound.
+type NamespaceConflict struct {
+	// Namespace is the name of the namespace.
+	Namespace string `json:"namespace"`
+
+	// BoundTo is the profile the namespace is bound to, as <namespace>/<name>.
+	BoundTo string `json:"boundTo"`
+
+	// Trace explains for every other profile selecting the namespace which rule decided for the winner.
+	// +optional
+	Trace []string `json:"trace,omitempty"`
+}
+
+// RolloutStatus describes the progress of a staged rollout.
+type RolloutStatus struct {
+	// ObservedGeneration is the profile generation being rolled out.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartTime is when the rollout of the observed generation started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// LastBatchTime is when the last batch of namespaces was updated.
+	// +optional
+	LastBatchTime *metav1.Time `json:"lastBatchTime,omitempty"`
+
+	// UpdatedNamespaces is the number of bound namespaces running the observed generation.
+	UpdatedNamespaces int32 `json:"updatedNamespaces"`
+
+	// TotalNamespaces is the number of namespaces bound to the profile.
+	TotalNamespaces int32 `json:"totalNamespaces"`
+
+	// FailedAdmissions is the number of pod creations rejected by quota admission in the
+	// updated namespaces since the rollout started.
+	FailedAdmissions int32 `json:"failedAdmissions,omitempty"`
+
+	// Paused is set when the rollout was stopped automatically. Changing the profile resumes it.
+	Paused bool `json:"paused,omitempty"`
+
+	// Message is a human readable description of the rollout state.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MemberClusterStatus reports a distributed profile in a member cluster.
+type MemberClusterStatus struct {
+	// Name is the name of the member cluster, i.e. of its kubeconfig Secret in the hub cluster.
+	Name string `json:"name"`
+
+	// ObservedGeneration is the generation of the profile that was last applied to the member cluster.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BoundNamespaces is the number of namespaces bound to the profile in the member cluster, as reported by the
+	// operator running there.
+	// +optional
+	BoundNamespaces int32 `json:"boundNamespaces,omitempty"`
+
+	// Error is why the profile could not be applied to the member cluster or its status not be read, empty when
+	// the last sync succeeded.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// LastSyncTime is when a sync last changed the status of the member cluster, a sync without changes does not
+	// update the status.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// DriftStatus describes the drift found in the bound namespaces by a periodic resync.
+type DriftStatus struct {
+	// LastCheckTime is when a resync last found a different drift, a resync that finds the same drift does not
+	// update the status.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// DriftedObjects is the number of managed objects that differed from the profile.
+	DriftedObjects int32 `json:"driftedObjects"`
+
+	// Corrected is set when the drifted objects were changed back to match the profile.
+	// It is not set when the operator runs in report only mode.
+	// +optional
+	Corrected bool `json:"corrected,omitempty"`
+
+	// Objects describes the drifted objects, at most 20 are listed.
+	// +optional
+	Objects []string `json:"objects,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Precedence",type=integer,JSONPath=`.spec.precedence`
+// +kubebuilder:printcolumn:name="Bound",type=integer,JSONPath=`.status.boundNamespaces`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// QuotaProfile is the Schema for the quotaprofiles API.
+type QuotaProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuotaProfileSpec   `json:"spec,omitempty"`
+	Status QuotaProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuotaProfileList contains a list of QuotaProfile.
+type QuotaProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuotaProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuotaProfile{}, &QuotaProfileList{})
+}
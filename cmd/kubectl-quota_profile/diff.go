@@ -0,0 +1,348 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	quotav1beta1 "github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+	"github.com/abdullah599/namespace-quota-operator/internal/controller"
+	"github.com/abdullah599/namespace-quota-operator/internal/resolver"
+)
+
+// diff prints the namespaces whose binding changes and the managed objects that are created, updated or deleted
+// when the QuotaProfile in file is applied, without changing the cluster. The objects are rendered by the
+// namespace controller against an in-memory copy of the cluster, like the quota simulator does.
+func (p *plugin) diff(ctx context.Context, file, namespace string) error {
+	profile, err := readQuotaProfile(file)
+	if err != nil {
+		return err
+	}
+	if profile.Namespace == "" {
+		profile.Namespace = namespace
+	}
+	id := resolver.ProfileID(profile)
+
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := p.c.List(ctx, quotaProfiles); err != nil {
+		return err
+	}
+
+	// the applied profile replaces the one in the cluster and keeps its creation time, a new profile is the newest one
+	profile.CreationTimestamp = metav1.Now()
+	profile.ResourceVersion = ""
+	quotaProfiles.Items = lo.Reject(quotaProfiles.Items, func(q quotav1alpha1.QuotaProfile, _ int) bool {
+		if resolver.ProfileID(&q) == id {
+			profile.CreationTimestamp = q.CreationTimestamp
+			return true
+		}
+		return false
+	})
+	r := resolver.New(scheme, append(quotaProfiles.Items, *profile))
+
+	namespaces := &v1.NamespaceList{}
+	if err := p.c.List(ctx, namespaces); err != nil {
+		return err
+	}
+	sort.Slice(namespaces.Items, func(i, j int) bool { return namespaces.Items[i].Name < namespaces.Items[j].Name })
+
+	// the namespaces are bound as the operator would bind them once the profile is applied, the namespace
+	// controller then renders the managed objects of the affected namespaces against the in-memory client
+	c := r.Client()
+	var bindings, quotas, affected []string
+	for _, ns := range namespaces.Items {
+		bound := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
+		resolved, err := r.Resolve(ctx, &ns)
+		if err != nil {
+			return err
+		}
+		if bound != resolved {
+			bindings = append(bindings, fmt.Sprintf("  %s: %s -> %s", ns.Name, orNone(bound), orNone(resolved)))
+		}
+		if bound == id || resolved == id {
+			affected = append(affected, ns.Name)
+		}
+
+		if err := bindNamespace(ctx, r, &ns, resolved); err != nil {
+			return err
+		}
+	}
+
+	reconciler := &controller.NamespaceReconciler{Client: c, Scheme: scheme}
+	for _, namespace := range affected {
+		current, err := p.seedNamespace(ctx, c, namespace)
+		if err != nil {
+			return err
+		}
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace}}); err != nil {
+			return fmt.Errorf("failed to render the objects of namespace %s: %w", namespace, err)
+		}
+		rendered, err := managedObjects(ctx, c, namespace)
+		if err != nil {
+			return err
+		}
+
+		if changes := managedObjectChanges(current, rendered); len(changes) > 0 {
+			quotas = append(quotas, fmt.Sprintf("  %s:", namespace))
+			quotas = append(quotas, changes...)
+		}
+	}
+
+	if len(bindings) == 0 && len(quotas) == 0 {
+		fmt.Fprintln(p.out, "No changes.")
+		return nil
+	}
+	if len(bindings) > 0 {
+		fmt.Fprintln(p.out, "Namespace bindings:")
+		printLines(p.out, bindings)
+	}
+	if len(quotas) > 0 {
+		if len(bindings) > 0 {
+			fmt.Fprintln(p.out)
+		}
+		fmt.Fprintln(p.out, "Quota changes:")
+		printLines(p.out, quotas)
+	}
+	return nil
+}
+
+// bindNamespace adds a copy of the namespace to the in-memory client of the resolver, labelled with the
+// resolved profile. Namespaces of profiles with a rollout are labelled with the generation of the profile,
+// so that the eventual state of the rollout is rendered.
+func bindNamespace(ctx context.Context, r *resolver.Resolver, namespace *v1.Namespace, profileID string) error {
+	ns := namespace.DeepCopy()
+	ns.ResourceVersion = ""
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	delete(ns.Labels, quotav1alpha1.QuotaProfileLabelKey)
+	delete(ns.Labels, quotav1alpha1.QuotaProfileGenerationLabelKey)
+
+	if profileID != "" {
+		ns.Labels[quotav1alpha1.QuotaProfileLabelKey] = profileID
+		profile, err := r.Get(ctx, profileID)
+		if err != nil {
+			return err
+		}
+		if profile.Spec.Rollout != nil {
+			ns.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] = strconv.FormatInt(profile.Generation, 10)
+		}
+	}
+
+	if err := r.Client().Create(ctx, ns); err != nil {
+		return fmt.Errorf("failed to add namespace %s: %w", ns.Name, err)
+	}
+	return nil
+}
+
+// seedNamespace copies the managed objects, the QuotaRecommendation and the QuotaRequests of the namespace
+// from the cluster to the in-memory client, the namespace controller reads them to render the managed objects.
+// It returns the managed objects in the cluster.
+func (p *plugin) seedNamespace(ctx context.Context, c client.Client, namespace string) (map[string]client.Object, error) {
+	current, err := managedObjects(ctx, p.c, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := lo.Values(current)
+	recommendations := &quotav1alpha1.QuotaRecommendationList{}
+	if err := p.c.List(ctx, recommendations, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range recommendations.Items {
+		objs = append(objs, &recommendations.Items[i])
+	}
+	quotaRequests := &quotav1alpha1.QuotaRequestList{}
+	if err := p.c.List(ctx, quotaRequests, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range quotaRequests.Items {
+		objs = append(objs, &quotaRequests.Items[i])
+	}
+
+	for _, obj := range objs {
+		obj = obj.DeepCopyObject().(client.Object)
+		obj.SetResourceVersion("")
+		if err := c.Create(ctx, obj); err != nil {
+			return nil, fmt.Errorf("failed to add %s/%s: %w", namespace, obj.GetName(), err)
+		}
+	}
+	return current, nil
+}
+
+// managedObjects returns the ResourceQuotas, LimitRanges and templated objects managed by a QuotaProfile
+// in the namespace, keyed by kind and name.
+func managedObjects(ctx context.Context, c client.Client, namespace string) (map[string]client.Object, error) {
+	opts := []client.ListOption{client.InNamespace(namespace), client.HasLabels{quotav1alpha1.QuotaProfileLabelKey}}
+	objs := map[string]client.Object{}
+
+	rqs := &v1.ResourceQuotaList{}
+	if err := c.List(ctx, rqs, opts...); err != nil {
+		return nil, err
+	}
+	for i := range rqs.Items {
+		objs["ResourceQuota "+rqs.Items[i].Name] = &rqs.Items[i]
+	}
+
+	lrs := &v1.LimitRangeList{}
+	if err := c.List(ctx, lrs, opts...); err != nil {
+		return nil, err
+	}
+	for i := range lrs.Items {
+		objs["LimitRange "+lrs.Items[i].Name] = &lrs.Items[i]
+	}
+
+	for _, gvk := range quotav1alpha1.ObjectTemplateKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			list.Items[i].SetGroupVersionKind(gvk)
+			objs[gvk.Kind+" "+list.Items[i].GetName()] = &list.Items[i]
+		}
+	}
+	return objs, nil
+}
+
+// managedObjectChanges compares the managed objects in the cluster with the ones rendered by the namespace
+// controller, both keyed by kind and name. ResourceQuotas list the changes of their hard limits.
+func managedObjectChanges(current, rendered map[string]client.Object) []string {
+	kinds := append([]string{"ResourceQuota", "LimitRange"}, lo.Map(quotav1alpha1.ObjectTemplateKinds, func(gvk schema.GroupVersionKind, _ int) string {
+		return gvk.Kind
+	})...)
+	keys := lo.Uniq(append(lo.Keys(current), lo.Keys(rendered)...))
+	sort.Slice(keys, func(i, j int) bool {
+		kindI, nameI, _ := strings.Cut(keys[i], " ")
+		kindJ, nameJ, _ := strings.Cut(keys[j], " ")
+		if kindI != kindJ {
+			return lo.IndexOf(kinds, kindI) < lo.IndexOf(kinds, kindJ)
+		}
+		return nameI < nameJ
+	})
+
+	var changes []string
+	for _, key := range keys {
+		before, after := current[key], rendered[key]
+		switch {
+		case after == nil:
+			changes = append(changes, "    - "+key)
+		case before == nil:
+			changes = append(changes, "    + "+key)
+			if rq, ok := after.(*v1.ResourceQuota); ok {
+				changes = append(changes, resourceListChanges(nil, rq.Spec.Hard)...)
+			}
+		default:
+			switch before := before.(type) {
+			case *v1.ResourceQuota:
+				rq := after.(*v1.ResourceQuota)
+				if !equality.Semantic.DeepEqual(before.Spec, rq.Spec) {
+					changes = append(changes, "    ~ "+key)
+					changes = append(changes, resourceListChanges(before.Spec.Hard, rq.Spec.Hard)...)
+				}
+			case *v1.LimitRange:
+				if !equality.Semantic.DeepEqual(before.Spec, after.(*v1.LimitRange).Spec) {
+					changes = append(changes, "    ~ "+key)
+				}
+			case *unstructured.Unstructured:
+				if !equality.Semantic.DeepEqual(templatedContent(before), templatedContent(after.(*unstructured.Unstructured))) {
+					changes = append(changes, "    ~ "+key)
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// templatedContent returns the parts of a templated object that are rendered from its template.
+func templatedContent(obj *unstructured.Unstructured) map[string]any {
+	content := lo.OmitByKeys(obj.Object, []string{"apiVersion", "kind", "metadata", "status"})
+	content["labels"] = obj.GetLabels()
+	content["annotations"] = obj.GetAnnotations()
+	return content
+}
+
+// resourceListChanges returns the resources whose amount differs between current and desired.
+func resourceListChanges(current, desired v1.ResourceList) []string {
+	resources := lo.Uniq(append(lo.Keys(current), lo.Keys(desired)...))
+	sort.Slice(resources, func(i, j int) bool { return resources[i] < resources[j] })
+
+	var changes []string
+	for _, resource := range resources {
+		before, hadBefore := current[resource]
+		after, hasAfter := desired[resource]
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("        %s: %s", resource, after.String()))
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf("        %s: %s -> <none>", resource, before.String()))
+		case before.Cmp(after) != 0:
+			changes = append(changes, fmt.Sprintf("        %s: %s -> %s", resource, before.String(), after.String()))
+		}
+	}
+	return changes
+}
+
+// readQuotaProfile decodes the QuotaProfile manifest in file, - reads it from stdin.
+func readQuotaProfile(file string) (*quotav1alpha1.QuotaProfile, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	obj, gvk, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", file, err)
+	}
+	switch profile := obj.(type) {
+	case *quotav1alpha1.QuotaProfile:
+		return profile, nil
+	case *quotav1beta1.QuotaProfile:
+		converted := &quotav1alpha1.QuotaProfile{}
+		if err := converted.ConvertFrom(profile); err != nil {
+			return nil, fmt.Errorf("unable to convert %s: %w", file, err)
+		}
+		return converted, nil
+	default:
+		return nil, fmt.Errorf("expected a QuotaProfile in %s but got %s", file, gvk.Kind)
+	}
+}
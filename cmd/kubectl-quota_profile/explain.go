@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/resolver"
+)
+
+// explain prints the profile the namespace is bound to, the profile it resolves to and
+// the decision the resolver took for every profile that selects the namespace.
+func (p *plugin) explain(ctx context.Context, name string) error {
+	ns := &v1.Namespace{}
+	if err := p.c.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+		return err
+	}
+
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := p.c.List(ctx, quotaProfiles); err != nil {
+		return err
+	}
+
+	r := resolver.New(scheme, quotaProfiles.Items)
+	resolved, err := r.Resolve(ctx, ns)
+	if err != nil {
+		return err
+	}
+	candidates, err := r.Candidates(ctx, ns)
+	if err != nil {
+		return err
+	}
+	winner, err := r.Get(ctx, resolved)
+	if err != nil {
+		return err
+	}
+
+	bound := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
+	fmt.Fprintf(p.out, "Namespace:  %s\n", ns.Name)
+	fmt.Fprintf(p.out, "Bound:      %s\n", orNone(bound))
+	fmt.Fprintf(p.out, "Resolved:   %s\n", orNone(resolved))
+	if ns.Labels[quotav1alpha1.QuotaProfileRetainedLabelKey] != "" {
+		fmt.Fprintf(p.out, "Retained:   the bound profile was deleted with the Retain policy\n")
+	}
+	if bound != resolved {
+		fmt.Fprintf(p.out, "\nThe namespace is bound to a different profile than it resolves to, it is relabelled on its next update.\n")
+	}
+
+	if len(candidates) == 0 {
+		fmt.Fprintf(p.out, "\nNo QuotaProfile selects this namespace.\n")
+		return nil
+	}
+
+	fmt.Fprintln(p.out)
+	w := tabwriter.NewWriter(p.out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tSELECTOR\tPRECEDENCE\tCREATED\tDECISION")
+	for _, q := range candidates {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", resolver.ProfileID(&q), selectorString(&q), q.Spec.Precedence,
+			q.CreationTimestamp.UTC().Format(time.RFC3339), decision(&q, ns, winner))
+	}
+	return w.Flush()
+}
+
+// decision describes why the resolver did or did not bind the namespace to the profile, with the rule of
+// index.Compare that decided between the profile and the winner.
+func decision(q *quotav1alpha1.QuotaProfile, ns *v1.Namespace, winner *quotav1alpha1.QuotaProfile) string {
+	if q.DeletionTimestamp != nil {
+		return "skipped: the profile is being deleted"
+	}
+
+	if reason := index.SelectorMismatch(q.Spec.NamespaceSelector, ns, time.Now()); reason != "" {
+		return "no match: " + reason
+	}
+
+	switch {
+	case winner == nil:
+		return "not selected"
+	case resolver.ProfileID(winner) == resolver.ProfileID(q):
+		return "selected: ranks first of the matching profiles"
+	default:
+		_, rule := index.Compare(winner, q)
+		return fmt.Sprintf("not selected: %s wins, %s", resolver.ProfileID(winner), rule)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+)
+
+// selectorString formats the namespace selector of a profile, e.g. "name=team-a", "env=dev,tier notin (sandbox)" or
+// "annotation:owner=alice,prefix=team-,minAge=24h0m0s".
+func selectorString(quotaProfile *quotav1alpha1.QuotaProfile) string {
+	selector := quotaProfile.Spec.NamespaceSelector
+	if selector.MatchName != nil {
+		return "name=" + *selector.MatchName
+	}
+	labels := make([]string, 0, len(selector.MatchLabels))
+	for key, value := range selector.MatchLabels {
+		labels = append(labels, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(labels)
+	for _, requirement := range selector.MatchExpressions {
+		labels = append(labels, index.RequirementString(requirement))
+	}
+	annotations := make([]string, 0, len(selector.MatchAnnotations))
+	for key, value := range selector.MatchAnnotations {
+		annotations = append(annotations, fmt.Sprintf("annotation:%s=%s", key, value))
+	}
+	sort.Strings(annotations)
+	parts := append(labels, annotations...)
+	if selector.MatchNamePrefix != nil {
+		parts = append(parts, "prefix="+*selector.MatchNamePrefix)
+	}
+	if selector.MinAge != nil {
+		parts = append(parts, "minAge="+selector.MinAge.Duration.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// orNone returns the profile ID or <none> when the namespace is not bound.
+func orNone(profileID string) string {
+	if profileID == "" {
+		return "<none>"
+	}
+	return profileID
+}
+
+func printLines(w io.Writer, lines []string) {
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
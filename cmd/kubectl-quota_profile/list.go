@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/resolver"
+)
+
+// list prints all QuotaProfiles with the number of namespaces bound to each of them.
+func (p *plugin) list(ctx context.Context) error {
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := p.c.List(ctx, quotaProfiles); err != nil {
+		return err
+	}
+
+	namespaces := &v1.NamespaceList{}
+	if err := p.c.List(ctx, namespaces, client.HasLabels{quotav1alpha1.QuotaProfileLabelKey}); err != nil {
+		return err
+	}
+	bound := lo.CountValuesBy(namespaces.Items, func(ns v1.Namespace) string {
+		return ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
+	})
+
+	if len(quotaProfiles.Items) == 0 {
+		fmt.Fprintln(p.out, "No QuotaProfiles found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tSELECTOR\tPRECEDENCE\tNAMESPACES")
+	for _, q := range quotaProfiles.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", q.Namespace, q.Name, selectorString(&q), q.Spec.Precedence, bound[resolver.ProfileID(&q)])
+	}
+	return w.Flush()
+}
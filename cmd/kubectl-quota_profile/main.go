@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command kubectl-quota_profile is a kubectl plugin, invoked as `kubectl quota-profile`, that shows how
+// QuotaProfiles are resolved for namespaces, previews profile changes and reports quota utilization.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	quotav1beta1 "github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	utilruntime.Must(quotav1alpha1.AddToScheme(scheme))
+	utilruntime.Must(quotav1beta1.AddToScheme(scheme))
+}
+
+const usageText = `Usage: kubectl quota-profile [--kubeconfig PATH] [-v] COMMAND [FLAGS]
+
+Commands:
+  explain NAMESPACE        Show which QuotaProfile the namespace resolves to and why
+  list                     List the QuotaProfiles with the number of bound namespaces
+  diff -f FILE [-n NS]     Show the namespace binding and quota changes of applying a QuotaProfile
+  usage [-n NS]            Show the utilization of the managed ResourceQuotas per namespace
+
+Flags:
+`
+
+// plugin implements the commands against a cluster.
+type plugin struct {
+	c   client.Client
+	out io.Writer
+}
+
+func main() {
+	var verbose bool
+	flag.BoolVar(&verbose, "v", false, "Log the decisions of the profile resolver to stderr.")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usageText)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if verbose {
+		logf.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stderr)))
+	} else {
+		logf.SetLogger(logr.Discard())
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: unable to load kubeconfig: %v\n", err)
+		os.Exit(1)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: unable to create client: %v\n", err)
+		os.Exit(1)
+	}
+
+	p := &plugin{c: c, out: os.Stdout}
+	if err := p.run(context.Background(), flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the flags of the command in args[0] and runs it.
+func (p *plugin) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	switch args[0] {
+	case "explain":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("explain takes exactly one namespace")
+		}
+		return p.explain(ctx, fs.Arg(0))
+	case "list":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return p.list(ctx)
+	case "diff":
+		file := fs.String("f", "", "The QuotaProfile manifest to compare with the cluster, - reads from stdin.")
+		namespace := fs.String("n", "default", "The namespace of the QuotaProfile when the manifest does not set one.")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *file == "" {
+			return fmt.Errorf("diff requires a QuotaProfile manifest, set it with -f")
+		}
+		return p.diff(ctx, *file, *namespace)
+	case "usage":
+		namespace := fs.String("n", "", "Only show the ResourceQuotas of this namespace.")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return p.usage(ctx, *namespace)
+	default:
+		return fmt.Errorf("unknown command %q, run with -h for the list of commands", args[0])
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// newTestPlugin returns a plugin against a cluster with two label selecting profiles, where the
+// team-a profile wins the team-a-dev namespace by precedence and the dev profile binds other-dev.
+func newTestPlugin() (*plugin, *bytes.Buffer) {
+	logf.SetLogger(logr.Discard())
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	profile := func(name string, labels map[string]string, precedence uint16, cpu string, age time.Duration) *quotav1alpha1.QuotaProfile {
+		return &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "quota-system", CreationTimestamp: metav1.NewTime(created.Add(age))},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector:  quotav1alpha1.NamespaceSelector{MatchLabels: labels},
+				Precedence:         precedence,
+				ResourceQuotaSpecs: []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(cpu)}}},
+			},
+		}
+	}
+	namespace := func(name, profileID string, labels map[string]string) *v1.Namespace {
+		labels[quotav1alpha1.QuotaProfileLabelKey] = profileID
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	resourceQuota := func(namespace, name, profileID, hard, used string) *v1.ResourceQuota {
+		return &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: profileID}},
+			Spec:       v1.ResourceQuotaSpec{Hard: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(hard)}},
+			Status: v1.ResourceQuotaStatus{
+				Hard: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(hard)},
+				Used: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(used)},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		profile("dev", map[string]string{"environment": "dev"}, 1, "1", 0),
+		profile("team-a", map[string]string{"team": "a"}, 5, "2", time.Hour),
+		namespace("team-a-dev", "quota-system.team-a", map[string]string{"environment": "dev", "team": "a"}),
+		namespace("other-dev", "quota-system.dev", map[string]string{"environment": "dev"}),
+		resourceQuota("team-a-dev", "quota-system-team-a-0-rq", "quota-system.team-a", "2", "1"),
+		resourceQuota("other-dev", "quota-system-dev-0-rq", "quota-system.dev", "1", "250m"),
+	).Build()
+
+	out := &bytes.Buffer{}
+	return &plugin{c: c, out: out}, out
+}
+
+func writeManifest(t *testing.T, manifest string) string {
+	file := filepath.Join(t.TempDir(), "profile.yaml")
+	if err := os.WriteFile(file, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func expectLines(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	p, out := newTestPlugin()
+	if err := p.run(context.Background(), []string{"explain", "team-a-dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectLines(t, out.String(),
+		"Bound:      quota-system.team-a",
+		"Resolved:   quota-system.team-a",
+		"not selected: quota-system.team-a wins, higher precedence (5 over 1)",
+		"selected: ranks first of the matching profiles",
+	)
+}
+
+func TestList(t *testing.T) {
+	p, out := newTestPlugin()
+	if err := p.run(context.Background(), []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and two profiles, got:\n%s", out.String())
+	}
+	for i, fields := range [][]string{{"quota-system", "dev", "environment=dev", "1", "1"}, {"quota-system", "team-a", "team=a", "5", "1"}} {
+		if got := strings.Fields(lines[i+1]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("expected row %v, got %v", fields, got)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("quota changes of bound namespaces", func(t *testing.T) {
+		p, out := newTestPlugin()
+		file := writeManifest(t, `apiVersion: quota.dev.operator/v1alpha1
+kind: QuotaProfile
+metadata:
+  name: dev
+  namespace: quota-system
+spec:
+  namespaceSelector:
+    matchLabels:
+      environment: dev
+  precedence: 1
+  resourceQuotaSpecs:
+  - hard:
+      requests.cpu: "3"
+      requests.memory: 1Gi
+`)
+		if err := p.run(context.Background(), []string{"diff", "-f", file}); err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(out.String(), "Namespace bindings:") {
+			t.Errorf("expected no binding changes, got:\n%s", out.String())
+		}
+		expectLines(t, out.String(),
+			"  other-dev:",
+			"    ~ ResourceQuota quota-system-dev-0-rq",
+			"        requests.cpu: 1 -> 3",
+			"        requests.memory: 1Gi",
+		)
+	})
+
+	t.Run("binding changes by precedence", func(t *testing.T) {
+		p, out := newTestPlugin()
+		file := writeManifest(t, `apiVersion: quota.dev.operator/v1alpha1
+kind: QuotaProfile
+metadata:
+  name: dev
+spec:
+  namespaceSelector:
+    matchLabels:
+      environment: dev
+  precedence: 10
+  resourceQuotaSpecs:
+  - hard:
+      requests.cpu: "1"
+`)
+		if err := p.run(context.Background(), []string{"diff", "-f", file, "-n", "quota-system"}); err != nil {
+			t.Fatal(err)
+		}
+
+		expectLines(t, out.String(),
+			"  team-a-dev: quota-system.team-a -> quota-system.dev",
+			"    + ResourceQuota quota-system-dev-0-rq",
+			"    - ResourceQuota quota-system-team-a-0-rq",
+		)
+		if strings.Contains(out.String(), "other-dev") {
+			t.Errorf("expected other-dev to be unchanged, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("objects rendered by the namespace controller", func(t *testing.T) {
+		manifest := `apiVersion: quota.dev.operator/v1alpha1
+kind: QuotaProfile
+metadata:
+  name: dev
+  namespace: quota-system
+spec:
+  namespaceSelector:
+    matchLabels:
+      environment: dev
+  precedence: 1
+  resourceQuotaSpecs:
+  - hard:
+      requests.cpu: "1"
+  budget:
+    hard:
+      requests.cpu: "4"
+  objectTemplates:
+  - template:
+      apiVersion: networking.k8s.io/v1
+      kind: NetworkPolicy
+      spec:
+        podSelector: {}
+`
+		p, out := newTestPlugin()
+		if err := p.run(context.Background(), []string{"diff", "-f", writeManifest(t, manifest)}); err != nil {
+			t.Fatal(err)
+		}
+		expectLines(t, out.String(),
+			"  other-dev:",
+			"    + ResourceQuota quota-system-dev-1-rq",
+			"        requests.cpu: 4",
+			"    + NetworkPolicy quota-system-dev-0-networkpolicy",
+		)
+
+		// once applied, the budget ResourceQuota and the NetworkPolicy are not reported as removed
+		budget := &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota-system-dev-1-rq", Namespace: "other-dev", Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "quota-system.dev"}},
+			Spec:       v1.ResourceQuotaSpec{Hard: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("4")}},
+		}
+		if err := p.c.Create(context.Background(), budget); err != nil {
+			t.Fatal(err)
+		}
+		out.Reset()
+		if err := p.run(context.Background(), []string{"diff", "-f", writeManifest(t, manifest)}); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(out.String(), "quota-system-dev-1-rq") {
+			t.Errorf("expected the budget ResourceQuota to be unchanged, got:\n%s", out.String())
+		}
+	})
+}
+
+func TestUsage(t *testing.T) {
+	p, out := newTestPlugin()
+	if err := p.run(context.Background(), []string{"usage", "-n", "other-dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and one resource, got:\n%s", out.String())
+	}
+	expected := []string{"other-dev", "quota-system.dev", "quota-system-dev-0-rq", "requests.cpu", "250m", "1", "25%"}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected row %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// usage prints the used and hard amount of every resource of the managed ResourceQuotas.
+func (p *plugin) usage(ctx context.Context, namespace string) error {
+	rqs := &v1.ResourceQuotaList{}
+	if err := p.c.List(ctx, rqs, client.InNamespace(namespace), client.HasLabels{quotav1alpha1.QuotaProfileLabelKey}); err != nil {
+		return err
+	}
+
+	if len(rqs.Items) == 0 {
+		fmt.Fprintln(p.out, "No managed ResourceQuotas found.")
+		return nil
+	}
+
+	sort.Slice(rqs.Items, func(i, j int) bool {
+		if rqs.Items[i].Namespace != rqs.Items[j].Namespace {
+			return rqs.Items[i].Namespace < rqs.Items[j].Namespace
+		}
+		return rqs.Items[i].Name < rqs.Items[j].Name
+	})
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tPROFILE\tRESOURCEQUOTA\tRESOURCE\tUSED\tHARD\tUTILIZATION")
+	for _, rq := range rqs.Items {
+		resources := lo.Keys(rq.Status.Hard)
+		sort.Slice(resources, func(i, j int) bool { return resources[i] < resources[j] })
+
+		for _, resource := range resources {
+			hard := rq.Status.Hard[resource]
+			used := rq.Status.Used[resource]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", rq.Namespace, rq.Labels[quotav1alpha1.QuotaProfileLabelKey], rq.Name,
+				resource, used.String(), hard.String(), utilization(used.AsApproximateFloat64(), hard.AsApproximateFloat64()))
+		}
+	}
+	return w.Flush()
+}
+
+// utilization formats used as a percentage of hard.
+func utilization(used, hard float64) string {
+	if hard == 0 {
+		if used == 0 {
+			return "0%"
+		}
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", used/hard*100)
+}
@@ -17,20 +17,28 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -38,9 +46,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	quotav1beta1 "github.com/abdullah599/namespace-quota-operator/api/v1beta1"
 	"github.com/abdullah599/namespace-quota-operator/internal/controller"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
 	webhookdevoperatorv1 "github.com/abdullah599/namespace-quota-operator/internal/webhook/v1"
 	webhookquotav1alpha1 "github.com/abdullah599/namespace-quota-operator/internal/webhook/v1alpha1"
+	webhookquotav1beta1 "github.com/abdullah599/namespace-quota-operator/internal/webhook/v1beta1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -53,6 +64,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(quotav1alpha1.AddToScheme(scheme))
+	utilruntime.Must(quotav1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -65,6 +77,13 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var operatorServiceAccount, breakGlassUsers, breakGlassGroups string
+	var lockSelectorLabels, namespaceWebhookFailOpen bool
+	var resyncPeriod time.Duration
+	var driftReportOnly bool
+	var recommendationInterval, recommendationWindow time.Duration
+	var memberClustersNamespace string
+	var memberSyncPeriod time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -83,6 +102,35 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&operatorServiceAccount, "operator-service-account", "",
+		"The username of the service account allowed to mutate managed ResourceQuotas, LimitRanges and templated objects, "+
+			"e.g. system:serviceaccount:<namespace>:<name>. Detected from the pod's service account token if not set.")
+	flag.StringVar(&breakGlassUsers, "break-glass-users", "",
+		"Comma separated list of users allowed to mutate managed ResourceQuotas, LimitRanges and templated objects in emergencies.")
+	flag.StringVar(&breakGlassGroups, "break-glass-groups", "",
+		"Comma separated list of groups allowed to mutate managed ResourceQuotas, LimitRanges and templated objects in emergencies.")
+	flag.BoolVar(&lockSelectorLabels, "lock-selector-labels", false,
+		"If set, only the operator and break-glass users can change the namespace labels and annotations the bound "+
+			"QuotaProfile selects by.")
+	flag.BoolVar(&namespaceWebhookFailOpen, "namespace-webhook-fail-open", false,
+		"If set, namespaces are admitted unchanged when QuotaProfiles cannot be looked up and labelled asynchronously "+
+			"by the QuotaProfile controller instead of rejecting the request. Pair it with failurePolicy: Ignore on the "+
+			"Namespace webhooks, see config/components/namespace-webhook-fail-open.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute,
+		"The interval of the full resync that compares the managed ResourceQuotas and LimitRanges of all bound "+
+			"namespaces with their QuotaProfiles and corrects drift. Use 0 to disable the resync.")
+	flag.BoolVar(&driftReportOnly, "drift-report-only", false,
+		"If set, the resync only records drift in the QuotaProfile status and metrics without correcting it.")
+	flag.DurationVar(&recommendationInterval, "recommendation-interval", 15*time.Minute,
+		"The interval at which the usage of the managed ResourceQuotas of QuotaProfiles with recommendations is sampled. "+
+			"Use 0 to disable recommendations.")
+	flag.DurationVar(&recommendationWindow, "recommendation-window", 24*time.Hour,
+		"The rolling window of usage samples the recommended hard limits are computed from.")
+	flag.StringVar(&memberClustersNamespace, "member-clusters-namespace", "",
+		"If set, the operator also runs as the hub of a fleet and applies the QuotaProfiles labelled "+
+			"quota.dev.operator/distribute=true to the member clusters whose kubeconfig Secrets are in this namespace.")
+	flag.DurationVar(&memberSyncPeriod, "member-sync-period", time.Minute,
+		"The interval at which the distributed QuotaProfiles are synced to the member clusters and their status is refreshed.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -187,6 +235,23 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "19935b34.dev.operator",
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				// only the namespace status ConfigMaps of the operator are cached, not every ConfigMap of the cluster
+				&corev1.ConfigMap{}: {Label: lo.Must(labels.Parse(quotav1alpha1.QuotaProfileLabelKey))},
+				// Pods and PersistentVolumeClaims are cached for the aggregate quota webhooks, which only need their specs
+				&corev1.Pod{}:                   {Transform: cache.TransformStripManagedFields()},
+				&corev1.PersistentVolumeClaim{}: {Transform: cache.TransformStripManagedFields()},
+				// only the kubeconfig Secrets of the member clusters are cached, Secrets are only read in hub mode
+				&corev1.Secret{}: {
+					Namespaces: map[string]cache.Config{memberClustersNamespace: {}},
+					Label:      lo.Must(labels.Parse(quotav1alpha1.MemberClusterLabelKey)),
+				},
+			},
+		},
+		// the objects rendered from object templates are read as unstructured objects, which are only
+		// served from the cache when enabled
+		Client: client.Options{Cache: &client.CacheOptions{Unstructured: true}},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -204,11 +269,40 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = index.SetupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
+
+	if operatorServiceAccount == "" {
+		operatorServiceAccount, err = webhookdevoperatorv1.DetectOperatorServiceAccount()
+		if err != nil {
+			setupLog.Error(err, "unable to detect the operator service account, only break-glass users can mutate managed objects")
+		}
+	}
+	authorizer := &webhookdevoperatorv1.ManagedObjectAuthorizer{
+		OperatorServiceAccount: operatorServiceAccount,
+		BreakGlassUsers:        splitList(breakGlassUsers),
+		BreakGlassGroups:       splitList(breakGlassGroups),
+		Recorder:               mgr.GetEventRecorderFor("namespace-quota-operator"),
+	}
+	setupLog.Info("managed object authorization", "operatorServiceAccount", operatorServiceAccount,
+		"breakGlassUsers", authorizer.BreakGlassUsers, "breakGlassGroups", authorizer.BreakGlassGroups)
+
 	if err = (&controller.QuotaProfileReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		HubEnabled: memberClustersNamespace != "",
+		APIReader:  mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QuotaProfile")
+		os.Exit(1)
+	}
+	if err = (&controller.QuotaRequestReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "QuotaProfile")
+		setupLog.Error(err, "unable to create controller", "controller", "QuotaRequest")
 		os.Exit(1)
 	}
 	// nolint:goconst
@@ -217,6 +311,16 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "QuotaProfile")
 			os.Exit(1)
 		}
+		if err = webhookquotav1alpha1.SetupQuotaRequestWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "QuotaRequest")
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookquotav1beta1.SetupQuotaProfileWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "QuotaProfile")
+			os.Exit(1)
+		}
 	}
 	if err = (&controller.NamespaceReconciler{
 		Client: mgr.GetClient(),
@@ -225,27 +329,79 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Namespace")
 		os.Exit(1)
 	}
+	if memberClustersNamespace != "" {
+		if err = (&controller.HubReconciler{
+			Client:     mgr.GetClient(),
+			Scheme:     mgr.GetScheme(),
+			APIReader:  mgr.GetAPIReader(),
+			Namespace:  memberClustersNamespace,
+			SyncPeriod: memberSyncPeriod,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Hub")
+			os.Exit(1)
+		}
+	}
+	if resyncPeriod > 0 {
+		if err = mgr.Add(&controller.NamespaceResyncer{
+			Client:     mgr.GetClient(),
+			Scheme:     mgr.GetScheme(),
+			Period:     resyncPeriod,
+			ReportOnly: driftReportOnly,
+		}); err != nil {
+			setupLog.Error(err, "unable to add namespace resync to manager")
+			os.Exit(1)
+		}
+	}
+	if recommendationInterval > 0 {
+		if err = mgr.Add(&controller.QuotaRecommender{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Interval: recommendationInterval,
+			Window:   recommendationWindow,
+		}); err != nil {
+			setupLog.Error(err, "unable to add quota recommender to manager")
+			os.Exit(1)
+		}
+	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
-		if err = webhookdevoperatorv1.SetupNamespaceWebhookWithManager(mgr); err != nil {
+		if err = webhookdevoperatorv1.SetupNamespaceWebhookWithManager(mgr, webhookdevoperatorv1.NamespaceWebhookOptions{
+			Authorizer:         authorizer,
+			LockSelectorLabels: lockSelectorLabels,
+			FailOpen:           namespaceWebhookFailOpen,
+		}); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Namespace")
 			os.Exit(1)
 		}
 	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
-		if err = webhookdevoperatorv1.SetupResourceQuotaWebhookWithManager(mgr); err != nil {
+		if err = webhookdevoperatorv1.SetupResourceQuotaWebhookWithManager(mgr, authorizer); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ResourceQuota")
 			os.Exit(1)
 		}
 	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
-		if err = webhookdevoperatorv1.SetupLimitRangeWebhookWithManager(mgr); err != nil {
+		if err = webhookdevoperatorv1.SetupLimitRangeWebhookWithManager(mgr, authorizer); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "LimitRange")
 			os.Exit(1)
 		}
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookdevoperatorv1.SetupObjectTemplateWebhooksWithManager(mgr, authorizer); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ObjectTemplate")
+			os.Exit(1)
+		}
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookdevoperatorv1.SetupAggregateQuotaWebhooksWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AggregateQuota")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -279,3 +435,10 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	return lo.Compact(lo.Map(strings.Split(value, ","), func(item string, _ int) string {
+		return strings.TrimSpace(item)
+	}))
+}
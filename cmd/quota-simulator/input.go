@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	quotav1beta1 "github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+	"github.com/abdullah599/namespace-quota-operator/internal/crdschema"
+)
+
+// readQuotaProfiles reads the QuotaProfiles from the files, directories are read in lexical order. The profiles
+// are validated against the schema of the QuotaProfile CRD, as the API server would do.
+func readQuotaProfiles(ctx context.Context, paths []string, defaultNamespace string) ([]quotav1alpha1.QuotaProfile, error) {
+	schemaValidator, err := crdschema.NewQuotaProfileValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	var quotaProfiles []quotav1alpha1.QuotaProfile
+	var errs []error
+	for _, path := range paths {
+		files, err := manifestFiles(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			objs, err := readObjects(file)
+			if err != nil {
+				return nil, err
+			}
+			for _, obj := range objs {
+				schemaErrs, err := schemaValidator.Validate(ctx, obj)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", file, err)
+				}
+
+				quotaProfile, err := toQuotaProfile(obj)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", file, err)
+				}
+				if quotaProfile.Namespace == "" {
+					quotaProfile.Namespace = defaultNamespace
+				}
+				if len(schemaErrs) > 0 {
+					errs = append(errs, fmt.Errorf("quota profile %s/%s is invalid: %w", quotaProfile.Namespace, quotaProfile.Name, schemaErrs.ToAggregate()))
+					continue
+				}
+				quotaProfiles = append(quotaProfiles, *quotaProfile)
+			}
+		}
+	}
+	return quotaProfiles, errors.Join(errs...)
+}
+
+// toQuotaProfile returns the object as a v1alpha1 QuotaProfile, which is the version the operator works with.
+func toQuotaProfile(obj runtime.Object) (*quotav1alpha1.QuotaProfile, error) {
+	switch quotaProfile := obj.(type) {
+	case *quotav1alpha1.QuotaProfile:
+		return quotaProfile, nil
+	case *quotav1beta1.QuotaProfile:
+		converted := &quotav1alpha1.QuotaProfile{}
+		if err := converted.ConvertFrom(quotaProfile); err != nil {
+			return nil, err
+		}
+		return converted, nil
+	default:
+		return nil, fmt.Errorf("expected QuotaProfiles but got %s", obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+}
+
+// readNamespaces reads the namespace inventory from the file.
+func readNamespaces(file string) ([]v1.Namespace, error) {
+	objs, err := readObjects(file)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]v1.Namespace, 0, len(objs))
+	for _, obj := range objs {
+		ns, ok := obj.(*v1.Namespace)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected Namespaces but got %s", file, obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		namespaces = append(namespaces, *ns)
+	}
+	return namespaces, nil
+}
+
+// manifestFiles returns the YAML and JSON files in the directory at path, or path itself when it is a file.
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			if !d.IsDir() {
+				files = append(files, file)
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// readObjects decodes all objects of a YAML or JSON file, the items of lists are returned as separate objects.
+func readObjects(file string) ([]runtime.Object, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var objs []runtime.Object
+	for {
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 {
+			continue
+		}
+
+		decoded, err := decodeObject(raw.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		objs = append(objs, decoded...)
+	}
+}
+
+func decodeObject(data []byte) ([]runtime.Object, error) {
+	obj, _, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsListType(obj) {
+		return []runtime.Object{obj}, nil
+	}
+
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return nil, err
+	}
+	var objs []runtime.Object
+	for _, item := range items {
+		// the items of a v1 List are not decoded
+		if unknown, ok := item.(*runtime.Unknown); ok {
+			decoded, err := decodeObject(unknown.Raw)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, decoded...)
+			continue
+		}
+		objs = append(objs, item)
+	}
+	return objs, nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command quota-simulator resolves a namespace inventory against QuotaProfile manifests without a cluster
+// and prints the resulting bindings and the ResourceQuotas, LimitRanges and templated objects the operator would render.
+// It exits with an error when a profile is invalid or overlaps with another one that rejects conflicts, so it can run as a CI check.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	quotav1beta1 "github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	utilruntime.Must(quotav1alpha1.AddToScheme(scheme))
+	utilruntime.Must(quotav1beta1.AddToScheme(scheme))
+}
+
+// fileList is a flag that can be given multiple times.
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	var profileFiles fileList
+	var namespacesFile, defaultNamespace, output string
+	var verbose bool
+
+	fs := flag.NewFlagSet("quota-simulator", flag.ContinueOnError)
+	fs.Var(&profileFiles, "f", "A QuotaProfile manifest or a directory of manifests, can be given multiple times.")
+	fs.StringVar(&namespacesFile, "namespaces", "", "The namespace inventory, a YAML or JSON dump such as the output of kubectl get namespaces -o yaml.")
+	fs.StringVar(&defaultNamespace, "n", "default", "The namespace of the QuotaProfiles whose manifest does not set one.")
+	fs.StringVar(&output, "o", "yaml", "The output format, yaml or json.")
+	fs.BoolVar(&verbose, "v", false, "Log the decisions of the operator code to stderr.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if verbose {
+		logf.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stderr)))
+	} else {
+		logf.SetLogger(logr.Discard())
+	}
+
+	if len(profileFiles) == 0 || namespacesFile == "" {
+		return fmt.Errorf("both -f and -namespaces are required")
+	}
+	if output != "yaml" && output != "json" {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+
+	quotaProfiles, err := readQuotaProfiles(ctx, profileFiles, defaultNamespace)
+	if err != nil {
+		return err
+	}
+	namespaces, err := readNamespaces(namespacesFile)
+	if err != nil {
+		return err
+	}
+
+	result, err := simulate(ctx, quotaProfiles, namespaces)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if output == "json" {
+		data, err = json.MarshalIndent(result, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(result)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
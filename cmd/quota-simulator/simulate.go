@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/controller"
+	"github.com/abdullah599/namespace-quota-operator/internal/resolver"
+	webhookquotav1alpha1 "github.com/abdullah599/namespace-quota-operator/internal/webhook/v1alpha1"
+)
+
+// operatorLabels are the namespace labels set by the operator, they are removed from the inventory
+// so that the bindings only depend on the profiles and the labels of the namespaces.
+var operatorLabels = []string{
+	quotav1alpha1.QuotaProfileLabelKey,
+	quotav1alpha1.QuotaProfileLastUpdateTimestamp,
+	quotav1alpha1.QuotaProfileRetainedLabelKey,
+	quotav1alpha1.QuotaProfileGenerationLabelKey,
+}
+
+// simulation is the result of resolving a namespace inventory against a set of QuotaProfiles.
+type simulation struct {
+	// Bindings maps every namespace of the inventory to the ID of its profile, unbound namespaces map to ""
+	Bindings map[string]string `json:"bindings"`
+
+	// Objects are the ResourceQuotas, LimitRanges and templated objects the operator renders in the bound namespaces
+	Objects []runtime.Object `json:"objects"`
+}
+
+// simulate validates the profiles with the QuotaProfile webhook, binds the namespaces with the Namespace
+// webhook and renders their managed objects with the Namespace controller, all against an
+// in-memory client.
+func simulate(ctx context.Context, quotaProfiles []quotav1alpha1.QuotaProfile, namespaces []v1.Namespace) (*simulation, error) {
+	for i := range quotaProfiles {
+		quotaProfiles[i].ResourceVersion = ""
+	}
+
+	if err := validateUnique(quotaProfiles); err != nil {
+		return nil, err
+	}
+
+	r := resolver.New(scheme, quotaProfiles)
+	c := r.Client()
+	if err := validateProfiles(ctx, r, quotaProfiles); err != nil {
+		return nil, err
+	}
+
+	result := &simulation{Bindings: map[string]string{}}
+	for _, ns := range namespaces {
+		ns := ns.DeepCopy()
+		ns.ResourceVersion = ""
+		for _, key := range operatorLabels {
+			delete(ns.Labels, key)
+		}
+
+		profileID, err := r.Resolve(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve namespace %s: %w", ns.Name, err)
+		}
+		result.Bindings[ns.Name] = profileID
+		if profileID != "" {
+			if ns.Labels == nil {
+				ns.Labels = map[string]string{}
+			}
+			ns.Labels[quotav1alpha1.QuotaProfileLabelKey] = profileID
+		}
+
+		if err := c.Create(ctx, ns); err != nil {
+			return nil, fmt.Errorf("failed to add namespace %s: %w", ns.Name, err)
+		}
+	}
+
+	reconciler := &controller.NamespaceReconciler{Client: c, Scheme: scheme}
+	for _, ns := range namespaces {
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: ns.Name}}); err != nil {
+			return nil, fmt.Errorf("failed to render the objects of namespace %s: %w", ns.Name, err)
+		}
+	}
+
+	rqs := &v1.ResourceQuotaList{}
+	if err := c.List(ctx, rqs); err != nil {
+		return nil, err
+	}
+	for _, rq := range rqs.Items {
+		result.Objects = append(result.Objects, &v1.ResourceQuota{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ResourceQuota"},
+			ObjectMeta: metav1.ObjectMeta{Name: rq.Name, Namespace: rq.Namespace, Labels: rq.Labels},
+			Spec:       rq.Spec,
+		})
+	}
+
+	lrs := &v1.LimitRangeList{}
+	if err := c.List(ctx, lrs); err != nil {
+		return nil, err
+	}
+	for _, lr := range lrs.Items {
+		result.Objects = append(result.Objects, &v1.LimitRange{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "LimitRange"},
+			ObjectMeta: metav1.ObjectMeta{Name: lr.Name, Namespace: lr.Namespace, Labels: lr.Labels},
+			Spec:       lr.Spec,
+		})
+	}
+
+	for _, gvk := range quotav1alpha1.ObjectTemplateKinds {
+		objs := &unstructured.UnstructuredList{}
+		objs.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, objs); err != nil {
+			return nil, err
+		}
+		for _, obj := range objs.Items {
+			rendered := &unstructured.Unstructured{Object: lo.OmitByKeys(obj.Object, []string{"metadata"})}
+			rendered.SetGroupVersionKind(gvk)
+			rendered.SetName(obj.GetName())
+			rendered.SetNamespace(obj.GetNamespace())
+			rendered.SetLabels(obj.GetLabels())
+			rendered.SetAnnotations(obj.GetAnnotations())
+			result.Objects = append(result.Objects, rendered)
+		}
+	}
+
+	sort.SliceStable(result.Objects, func(i, j int) bool {
+		a, b := result.Objects[i].(metav1.Object), result.Objects[j].(metav1.Object)
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+	return result, nil
+}
+
+// validateUnique rejects profiles that are given more than once.
+func validateUnique(quotaProfiles []quotav1alpha1.QuotaProfile) error {
+	seen := map[string]bool{}
+	var errs []error
+	for _, q := range quotaProfiles {
+		id := resolver.ProfileID(&q)
+		if seen[id] {
+			errs = append(errs, fmt.Errorf("quota profile %s/%s is defined more than once", q.Namespace, q.Name))
+		}
+		seen[id] = true
+	}
+	return errors.Join(errs...)
+}
+
+// validateProfiles runs the QuotaProfile validating webhook for every profile, so that invalid specs
+// and overlaps with another profile that the conflict policy rejects fail the simulation.
+func validateProfiles(ctx context.Context, r *resolver.Resolver, quotaProfiles []quotav1alpha1.QuotaProfile) error {
+	webhookquotav1alpha1.C = r.Client()
+	validator := &webhookquotav1alpha1.QuotaProfileCustomValidator{Offline: true}
+
+	var errs []error
+	for _, q := range quotaProfiles {
+		if _, err := validator.ValidateCreate(ctx, &q); err != nil {
+			errs = append(errs, fmt.Errorf("quota profile %s/%s is invalid: %w", q.Namespace, q.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
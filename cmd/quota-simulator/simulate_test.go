@@ -0,0 +1,235 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+const devProfile = `apiVersion: quota.dev.operator/v1alpha1
+kind: QuotaProfile
+metadata:
+  name: dev
+  namespace: quota-system
+spec:
+  namespaceSelector:
+    matchLabels:
+      environment: dev
+  precedence: 1
+  resourceQuotaSpecs:
+  - hard:
+      requests.cpu: "1"
+  limitRangeSpecs:
+  - limits:
+    - type: Container
+      default:
+        cpu: 500m
+  objectTemplates:
+  - template:
+      apiVersion: networking.k8s.io/v1
+      kind: NetworkPolicy
+      spec:
+        podSelector: {}
+        policyTypes:
+        - Egress
+  - template:
+      apiVersion: rbac.authorization.k8s.io/v1
+      kind: RoleBinding
+      roleRef:
+        apiGroup: rbac.authorization.k8s.io
+        kind: ClusterRole
+        name: view
+      subjects:
+      - kind: Group
+        name: developers
+`
+
+const teamProfile = `apiVersion: quota.dev.operator/v1alpha1
+kind: QuotaProfile
+metadata:
+  name: team-a
+  namespace: quota-system
+spec:
+  namespaceSelector:
+    matchLabels:
+      team: a
+  precedence: 5
+  resourceQuotaSpecs:
+  - hard:
+      requests.cpu: "4"
+`
+
+// teamProfileV1beta1 is teamProfile in the storage version
+const teamProfileV1beta1 = `apiVersion: quota.dev.operator/v1beta1
+kind: QuotaProfile
+metadata:
+  name: team-a
+  namespace: quota-system
+spec:
+  namespaceSelector:
+    labelSelector:
+      matchLabels:
+        team: a
+  precedence: 5
+  resourceQuotas:
+  - name: compute
+    hard:
+      requests.cpu: "4"
+`
+
+// the inventory is a kubectl List dump, the labels of the operator are ignored
+const inventory = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: team-a-dev
+    labels:
+      environment: dev
+      team: a
+      quota.dev.operator/profile: quota-system.dev
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: other-dev
+    labels:
+      environment: dev
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: kube-system
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestSimulate(t *testing.T) {
+	for version, team := range map[string]string{"v1alpha1": teamProfile, "v1beta1": teamProfileV1beta1} {
+		t.Run(version, func(t *testing.T) {
+			testSimulate(t, team)
+		})
+	}
+}
+
+func testSimulate(t *testing.T, team string) {
+	dir := t.TempDir()
+	profiles := filepath.Join(dir, "profiles")
+	if err := os.Mkdir(profiles, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, profiles, "dev.yaml", devProfile)
+	writeFile(t, profiles, "team-a.yaml", team)
+	namespaces := writeFile(t, dir, "namespaces.yaml", inventory)
+
+	out := &bytes.Buffer{}
+	if err := run(context.Background(), []string{"-f", profiles, "-namespaces", namespaces}, out); err != nil {
+		t.Fatal(err)
+	}
+
+	result := struct {
+		Bindings map[string]string `json:"bindings"`
+		Objects  []struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		} `json:"objects"`
+	}{}
+	if err := yaml.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, out.String())
+	}
+
+	expectedBindings := map[string]string{"team-a-dev": "quota-system.team-a", "other-dev": "quota-system.dev", "kube-system": ""}
+	if !reflect.DeepEqual(result.Bindings, expectedBindings) {
+		t.Errorf("expected bindings %v, got %v", expectedBindings, result.Bindings)
+	}
+
+	var objects []string
+	for _, obj := range result.Objects {
+		objects = append(objects, obj.Kind+" "+obj.Metadata.Namespace+"/"+obj.Metadata.Name)
+	}
+	expectedObjects := []string{
+		"LimitRange other-dev/quota-system-dev-0-lr",
+		"NetworkPolicy other-dev/quota-system-dev-0-networkpolicy",
+		"RoleBinding other-dev/quota-system-dev-0-rolebinding",
+		"ResourceQuota other-dev/quota-system-dev-0-rq",
+		"ResourceQuota team-a-dev/quota-system-team-a-0-rq",
+	}
+	if !reflect.DeepEqual(objects, expectedObjects) {
+		t.Errorf("expected objects %v, got %v", expectedObjects, objects)
+	}
+	if !strings.Contains(out.String(), "requests.cpu: \"4\"") {
+		t.Errorf("expected the team-a hard limit in the output:\n%s", out.String())
+	}
+}
+
+func TestSimulateRejectsInvalidProfiles(t *testing.T) {
+	for name, tc := range map[string]struct {
+		profiles []string
+		expected string
+	}{
+		"overlapping selectors": {
+			profiles: []string{devProfile, strings.NewReplacer("name: dev", "name: dev-copy", "precedence: 1", "precedence: 1\n  conflictPolicy: Reject").Replace(devProfile)},
+			expected: "overlaps with label environment=dev of quota profile quota-system/dev with the same precedence 1",
+		},
+		"invalid spec": {
+			profiles: []string{strings.ReplaceAll(teamProfile, `requests.cpu: "4"`, `requests.cpu: "-4"`)},
+			expected: "spec.resourceQuotaSpecs[0].hard[requests.cpu]",
+		},
+		"both selectors": {
+			profiles: []string{strings.ReplaceAll(teamProfile, "    matchLabels:", "    matchName: team-a\n    matchLabels:")},
+			expected: "only one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions or namespaceSelector.matchName can be set",
+		},
+		"precedence out of range": {
+			profiles: []string{strings.ReplaceAll(teamProfileV1beta1, "precedence: 5", "precedence: 70000")},
+			expected: "spec.precedence",
+		},
+		"duplicate profile": {
+			profiles: []string{teamProfile, teamProfile},
+			expected: "quota profile quota-system/team-a is defined more than once",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			args := []string{"-namespaces", writeFile(t, dir, "namespaces.yaml", inventory)}
+			for i, profile := range tc.profiles {
+				args = append(args, "-f", writeFile(t, dir, string(rune('a'+i))+".yaml", profile))
+			}
+
+			err := run(context.Background(), args, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
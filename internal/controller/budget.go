@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// budgetSharesChanged passes the namespaces that are created or deleted or whose binding or budget weight changed.
+// Other label changes, e.g. the timestamp label every reconcile of the profile updates on all bound namespaces,
+// do not change the shares, so the other namespaces of the budget are not enqueued for them.
+func (r *NamespaceReconciler) budgetSharesChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldLabels, newLabels := e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()
+			profileID := newLabels[quotav1alpha1.QuotaProfileLabelKey]
+			if oldLabels[quotav1alpha1.QuotaProfileLabelKey] != profileID {
+				return true
+			}
+
+			profileNamespace, profileName := quota.SplitProfileID(profileID)
+			if profileName == "" {
+				return false
+			}
+			profile := &quotav1alpha1.QuotaProfile{}
+			if err := r.Get(context.Background(), types.NamespacedName{Namespace: profileNamespace, Name: profileName}, profile); err != nil {
+				return false
+			}
+			if profile.Spec.Budget == nil || profile.Spec.Budget.WeightLabel == "" {
+				return false
+			}
+			return oldLabels[profile.Spec.Budget.WeightLabel] != newLabels[profile.Spec.Budget.WeightLabel]
+		},
+	}
+}
+
+// namespacesSharingBudget maps a namespace to the other namespaces bound to the same profile if the profile has a
+// budget, so that their shares are rebalanced when a namespace binds, unbinds or changes its weight.
+func (r *NamespaceReconciler) namespacesSharingBudget(ctx context.Context, obj client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+
+	profileID := obj.GetLabels()[quotav1alpha1.QuotaProfileLabelKey]
+	profileNamespace, profileName := quota.SplitProfileID(profileID)
+	if profileName == "" {
+		return nil
+	}
+
+	profile := &quotav1alpha1.QuotaProfile{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: profileNamespace, Name: profileName}, profile); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			l.Error(err, "failed to get quota profile", "profileNamespace", profileNamespace, "profileName", profileName)
+		}
+		return nil
+	}
+	if profile.Spec.Budget == nil {
+		return nil
+	}
+
+	namespaces, err := index.NamespacesWithLabel(ctx, r.Client, quotav1alpha1.QuotaProfileLabelKey, profileID)
+	if err != nil {
+		l.Error(err, "failed to list namespaces bound to quota profile", "profileID", profileID)
+		return nil
+	}
+
+	return lo.FilterMap(namespaces, func(ns v1.Namespace, _ int) (reconcile.Request, bool) {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Name: ns.Name}}, ns.Name != obj.GetName()
+	})
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+var _ = Describe("Budget", func() {
+	const profileID = "default.shared"
+
+	var (
+		ctx          context.Context
+		fakeClient   client.Client
+		quotaProfile *quotav1alpha1.QuotaProfile
+		reconciler   *NamespaceReconciler
+	)
+
+	boundNamespace := func(name string, labels map[string]string) *v1.Namespace {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"team": "shared", quotav1alpha1.QuotaProfileLabelKey: profileID},
+		}}
+		for key, value := range labels {
+			ns.Labels[key] = value
+		}
+		return ns
+	}
+
+	reconcileNamespace := func(name string) {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	budgetHard := func(namespace string) v1.ResourceList {
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "default-shared-0-rq"}, rq)).To(Succeed())
+		return rq.Spec.Hard
+	}
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+		s := setupFakeClientWithScheme()
+
+		quotaProfile = &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "shared"}},
+				Budget: &quotav1alpha1.QuotaBudget{
+					Hard: v1.ResourceList{
+						v1.ResourceRequestsCPU:    resource.MustParse("10"),
+						v1.ResourceRequestsMemory: resource.MustParse("30Gi"),
+					},
+					Strategy: quotav1alpha1.BudgetStrategyEqual,
+				},
+			},
+		}
+
+		fakeClient = newFakeClientBuilder(s).WithObjects(
+			quotaProfile,
+			boundNamespace("team-a", map[string]string{"weight": "3"}),
+			boundNamespace("team-b", nil),
+			boundNamespace("team-c", map[string]string{"weight": "invalid"}),
+		).Build()
+		reconciler = &NamespaceReconciler{Client: fakeClient, Scheme: s}
+	})
+
+	It("should split the budget equally", func() {
+		for _, name := range []string{"team-a", "team-b", "team-c"} {
+			reconcileNamespace(name)
+		}
+
+		// the millicore left over goes to the first namespace by name
+		Expect(budgetHard("team-a")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("3334m")))
+		Expect(budgetHard("team-b")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("3333m")))
+		Expect(budgetHard("team-c")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("3333m")))
+		Expect(budgetHard("team-c")).To(HaveKeyWithValue(v1.ResourceRequestsMemory, resource.MustParse("10Gi")))
+	})
+
+	It("should split the budget by the weights in the namespace labels", func() {
+		quotaProfile.Spec.Budget.Strategy = quotav1alpha1.BudgetStrategyWeighted
+		quotaProfile.Spec.Budget.WeightLabel = "weight"
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		reconcileNamespace("team-a")
+		reconcileNamespace("team-c")
+
+		Expect(budgetHard("team-a")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("6")))
+		Expect(budgetHard("team-c")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("2")))
+	})
+
+	It("should give fixed allocations and split the rest equally", func() {
+		quotaProfile.Spec.Budget.Strategy = quotav1alpha1.BudgetStrategyFixed
+		quotaProfile.Spec.Budget.Allocations = map[string]v1.ResourceList{
+			"team-a": {v1.ResourceRequestsCPU: resource.MustParse("6")},
+		}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		reconcileNamespace("team-a")
+		reconcileNamespace("team-b")
+
+		Expect(budgetHard("team-a")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("6")))
+		Expect(budgetHard("team-a")).To(HaveKeyWithValue(v1.ResourceRequestsMemory, resource.MustParse("10Gi")))
+		Expect(budgetHard("team-b")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("2")))
+	})
+
+	It("should rebalance the shares when a namespace is unbound", func() {
+		reconcileNamespace("team-a")
+
+		ns := &v1.Namespace{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "team-c"}, ns)).To(Succeed())
+		Expect(reconciler.namespacesSharingBudget(ctx, ns)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-b"}},
+		))
+
+		delete(ns.Labels, quotav1alpha1.QuotaProfileLabelKey)
+		Expect(fakeClient.Update(ctx, ns)).To(Succeed())
+		reconcileNamespace("team-a")
+
+		Expect(budgetHard("team-a")).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("5")))
+		Expect(budgetHard("team-a")).To(HaveKeyWithValue(v1.ResourceRequestsMemory, resource.MustParse("15Gi")))
+	})
+
+	It("should only enqueue the other namespaces when the binding or the weight changed", func() {
+		quotaProfile.Spec.Budget.Strategy = quotav1alpha1.BudgetStrategyWeighted
+		quotaProfile.Spec.Budget.WeightLabel = "weight"
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		oldNs := &v1.Namespace{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "team-a"}, oldNs)).To(Succeed())
+		changed := func(mutate func(labels map[string]string)) bool {
+			newNs := oldNs.DeepCopy()
+			mutate(newNs.Labels)
+			return reconciler.budgetSharesChanged().Update(event.UpdateEvent{ObjectOld: oldNs, ObjectNew: newNs})
+		}
+
+		Expect(changed(func(labels map[string]string) {
+			labels[quotav1alpha1.QuotaProfileLastUpdateTimestamp] = "1"
+		})).To(BeFalse())
+		Expect(changed(func(labels map[string]string) { labels["weight"] = "7" })).To(BeTrue())
+		Expect(changed(func(labels map[string]string) { delete(labels, quotav1alpha1.QuotaProfileLabelKey) })).To(BeTrue())
+
+		By("removing the budget")
+		quotaProfile.Spec.Budget = nil
+		quotaProfile.Spec.ResourceQuotaSpecs = []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}}}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		Expect(changed(func(labels map[string]string) { labels["weight"] = "7" })).To(BeFalse())
+	})
+
+	It("should keep the budget quota after the quotas of the resourceQuotaSpecs", func() {
+		quotaProfile.Spec.ResourceQuotaSpecs = []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}}}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		reconcileNamespace("team-a")
+
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "default-shared-0-rq"}, rq)).To(Succeed())
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourcePods, resource.MustParse("10")))
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "default-shared-1-rq"}, rq)).To(Succeed())
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("3334m")))
+	})
+
+	It("should not enqueue other namespaces of profiles without a budget", func() {
+		quotaProfile.Spec.Budget = nil
+		quotaProfile.Spec.ResourceQuotaSpecs = []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}}}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		Expect(reconciler.namespacesSharingBudget(ctx, boundNamespace("team-a", nil))).To(BeEmpty())
+	})
+})
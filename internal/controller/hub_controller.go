@@ -0,0 +1,462 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// HubReconciler runs in the hub cluster of a fleet. It applies the QuotaProfiles labelled for distribution to
+// every member cluster whose kubeconfig Secret is in Namespace, and reports the bound namespaces and errors of
+// every member cluster in the status of the profile. The operator must run in the member clusters as well, the
+// hub only keeps the copies of the profiles in sync.
+type HubReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Namespace holds the kubeconfig Secrets of the member clusters.
+	Namespace string
+
+	// SyncPeriod is the time after which the member clusters are synced again, to refresh their status.
+	SyncPeriod time.Duration
+
+	// NewMemberClient returns a client for the member cluster of a kubeconfig, NewMemberClient when not set.
+	NewMemberClient func(kubeconfig []byte, scheme *runtime.Scheme) (client.Client, error)
+
+	// APIReader reads the Secrets of removed member clusters, which are no longer in the cache of the manager once
+	// they lost the member cluster label. The Client is used when not set.
+	APIReader client.Reader
+
+	mu      sync.Mutex
+	members map[string]memberClient
+}
+
+// errMemberClusterGone is returned for a removed member cluster whose kubeconfig Secret is deleted and whose client
+// is not known from an earlier sync, so the copies of the profiles in it cannot be deleted.
+var errMemberClusterGone = errors.New("the kubeconfig Secret of the member cluster was deleted")
+
+// memberClient is the client of a member cluster, built from the given version of its Secret.
+type memberClient struct {
+	resourceVersion string
+	client          client.Client
+}
+
+// NewMemberClient returns a client for the cluster of the kubeconfig. Kubeconfigs whose users authenticate with an
+// exec plugin, an auth provider or a token file are rejected, as they would let whoever can write Secrets in the
+// member clusters namespace run commands in the operator's pod or send its files to any server.
+func NewMemberClient(kubeconfig []byte, scheme *runtime.Scheme) (client.Client, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	for name, authInfo := range config.AuthInfos {
+		if authInfo.Exec != nil {
+			return nil, fmt.Errorf("user %s of the kubeconfig uses an exec plugin, which is not allowed for member clusters", name)
+		}
+		if authInfo.AuthProvider != nil {
+			return nil, fmt.Errorf("user %s of the kubeconfig uses an auth provider, which is not allowed for member clusters", name)
+		}
+		if authInfo.TokenFile != "" {
+			return nil, fmt.Errorf("user %s of the kubeconfig uses a token file, which is not allowed for member clusters", name)
+		}
+	}
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: scheme})
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile applies a profile labelled for distribution to all member clusters and records their status, or
+// deletes its copies from the member clusters once the label is removed or the profile is deleted. The member
+// clusters in the status received a copy, the copy is deleted from the ones that are no longer labelled as members.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.20.0/pkg/reconcile
+func (r *HubReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+
+	quotaProfile := &quotav1alpha1.QuotaProfile{}
+	if err := r.Get(ctx, req.NamespacedName, quotaProfile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		l.Error(err, "failed to get quota profile", "quotaProfile", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	secrets := &v1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(r.Namespace), client.HasLabels{quotav1alpha1.MemberClusterLabelKey}); err != nil {
+		l.Error(err, "failed to list member clusters", "namespace", r.Namespace)
+		return ctrl.Result{}, err
+	}
+	sort.Slice(secrets.Items, func(i, j int) bool { return secrets.Items[i].Name < secrets.Items[j].Name })
+
+	if !isDistributed(quotaProfile) {
+		return ctrl.Result{}, r.withdraw(ctx, quotaProfile, secrets.Items)
+	}
+
+	if !controllerutil.ContainsFinalizer(quotaProfile, quotav1alpha1.QuotaProfileHubFinalizer) {
+		l.Info("adding hub finalizer", "quotaProfile", req.NamespacedName)
+		controllerutil.AddFinalizer(quotaProfile, quotav1alpha1.QuotaProfileHubFinalizer)
+		if err := r.Update(ctx, quotaProfile); err != nil {
+			l.Error(err, "failed to add hub finalizer", "quotaProfile", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+	}
+
+	members := make([]quotav1alpha1.MemberClusterStatus, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		member := quotav1alpha1.MemberClusterStatus{Name: secret.Name, LastSyncTime: lo.ToPtr(metav1.Now())}
+		if bound, err := r.distribute(ctx, quotaProfile, &secret); err != nil {
+			l.Error(err, "failed to distribute quota profile", "quotaProfile", req.NamespacedName, "memberCluster", secret.Name)
+			member.Error = err.Error()
+			member.ObservedGeneration, member.BoundNamespaces = previousMemberStatus(quotaProfile, secret.Name)
+		} else {
+			member.ObservedGeneration = quotaProfile.Generation
+			member.BoundNamespaces = bound
+		}
+		members = append(members, member)
+	}
+
+	// the status entry of a removed member cluster is only dropped once its copy is deleted, so that a copy that is
+	// still enforced there stays visible
+	for _, name := range removedMembers(quotaProfile, secrets.Items) {
+		if err := r.withdrawFromRemovedMember(ctx, quotaProfile, name); err != nil {
+			l.Error(err, "failed to delete quota profile from removed member cluster", "quotaProfile", req.NamespacedName, "memberCluster", name)
+			member, _ := lo.Find(quotaProfile.Status.MemberClusters, func(member quotav1alpha1.MemberClusterStatus) bool {
+				return member.Name == name
+			})
+			member.Error = fmt.Sprintf("the member cluster was removed but its copy of the profile could not be deleted: %v", err)
+			member.LastSyncTime = lo.ToPtr(metav1.Now())
+			members = append(members, member)
+		}
+	}
+
+	if err := r.updateMemberStatus(ctx, quotaProfile, members); err != nil {
+		l.Error(err, "failed to update member cluster status", "quotaProfile", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.SyncPeriod}, nil
+}
+
+// distribute creates or updates the copy of the profile in the member cluster of the Secret and returns the
+// number of namespaces bound to it there.
+func (r *HubReconciler) distribute(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, secret *v1.Secret) (int32, error) {
+	l := log.FromContext(ctx)
+
+	c, err := r.memberClient(secret)
+	if err != nil {
+		return 0, err
+	}
+
+	desired := memberCopy(quotaProfile)
+	existing := &quotav1alpha1.QuotaProfile{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return 0, err
+		}
+		if err := ensureNamespace(ctx, c, desired.Namespace); err != nil {
+			return 0, err
+		}
+		l.Info("creating quota profile in member cluster", "quotaProfile", client.ObjectKeyFromObject(desired), "memberCluster", secret.Name)
+		return 0, c.Create(ctx, desired)
+	}
+
+	if existing.Labels[quotav1alpha1.QuotaProfileDistributedLabelKey] != "true" {
+		return 0, fmt.Errorf("quota profile %s/%s exists in the member cluster and is not managed by the hub", existing.Namespace, existing.Name)
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec, desired.Spec) || !equality.Semantic.DeepEqual(existing.Labels, desired.Labels) ||
+		!equality.Semantic.DeepEqual(existing.Annotations, desired.Annotations) {
+		l.Info("updating quota profile in member cluster", "quotaProfile", client.ObjectKeyFromObject(desired), "memberCluster", secret.Name)
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		existing.Spec = desired.Spec
+		if err := c.Update(ctx, existing); err != nil {
+			return 0, err
+		}
+	}
+	return existing.Status.BoundNamespaces, nil
+}
+
+// withdraw deletes the copies of a profile that is no longer distributed from all member clusters, including
+// the removed ones in its status, then removes the hub finalizer and the member cluster status. A removed member
+// cluster whose Secret is deleted cannot be reached, its copy is left in place.
+func (r *HubReconciler) withdraw(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, secrets []v1.Secret) error {
+	l := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(quotaProfile, quotav1alpha1.QuotaProfileHubFinalizer) {
+		return nil
+	}
+
+	var errs []error
+	for _, secret := range secrets {
+		c, err := r.memberClient(&secret)
+		if err == nil {
+			err = r.deleteMemberCopy(ctx, quotaProfile, c, secret.Name)
+		}
+		if err != nil {
+			l.Error(err, "failed to delete quota profile from member cluster", "quotaProfile", quotaProfile.Name, "memberCluster", secret.Name)
+			errs = append(errs, fmt.Errorf("member cluster %s: %w", secret.Name, err))
+		}
+	}
+	for _, name := range removedMembers(quotaProfile, secrets) {
+		err := r.withdrawFromRemovedMember(ctx, quotaProfile, name)
+		if errors.Is(err, errMemberClusterGone) {
+			l.Info("cannot delete quota profile from removed member cluster, its copy is left in place", "quotaProfile", quotaProfile.Name, "memberCluster", name)
+			continue
+		}
+		if err != nil {
+			l.Error(err, "failed to delete quota profile from removed member cluster", "quotaProfile", quotaProfile.Name, "memberCluster", name)
+			errs = append(errs, fmt.Errorf("member cluster %s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if quotaProfile.DeletionTimestamp == nil && len(quotaProfile.Status.MemberClusters) > 0 {
+		if err := r.updateMemberStatus(ctx, quotaProfile, nil); err != nil {
+			return err
+		}
+	}
+
+	l.Info("removing hub finalizer", "quotaProfile", quotaProfile.Name)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(quotaProfile), quotaProfile); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !controllerutil.RemoveFinalizer(quotaProfile, quotav1alpha1.QuotaProfileHubFinalizer) {
+			return nil
+		}
+		return r.Update(ctx, quotaProfile)
+	})
+}
+
+// withdrawFromRemovedMember deletes the copy of the profile from a member cluster that is no longer labelled as a
+// member. Its client is built from its Secret while the Secret exists, otherwise the client of an earlier sync is used.
+func (r *HubReconciler) withdrawFromRemovedMember(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, name string) error {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	var c client.Client
+	secret := &v1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: name}, secret); err == nil {
+		if c, err = r.memberClient(secret); err != nil {
+			return err
+		}
+	} else if apierrors.IsNotFound(err) {
+		r.mu.Lock()
+		member, ok := r.members[name]
+		r.mu.Unlock()
+		if !ok {
+			return errMemberClusterGone
+		}
+		c = member.client
+	} else {
+		return err
+	}
+	return r.deleteMemberCopy(ctx, quotaProfile, c, name)
+}
+
+// deleteMemberCopy deletes the copy of the profile from a member cluster, if the hub manages it.
+func (r *HubReconciler) deleteMemberCopy(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, c client.Client, member string) error {
+	existing := &quotav1alpha1.QuotaProfile{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(quotaProfile), existing); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if existing.Labels[quotav1alpha1.QuotaProfileDistributedLabelKey] != "true" {
+		return nil
+	}
+	log.FromContext(ctx).Info("deleting quota profile from member cluster", "quotaProfile", client.ObjectKeyFromObject(existing), "memberCluster", member)
+	return client.IgnoreNotFound(c.Delete(ctx, existing))
+}
+
+// updateMemberStatus records the status of the member clusters in the profile. A member cluster whose status did
+// not change keeps its sync time, so that a sync without changes does not write the profile every SyncPeriod.
+func (r *HubReconciler) updateMemberStatus(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, members []quotav1alpha1.MemberClusterStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(quotaProfile), quotaProfile); err != nil {
+			return err
+		}
+		members := lo.Map(members, func(member quotav1alpha1.MemberClusterStatus, _ int) quotav1alpha1.MemberClusterStatus {
+			previous, found := lo.Find(quotaProfile.Status.MemberClusters, func(previous quotav1alpha1.MemberClusterStatus) bool {
+				return previous.Name == member.Name
+			})
+			lastSyncTime := previous.LastSyncTime
+			previous.LastSyncTime = member.LastSyncTime
+			if found && equality.Semantic.DeepEqual(previous, member) {
+				member.LastSyncTime = lastSyncTime
+			}
+			return member
+		})
+		if equality.Semantic.DeepEqual(quotaProfile.Status.MemberClusters, members) {
+			return nil
+		}
+		quotaProfile.Status.MemberClusters = members
+		return r.Status().Update(ctx, quotaProfile)
+	})
+}
+
+// memberClient returns the client of the member cluster of the Secret, which is built again when the Secret changes.
+func (r *HubReconciler) memberClient(secret *v1.Secret) (client.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if member, ok := r.members[secret.Name]; ok && member.resourceVersion == secret.ResourceVersion {
+		return member.client, nil
+	}
+
+	kubeconfig := secret.Data[quotav1alpha1.MemberClusterKubeconfigKey]
+	if len(kubeconfig) == 0 {
+		return nil, fmt.Errorf("secret %s/%s has no %s", secret.Namespace, secret.Name, quotav1alpha1.MemberClusterKubeconfigKey)
+	}
+	newMemberClient := r.NewMemberClient
+	if newMemberClient == nil {
+		newMemberClient = NewMemberClient
+	}
+	c, err := newMemberClient(kubeconfig, r.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a client from the kubeconfig of secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	if r.members == nil {
+		r.members = map[string]memberClient{}
+	}
+	r.members[secret.Name] = memberClient{resourceVersion: secret.ResourceVersion, client: c}
+	return c, nil
+}
+
+// ensureNamespace creates the namespace of a distributed profile in a member cluster where it does not exist.
+func ensureNamespace(ctx context.Context, c client.Client, name string) error {
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, &v1.Namespace{}); !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := c.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}); !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// memberCopy returns the copy of a distributed profile that is applied to the member clusters. It is marked as
+// distributed instead of for distribution, so a member cluster that is a hub itself does not distribute it again.
+func memberCopy(quotaProfile *quotav1alpha1.QuotaProfile) *quotav1alpha1.QuotaProfile {
+	labels := lo.OmitByKeys(quotaProfile.Labels, []string{quotav1alpha1.QuotaProfileDistributeLabelKey})
+	labels[quotav1alpha1.QuotaProfileDistributedLabelKey] = "true"
+	annotations := lo.OmitByKeys(quotaProfile.Annotations, []string{"kubectl.kubernetes.io/last-applied-configuration"})
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	return &quotav1alpha1.QuotaProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        quotaProfile.Name,
+			Namespace:   quotaProfile.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: *quotaProfile.Spec.DeepCopy(),
+	}
+}
+
+// previousMemberStatus returns the last applied generation and bound namespaces reported for the member cluster.
+func previousMemberStatus(quotaProfile *quotav1alpha1.QuotaProfile, name string) (int64, int32) {
+	member, _ := lo.Find(quotaProfile.Status.MemberClusters, func(member quotav1alpha1.MemberClusterStatus) bool {
+		return member.Name == name
+	})
+	return member.ObservedGeneration, member.BoundNamespaces
+}
+
+// removedMembers returns the member clusters in the status of the profile, i.e. the ones that received a copy,
+// that have no member cluster Secret anymore.
+func removedMembers(quotaProfile *quotav1alpha1.QuotaProfile, secrets []v1.Secret) []string {
+	current := lo.SliceToMap(secrets, func(secret v1.Secret) (string, bool) { return secret.Name, true })
+	var removed []string
+	for _, member := range quotaProfile.Status.MemberClusters {
+		if !current[member.Name] {
+			removed = append(removed, member.Name)
+		}
+	}
+	return removed
+}
+
+// isDistributed tells whether the profile is labelled for distribution and not being deleted.
+func isDistributed(quotaProfile *quotav1alpha1.QuotaProfile) bool {
+	return quotaProfile.DeletionTimestamp == nil && quotaProfile.Labels[quotav1alpha1.QuotaProfileDistributeLabelKey] == "true"
+}
+
+// distributedQuotaProfiles maps a member cluster Secret to all QuotaProfiles labelled for distribution, so that
+// new and changed member clusters get the profiles right away.
+func (r *HubReconciler) distributedQuotaProfiles(ctx context.Context, _ client.Object) []reconcile.Request {
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := r.List(ctx, quotaProfiles, client.MatchingLabels{quotav1alpha1.QuotaProfileDistributeLabelKey: "true"}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list distributed quota profiles")
+		return nil
+	}
+	return lo.Map(quotaProfiles.Items, func(q quotav1alpha1.QuotaProfile, _ int) reconcile.Request {
+		return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&q)}
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HubReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&quotav1alpha1.QuotaProfile{}, builder.WithPredicates(
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				_, distribute := obj.GetLabels()[quotav1alpha1.QuotaProfileDistributeLabelKey]
+				return distribute || controllerutil.ContainsFinalizer(obj, quotav1alpha1.QuotaProfileHubFinalizer)
+			}),
+			// the status written by the hub must not trigger another sync, the member clusters are synced every SyncPeriod
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{}),
+		)).
+		// the cache only holds labelled Secrets, removing the label is seen as a deletion
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.distributedQuotaProfiles),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				_, member := obj.GetLabels()[quotav1alpha1.MemberClusterLabelKey]
+				return obj.GetNamespace() == r.Namespace && member
+			}))).
+		Named("hub").
+		Complete(r)
+}
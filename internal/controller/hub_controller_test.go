@@ -0,0 +1,405 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+const memberClustersNamespace = "fleet-system"
+
+func newDistributedProfile() *quotav1alpha1.QuotaProfile {
+	return &quotav1alpha1.QuotaProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "team-a",
+			Namespace: "quota-system",
+			Labels:    map[string]string{quotav1alpha1.QuotaProfileDistributeLabelKey: "true", "team": "a"},
+		},
+		Spec: quotav1alpha1.QuotaProfileSpec{
+			NamespaceSelector:  quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+			Precedence:         5,
+			ResourceQuotaSpecs: []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("4")}}},
+		},
+	}
+}
+
+func newMemberClusterSecret(name string, kubeconfig []byte) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: memberClustersNamespace,
+			Labels:    map[string]string{quotav1alpha1.MemberClusterLabelKey: "true"},
+		},
+		Data: map[string][]byte{quotav1alpha1.MemberClusterKubeconfigKey: kubeconfig},
+	}
+}
+
+var _ = Describe("Hub Controller", func() {
+	var (
+		ctx          context.Context
+		hubClient    client.Client
+		members      map[string]client.Client
+		quotaProfile *quotav1alpha1.QuotaProfile
+		reconciler   *HubReconciler
+		req          reconcile.Request
+	)
+
+	reconcileHub := func() {
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hubClient.Get(ctx, req.NamespacedName, quotaProfile)).To(Succeed())
+	}
+
+	memberCopyOf := func(member string) (*quotav1alpha1.QuotaProfile, error) {
+		memberProfile := &quotav1alpha1.QuotaProfile{}
+		return memberProfile, members[member].Get(ctx, req.NamespacedName, memberProfile)
+	}
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+		s := setupFakeClientWithScheme()
+
+		quotaProfile = newDistributedProfile()
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(quotaProfile)}
+		hubClient = newFakeClientBuilder(s).WithObjects(
+			quotaProfile,
+			newMemberClusterSecret("member-a", []byte("member-a")),
+			newMemberClusterSecret("member-b", []byte("member-b")),
+			newMemberClusterSecret("unreachable", []byte("unreachable")),
+		).Build()
+
+		members = map[string]client.Client{
+			"member-a": newFakeClientBuilder(s).Build(),
+			"member-b": newFakeClientBuilder(s).Build(),
+		}
+		reconciler = &HubReconciler{
+			Client:    hubClient,
+			Scheme:    s,
+			Namespace: memberClustersNamespace,
+			NewMemberClient: func(kubeconfig []byte, _ *runtime.Scheme) (client.Client, error) {
+				if c, ok := members[string(kubeconfig)]; ok {
+					return c, nil
+				}
+				return nil, errors.New("connection refused")
+			},
+		}
+	})
+
+	It("should apply distributed profiles to every member cluster and report their status", func() {
+		reconcileHub()
+		Expect(quotaProfile.Finalizers).To(ContainElement(quotav1alpha1.QuotaProfileHubFinalizer))
+
+		for member := range members {
+			memberProfile, err := memberCopyOf(member)
+			Expect(err).NotTo(HaveOccurred(), member)
+			Expect(memberProfile.Labels).To(Equal(map[string]string{quotav1alpha1.QuotaProfileDistributedLabelKey: "true", "team": "a"}))
+			Expect(memberProfile.Spec).To(Equal(quotaProfile.Spec))
+			Expect(members[member].Get(ctx, client.ObjectKey{Name: "quota-system"}, &v1.Namespace{})).To(Succeed())
+		}
+
+		By("reporting the bound namespaces of the member clusters")
+		memberProfile, err := memberCopyOf("member-a")
+		Expect(err).NotTo(HaveOccurred())
+		memberProfile.Status.BoundNamespaces = 3
+		Expect(members["member-a"].Status().Update(ctx, memberProfile)).To(Succeed())
+		reconcileHub()
+
+		Expect(quotaProfile.Status.MemberClusters).To(HaveLen(3))
+		Expect(quotaProfile.Status.MemberClusters[0].Name).To(Equal("member-a"))
+		Expect(quotaProfile.Status.MemberClusters[0].BoundNamespaces).To(Equal(int32(3)))
+		Expect(quotaProfile.Status.MemberClusters[0].Error).To(BeEmpty())
+		Expect(quotaProfile.Status.MemberClusters[1].Name).To(Equal("member-b"))
+		Expect(quotaProfile.Status.MemberClusters[1].Error).To(BeEmpty())
+		Expect(quotaProfile.Status.MemberClusters[2].Name).To(Equal("unreachable"))
+		Expect(quotaProfile.Status.MemberClusters[2].Error).To(ContainSubstring("connection refused"))
+	})
+
+	It("should refuse to overwrite profiles of a member cluster that the hub does not manage", func() {
+		unmanaged := newDistributedProfile()
+		unmanaged.Labels = nil
+		unmanaged.Spec.Precedence = 1
+		Expect(members["member-b"].Create(ctx, unmanaged)).To(Succeed())
+
+		reconcileHub()
+		Expect(quotaProfile.Status.MemberClusters[1].Error).To(ContainSubstring("is not managed by the hub"))
+		Expect(quotaProfile.Status.MemberClusters[0].Error).To(BeEmpty())
+
+		memberProfile, err := memberCopyOf("member-b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(memberProfile.Spec.Precedence).To(Equal(uint16(1)))
+	})
+
+	It("should update the copies and delete them once the profile is no longer distributed", func() {
+		reconcileHub()
+
+		quotaProfile.Spec.Precedence = 10
+		Expect(hubClient.Update(ctx, quotaProfile)).To(Succeed())
+		reconcileHub()
+		memberProfile, err := memberCopyOf("member-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(memberProfile.Spec.Precedence).To(Equal(uint16(10)))
+		Expect(quotaProfile.Status.MemberClusters[0].ObservedGeneration).To(Equal(quotaProfile.Generation))
+
+		delete(quotaProfile.Labels, quotav1alpha1.QuotaProfileDistributeLabelKey)
+		Expect(hubClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		By("keeping the finalizer while a member cluster is unreachable")
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("member cluster unreachable")))
+		Expect(hubClient.Get(ctx, req.NamespacedName, quotaProfile)).To(Succeed())
+		Expect(quotaProfile.Finalizers).To(ContainElement(quotav1alpha1.QuotaProfileHubFinalizer))
+
+		Expect(hubClient.Delete(ctx, newMemberClusterSecret("unreachable", nil))).To(Succeed())
+		reconcileHub()
+		Expect(quotaProfile.Finalizers).NotTo(ContainElement(quotav1alpha1.QuotaProfileHubFinalizer))
+		Expect(quotaProfile.Status.MemberClusters).To(BeEmpty())
+		for member := range members {
+			_, err := memberCopyOf(member)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), member)
+		}
+	})
+
+	It("should delete the copy from a member cluster whose Secret lost the member label", func() {
+		reconcileHub()
+
+		secret := &v1.Secret{}
+		Expect(hubClient.Get(ctx, client.ObjectKey{Namespace: memberClustersNamespace, Name: "member-b"}, secret)).To(Succeed())
+		delete(secret.Labels, quotav1alpha1.MemberClusterLabelKey)
+		Expect(hubClient.Update(ctx, secret)).To(Succeed())
+		reconcileHub()
+
+		_, err := memberCopyOf("member-b")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		_, err = memberCopyOf("member-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quotaProfile.Status.MemberClusters).To(HaveLen(2))
+		Expect(quotaProfile.Status.MemberClusters[0].Name).To(Equal("member-a"))
+		Expect(quotaProfile.Status.MemberClusters[1].Name).To(Equal("unreachable"))
+	})
+
+	It("should delete the copy from a member cluster whose Secret was deleted with the client of the last sync", func() {
+		reconcileHub()
+
+		Expect(hubClient.Delete(ctx, newMemberClusterSecret("member-b", nil))).To(Succeed())
+		reconcileHub()
+
+		_, err := memberCopyOf("member-b")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(quotaProfile.Status.MemberClusters).To(HaveLen(2))
+	})
+
+	It("should keep reporting a removed member cluster whose copy cannot be deleted", func() {
+		reconcileHub()
+
+		By("restarting the hub after the Secret of a member cluster was deleted")
+		Expect(hubClient.Delete(ctx, newMemberClusterSecret("member-b", nil))).To(Succeed())
+		reconciler = &HubReconciler{Client: hubClient, Scheme: reconciler.Scheme, Namespace: memberClustersNamespace, NewMemberClient: reconciler.NewMemberClient}
+		reconcileHub()
+
+		_, err := memberCopyOf("member-b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quotaProfile.Status.MemberClusters).To(HaveLen(3))
+		Expect(quotaProfile.Status.MemberClusters[2].Name).To(Equal("member-b"))
+		Expect(quotaProfile.Status.MemberClusters[2].Error).To(ContainSubstring("the kubeconfig Secret of the member cluster was deleted"))
+
+		By("removing the finalizer once the profile is no longer distributed")
+		Expect(hubClient.Delete(ctx, newMemberClusterSecret("unreachable", nil))).To(Succeed())
+		delete(quotaProfile.Labels, quotav1alpha1.QuotaProfileDistributeLabelKey)
+		Expect(hubClient.Update(ctx, quotaProfile)).To(Succeed())
+		reconcileHub()
+		Expect(quotaProfile.Finalizers).NotTo(ContainElement(quotav1alpha1.QuotaProfileHubFinalizer))
+		Expect(quotaProfile.Status.MemberClusters).To(BeEmpty())
+	})
+
+	It("should not update the status when only the sync time changed", func() {
+		reconcileHub()
+		synced := quotaProfile.DeepCopy()
+
+		reconcileHub()
+		Expect(quotaProfile.ResourceVersion).To(Equal(synced.ResourceVersion))
+
+		By("updating the status once a member cluster reports a change")
+		memberProfile, err := memberCopyOf("member-b")
+		Expect(err).NotTo(HaveOccurred())
+		memberProfile.Status.BoundNamespaces = 2
+		Expect(members["member-b"].Status().Update(ctx, memberProfile)).To(Succeed())
+		reconcileHub()
+
+		Expect(quotaProfile.ResourceVersion).NotTo(Equal(synced.ResourceVersion))
+		Expect(quotaProfile.Status.MemberClusters[0].LastSyncTime).To(Equal(synced.Status.MemberClusters[0].LastSyncTime))
+		Expect(quotaProfile.Status.MemberClusters[1].BoundNamespaces).To(Equal(int32(2)))
+	})
+
+	It("should reject kubeconfigs that run commands or read files in the operator", func() {
+		kubeconfig := func(user string) []byte {
+			return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: member
+  cluster:
+    server: https://member.example.com:6443
+contexts:
+- name: member
+  context:
+    cluster: member
+    user: member
+current-context: member
+users:
+- name: member
+  user:
+` + user)
+		}
+
+		_, err := NewMemberClient(kubeconfig(`    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: /bin/sh
+`), reconciler.Scheme)
+		Expect(err).To(MatchError(ContainSubstring("uses an exec plugin")))
+
+		_, err = NewMemberClient(kubeconfig(`    auth-provider:
+      name: oidc
+`), reconciler.Scheme)
+		Expect(err).To(MatchError(ContainSubstring("uses an auth provider")))
+
+		_, err = NewMemberClient(kubeconfig(`    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token
+`), reconciler.Scheme)
+		Expect(err).To(MatchError(ContainSubstring("uses a token file")))
+
+		_, err = NewMemberClient(kubeconfig(`    token: member-token
+`), reconciler.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("Hub Controller against several API servers", Ordered, func() {
+	var (
+		ctx          context.Context
+		environments []*envtest.Environment
+		hubClient    client.Client
+		memberNames  = []string{"member-a", "member-b"}
+		members      = map[string]client.Client{}
+		reconciler   *HubReconciler
+		req          reconcile.Request
+	)
+
+	// startEnvironment starts an API server with the CRDs of the operator and returns a client for it
+	startEnvironment := func(s *runtime.Scheme) (*envtest.Environment, client.Client) {
+		testEnv := &envtest.Environment{
+			CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+			ErrorIfCRDPathMissing: true,
+		}
+		if getFirstFoundEnvTestBinaryDir() != "" {
+			testEnv.BinaryAssetsDirectory = getFirstFoundEnvTestBinaryDir()
+		}
+		environments = append(environments, testEnv)
+
+		cfg, err := testEnv.Start()
+		Expect(err).NotTo(HaveOccurred())
+		c, err := client.New(cfg, client.Options{Scheme: s})
+		Expect(err).NotTo(HaveOccurred())
+		return testEnv, c
+	}
+
+	BeforeAll(func() {
+		if os.Getenv("KUBEBUILDER_ASSETS") == "" && getFirstFoundEnvTestBinaryDir() == "" {
+			Skip("envtest binaries not found, run 'make setup-envtest' first")
+		}
+
+		ctx = context.Background()
+		s := setupFakeClientWithScheme()
+
+		_, hubClient = startEnvironment(s)
+		Expect(hubClient.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: memberClustersNamespace}})).To(Succeed())
+		Expect(hubClient.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "quota-system"}})).To(Succeed())
+
+		// every member cluster is reached through a kubeconfig Secret in the hub, like in a real fleet
+		for _, name := range memberNames {
+			testEnv, c := startEnvironment(s)
+			members[name] = c
+
+			user, err := testEnv.AddUser(envtest.User{Name: "quota-hub", Groups: []string{"system:masters"}}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			kubeconfig, err := user.KubeConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hubClient.Create(ctx, newMemberClusterSecret(name, kubeconfig))).To(Succeed())
+		}
+
+		quotaProfile := newDistributedProfile()
+		Expect(hubClient.Create(ctx, quotaProfile)).To(Succeed())
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(quotaProfile)}
+		reconciler = &HubReconciler{Client: hubClient, Scheme: s, Namespace: memberClustersNamespace}
+	})
+
+	AfterAll(func() {
+		for _, testEnv := range environments {
+			Expect(testEnv.Stop()).To(Succeed())
+		}
+	})
+
+	It("should apply the profile to every member cluster", func() {
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range memberNames {
+			memberProfile := &quotav1alpha1.QuotaProfile{}
+			Expect(members[name].Get(ctx, req.NamespacedName, memberProfile)).To(Succeed(), name)
+			Expect(memberProfile.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileDistributedLabelKey, "true"))
+			Expect(memberProfile.Spec.Precedence).To(Equal(uint16(5)))
+		}
+
+		quotaProfile := &quotav1alpha1.QuotaProfile{}
+		Expect(hubClient.Get(ctx, req.NamespacedName, quotaProfile)).To(Succeed())
+		Expect(quotaProfile.Status.MemberClusters).To(HaveLen(len(memberNames)))
+		for _, member := range quotaProfile.Status.MemberClusters {
+			Expect(member.Error).To(BeEmpty(), member.Name)
+		}
+	})
+
+	It("should delete the copies from every member cluster when the profile is deleted", func() {
+		quotaProfile := &quotav1alpha1.QuotaProfile{}
+		Expect(hubClient.Get(ctx, req.NamespacedName, quotaProfile)).To(Succeed())
+		Expect(hubClient.Delete(ctx, quotaProfile)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range memberNames {
+			err := members[name].Get(ctx, req.NamespacedName, &quotav1alpha1.QuotaProfile{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), name)
+		}
+		Expect(apierrors.IsNotFound(hubClient.Get(ctx, req.NamespacedName, quotaProfile))).To(BeTrue())
+	})
+})
@@ -0,0 +1,240 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// managedObjectKind describes a kind of namespaced object that is rendered from a QuotaProfile,
+// so that all managed kinds share the same create, update and prune logic.
+type managedObjectKind[T client.Object, L client.ObjectList] struct {
+	// name is the human readable name of the kind used in log messages, e.g. "resource quota"
+	name string
+
+	newObject func() T
+	newList   func() L
+	items     func(L) []T
+
+	// count returns the number of objects of this kind the profile declares
+	count func(q *quotav1alpha1.QuotaProfile) int
+
+	// objectName returns the name of the object rendered from the spec at the given index
+	objectName func(q *quotav1alpha1.QuotaProfile, index int) string
+
+	// render sets the desired state of the object rendered from the spec at the given index.
+	// It returns false when an existing object must be left unchanged.
+	render func(q *quotav1alpha1.QuotaProfile, index int, obj T) bool
+}
+
+// reconcileManagedObjects makes the objects of the given kind in the namespace match the quota profile.
+// Objects of other profiles and objects whose spec was removed from the profile are deleted, existing
+// objects are updated and missing ones are created. Unmanaged objects are never touched, unless they
+// carry the name of an object the profile declares, in which case they are adopted.
+//
+// It returns a description of every object that did not match the profile. With reportOnly set, the
+// differences are only reported and no object is changed.
+func reconcileManagedObjects[T client.Object, L client.ObjectList](ctx context.Context, c client.Client, l logr.Logger, q *quotav1alpha1.QuotaProfile, namespace string, kind managedObjectKind[T, L], reportOnly bool) ([]string, error) {
+	l.Info("reconciling managed objects", "kind", kind.name, "namespace", namespace, "profile", q.Name, "reportOnly", reportOnly)
+
+	list := kind.newList()
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		l.Error(err, "failed to list managed objects", "kind", kind.name, "namespace", namespace)
+		return nil, err
+	}
+
+	profileID := quota.ProfileID(q.Namespace, q.Name)
+	count := kind.count(q)
+	existing := make(map[int]T, count)
+	var drift []string
+	var errs []error
+
+	for _, obj := range kind.items(list) {
+		label, managed := obj.GetLabels()[quotav1alpha1.QuotaProfileLabelKey]
+		if !managed {
+			l.Info("skipping unmanaged object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			continue
+		}
+
+		if obj.GetDeletionTimestamp() != nil {
+			l.Info("skipping object with deletion timestamp", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			continue
+		}
+
+		if label != profileID {
+			drift = append(drift, fmt.Sprintf("%s %s/%s belongs to quota profile %s", kind.name, namespace, obj.GetName(), label))
+			if reportOnly {
+				continue
+			}
+			l.Info("deleting object with mismatched profile", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				l.Error(err, "failed to delete object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		index, err := getManagedObjectIndex(obj.GetName())
+		if err != nil || index >= count || obj.GetName() != kind.objectName(q, index) {
+			drift = append(drift, fmt.Sprintf("%s %s/%s is not declared by the profile", kind.name, namespace, obj.GetName()))
+			if reportOnly {
+				continue
+			}
+			l.Info("deleting object with out of bounds index", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				l.Error(err, "failed to delete object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		existing[index] = obj
+	}
+
+	for i := 0; i < count; i++ {
+		obj, found := existing[i]
+		if !found {
+			obj = kind.newObject()
+			name := kind.objectName(q, i)
+
+			err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+			if client.IgnoreNotFound(err) != nil {
+				l.Error(err, "failed to get object", "kind", kind.name, "namespace", namespace, "name", name)
+				errs = append(errs, err)
+				continue
+			}
+
+			if err != nil {
+				drift = append(drift, fmt.Sprintf("%s %s/%s is missing", kind.name, namespace, name))
+				if reportOnly {
+					continue
+				}
+
+				obj.SetName(name)
+				obj.SetNamespace(namespace)
+				obj.SetLabels(map[string]string{quotav1alpha1.QuotaProfileLabelKey: profileID})
+				kind.render(q, i, obj)
+
+				if err := c.Create(ctx, obj); err != nil {
+					l.Error(err, "failed to create object", "kind", kind.name, "namespace", namespace, "name", name)
+					errs = append(errs, err)
+				} else {
+					l.Info("successfully created object", "kind", kind.name, "namespace", namespace, "name", name)
+				}
+				continue
+			}
+
+			drift = append(drift, fmt.Sprintf("%s %s/%s is not managed", kind.name, namespace, name))
+			if reportOnly {
+				continue
+			}
+
+			l.Info("adopting unmanaged object", "kind", kind.name, "namespace", namespace, "name", name)
+			labels := obj.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[quotav1alpha1.QuotaProfileLabelKey] = profileID
+			obj.SetLabels(labels)
+		}
+
+		current := obj.DeepCopyObject()
+		if !kind.render(q, i, obj) {
+			continue
+		}
+
+		// adopted objects always need an update for their label
+		if found && equality.Semantic.DeepEqual(current, obj) {
+			continue
+		}
+		if found {
+			drift = append(drift, fmt.Sprintf("%s %s/%s differs from the profile", kind.name, namespace, obj.GetName()))
+			if reportOnly {
+				continue
+			}
+		}
+
+		if err := c.Update(ctx, obj); err != nil {
+			l.Error(err, "failed to update object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			errs = append(errs, err)
+		} else {
+			l.Info("successfully updated object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+		}
+	}
+
+	return drift, errors.Join(errs...)
+}
+
+// deleteManagedObjects deletes all objects of the given kind in the namespace that are managed by any quota profile.
+func deleteManagedObjects[T client.Object, L client.ObjectList](ctx context.Context, c client.Client, l logr.Logger, namespace string, kind managedObjectKind[T, L]) error {
+	l.Info("deleting managed objects", "kind", kind.name, "namespace", namespace)
+
+	list := kind.newList()
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		l.Error(err, "failed to list managed objects", "kind", kind.name, "namespace", namespace)
+		return err
+	}
+
+	var errs []error
+	for _, obj := range kind.items(list) {
+		if obj.GetDeletionTimestamp() != nil {
+			l.Info("skipping object with deletion timestamp", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			continue
+		}
+
+		if _, exists := obj.GetLabels()[quotav1alpha1.QuotaProfileLabelKey]; !exists {
+			l.Info("skipping unmanaged object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			continue
+		}
+
+		if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			l.Error(err, "failed to delete object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			errs = append(errs, err)
+		} else {
+			l.Info("successfully deleted object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// getManagedObjectIndex extracts the spec index from the name of a managed object, e.g. 1 from "ns-profile-1-rq"
+func getManagedObjectIndex(name string) (int, error) {
+	parts := strings.Split(name, "-")
+	if len(parts) < 2 {
+		return -1, fmt.Errorf("invalid managed object name: %s", name)
+	}
+
+	index, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return -1, fmt.Errorf("invalid index: %s", parts[len(parts)-2])
+	}
+
+	return index, nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+func newManagedObjectsProfile(namespace string) *quotav1alpha1.QuotaProfile {
+	return &quotav1alpha1.QuotaProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-profile",
+			Namespace: "default",
+		},
+		Spec: quotav1alpha1.QuotaProfileSpec{
+			NamespaceSelector: quotav1alpha1.NamespaceSelector{
+				MatchName: &namespace,
+			},
+			ResourceQuotaSpecs: []v1.ResourceQuotaSpec{
+				{Hard: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+				{Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}},
+			},
+			LimitRangeSpecs: []v1.LimitRangeSpec{
+				{Limits: []v1.LimitRangeItem{{Type: v1.LimitTypeContainer, Default: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}}}},
+			},
+		},
+	}
+}
+
+// expectManagedObjects reconciles the namespace, then checks the number of managed objects it contains.
+func expectManagedObjects(ctx context.Context, c client.Client, r *NamespaceReconciler, namespace string, rqs, lrs int) {
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespace}})
+	Expect(err).NotTo(HaveOccurred())
+
+	rqList := &v1.ResourceQuotaList{}
+	Expect(c.List(ctx, rqList, client.InNamespace(namespace), client.HasLabels{quotav1alpha1.QuotaProfileLabelKey})).To(Succeed())
+	Expect(rqList.Items).To(HaveLen(rqs))
+
+	lrList := &v1.LimitRangeList{}
+	Expect(c.List(ctx, lrList, client.InNamespace(namespace), client.HasLabels{quotav1alpha1.QuotaProfileLabelKey})).To(Succeed())
+	Expect(lrList.Items).To(HaveLen(lrs))
+}
+
+var _ = Describe("Managed objects", func() {
+	const namespaceName = "test-namespace"
+
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		profile    *quotav1alpha1.QuotaProfile
+		reconciler *NamespaceReconciler
+	)
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+
+		s := setupFakeClientWithScheme()
+		profile = newManagedObjectsProfile(namespaceName)
+		ns := &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespaceName,
+				Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.test-profile"},
+			},
+		}
+
+		fakeClient = fake.NewClientBuilder().WithScheme(s).WithObjects(ns, profile).Build()
+		reconciler = &NamespaceReconciler{Client: fakeClient, Scheme: s, log: log.Log.WithName("test")}
+	})
+
+	It("should create a LimitRange for a spec added to the profile", func() {
+		expectManagedObjects(ctx, fakeClient, reconciler, namespaceName, 2, 1)
+
+		profile.Spec.LimitRangeSpecs = append(profile.Spec.LimitRangeSpecs, v1.LimitRangeSpec{
+			Limits: []v1.LimitRangeItem{{Type: v1.LimitTypePod, Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}}},
+		})
+		Expect(fakeClient.Update(ctx, profile)).To(Succeed())
+
+		expectManagedObjects(ctx, fakeClient, reconciler, namespaceName, 2, 2)
+
+		lr := &v1.LimitRange{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: "default-test-profile-1-lr"}, lr)).To(Succeed())
+		Expect(lr.Spec.Limits[0].Type).To(Equal(v1.LimitTypePod))
+	})
+
+	It("should prune LimitRanges and ResourceQuotas for specs removed from the profile", func() {
+		profile.Spec.LimitRangeSpecs = append(profile.Spec.LimitRangeSpecs, profile.Spec.LimitRangeSpecs[0])
+		Expect(fakeClient.Update(ctx, profile)).To(Succeed())
+		expectManagedObjects(ctx, fakeClient, reconciler, namespaceName, 2, 2)
+
+		profile.Spec.ResourceQuotaSpecs = profile.Spec.ResourceQuotaSpecs[:1]
+		profile.Spec.LimitRangeSpecs = nil
+		Expect(fakeClient.Update(ctx, profile)).To(Succeed())
+
+		expectManagedObjects(ctx, fakeClient, reconciler, namespaceName, 1, 0)
+	})
+
+	It("should leave unmanaged LimitRanges alone", func() {
+		unmanaged := &v1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-defaults", Namespace: namespaceName},
+			Spec:       v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{{Type: v1.LimitTypeContainer}}},
+		}
+		Expect(fakeClient.Create(ctx, unmanaged)).To(Succeed())
+
+		expectManagedObjects(ctx, fakeClient, reconciler, namespaceName, 2, 1)
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(unmanaged), unmanaged)).To(Succeed())
+	})
+})
+
+var _ = Describe("Managed objects against an API server", Ordered, func() {
+	const namespaceName = "envtest-namespace"
+
+	var (
+		ctx        context.Context
+		testEnv    *envtest.Environment
+		k8sClient  client.Client
+		profile    *quotav1alpha1.QuotaProfile
+		reconciler *NamespaceReconciler
+	)
+
+	BeforeAll(func() {
+		if os.Getenv("KUBEBUILDER_ASSETS") == "" && getFirstFoundEnvTestBinaryDir() == "" {
+			Skip("envtest binaries not found, run 'make setup-envtest' first")
+		}
+
+		ctx = context.Background()
+		testEnv = &envtest.Environment{
+			CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+			ErrorIfCRDPathMissing: true,
+		}
+		if getFirstFoundEnvTestBinaryDir() != "" {
+			testEnv.BinaryAssetsDirectory = getFirstFoundEnvTestBinaryDir()
+		}
+
+		cfg, err := testEnv.Start()
+		Expect(err).NotTo(HaveOccurred())
+
+		s := setupFakeClientWithScheme()
+		k8sClient, err = client.New(cfg, client.Options{Scheme: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespaceName,
+				Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.test-profile"},
+			},
+		})).To(Succeed())
+
+		profile = newManagedObjectsProfile(namespaceName)
+		Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+
+		reconciler = &NamespaceReconciler{Client: k8sClient, Scheme: s, log: log.Log.WithName("envtest")}
+	})
+
+	AfterAll(func() {
+		if testEnv != nil {
+			Expect(testEnv.Stop()).To(Succeed())
+		}
+	})
+
+	It("should create all managed objects", func() {
+		expectManagedObjects(ctx, k8sClient, reconciler, namespaceName, 2, 1)
+	})
+
+	It("should create, update and prune LimitRanges like ResourceQuotas", func() {
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(profile), profile)).To(Succeed())
+		profile.Spec.LimitRangeSpecs[0].Limits[0].Default[v1.ResourceCPU] = resource.MustParse("250m")
+		profile.Spec.LimitRangeSpecs = append(profile.Spec.LimitRangeSpecs, v1.LimitRangeSpec{
+			Limits: []v1.LimitRangeItem{{Type: v1.LimitTypePod, Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}}},
+		})
+		Expect(k8sClient.Update(ctx, profile)).To(Succeed())
+
+		expectManagedObjects(ctx, k8sClient, reconciler, namespaceName, 2, 2)
+
+		lr := &v1.LimitRange{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: "default-test-profile-0-lr"}, lr)).To(Succeed())
+		Expect(lr.Spec.Limits[0].Default).To(HaveKeyWithValue(v1.ResourceCPU, resource.MustParse("250m")))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(profile), profile)).To(Succeed())
+		profile.Spec.ResourceQuotaSpecs = profile.Spec.ResourceQuotaSpecs[:1]
+		profile.Spec.LimitRangeSpecs = profile.Spec.LimitRangeSpecs[1:]
+		Expect(k8sClient.Update(ctx, profile)).To(Succeed())
+
+		expectManagedObjects(ctx, k8sClient, reconciler, namespaceName, 1, 1)
+	})
+})
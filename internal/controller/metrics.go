@@ -0,0 +1,41 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// driftedObjects is the number of managed objects per quota profile that the last resync found to differ
+	// from the profile.
+	driftedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "quota_profile_drifted_objects",
+		Help: "Number of managed objects that differed from their quota profile in the last resync",
+	}, []string{"quota_profile"})
+
+	// resyncs counts the periodic resyncs by mode, i.e. whether drift was corrected or only reported.
+	resyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "quota_profile_resyncs_total",
+		Help: "Total number of periodic resyncs of the namespaces bound to quota profiles",
+	}, []string{"mode"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftedObjects, resyncs)
+}
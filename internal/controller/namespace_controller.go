@@ -18,17 +18,21 @@ package controller
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strconv"
-	"strings"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
 	"github.com/go-logr/logr"
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -46,6 +50,7 @@ type NamespaceReconciler struct {
 
 // +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=limitranges,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -79,305 +84,239 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			return ctrl.Result{}, err
 		}
 
+		if err := r.deleteManagedObjectTemplates(ctx, ns.Name); err != nil {
+			r.log.Error(err, "failed to delete managed object templates", "namespace", ns.Name)
+			return ctrl.Result{}, err
+		}
+
+		if err := r.deleteStatus(ctx, ns.Name); err != nil {
+			r.log.Error(err, "failed to delete status config map", "namespace", ns.Name)
+			return ctrl.Result{}, err
+		}
+
 		r.log.Info("successfully cleaned up managed resources", "namespace", ns.Name)
 		return ctrl.Result{}, nil
 	} else {
 		profileID := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
-		profileNamespace, profileName := splitProfileID(profileID)
+		profileNamespace, profileName := quota.SplitProfileID(profileID)
 		r.log.Info("found quota profile label", "namespace", ns.Name, "profileID", profileID)
 
 		profile := &quotav1alpha1.QuotaProfile{}
 		if err := r.Get(ctx, types.NamespacedName{Namespace: profileNamespace, Name: profileName}, profile); err != nil {
 			r.log.Error(err, "failed to get quota profile", "profileNamespace", profileNamespace, "profileName", profileName)
+			if apierrors.IsNotFound(err) && ns.Labels[quotav1alpha1.QuotaProfileRetainedLabelKey] != "" {
+				return ctrl.Result{}, r.writeStatus(ctx, ns.Name, profileID, retainedReason)
+			}
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 
-		if err := r.reconcileResources(ctx, *profile, ns.Name); err != nil {
+		reason, err := r.bindingReason(ctx, ns, profile)
+		if err != nil {
+			r.log.Error(err, "failed to look up quota profiles for namespace", "namespace", ns.Name)
+			return ctrl.Result{}, err
+		}
+
+		if profile.Spec.Rollout != nil && ns.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] != strconv.FormatInt(profile.Generation, 10) {
+			managed, err := r.hasManagedResources(ctx, ns.Name, profileID)
+			if err != nil {
+				r.log.Error(err, "failed to check managed resources", "namespace", ns.Name)
+				return ctrl.Result{}, err
+			}
+
+			// namespaces that are not yet bound to the profile get the current spec right away,
+			// the others keep their resources until the rollout reaches them
+			if managed {
+				r.log.Info("namespace is waiting for quota profile rollout", "namespace", ns.Name, "profileID", profileID)
+				return ctrl.Result{}, r.writeStatus(ctx, ns.Name, profileID, reason+", waiting for the rollout of the latest profile change")
+			}
+		}
+
+		if _, err := r.reconcileResources(ctx, *profile, ns.Name, false); err != nil {
 			r.log.Error(err, "failed to reconcile quota profile", "namespace", ns.Name, "profileID", profileID)
 			return ctrl.Result{}, err
 		}
 
+		if err := r.writeStatus(ctx, ns.Name, profileID, reason); err != nil {
+			r.log.Error(err, "failed to write status config map", "namespace", ns.Name)
+			return ctrl.Result{}, err
+		}
+
 		r.log.Info("successfully reconciled quota profile", "namespace", ns.Name, "profileID", profileID)
 		return ctrl.Result{}, nil
 	}
 }
 
-func (r *NamespaceReconciler) reconcileResources(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string) error {
+// reconcileResources makes the managed resources of the namespace match the profile and returns a description
+// of every resource that did not. With reportOnly set, nothing is changed.
+func (r *NamespaceReconciler) reconcileResources(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string, reportOnly bool) ([]string, error) {
 	r.log.Info("reconciling resources", "namespace", namespace, "profile", q.Name)
 
-	if err := r.reconcileResourceQuotas(ctx, q, namespace); err != nil {
+	rqDrift, err := r.reconcileResourceQuotas(ctx, q, namespace, reportOnly)
+	if err != nil {
 		r.log.Error(err, "failed to reconcile resource quotas", "namespace", namespace, "profile", q.Name)
-		return err
+		return nil, err
 	}
 
-	if err := r.reconcileLimitRanges(ctx, q, namespace); err != nil {
+	lrDrift, err := r.reconcileLimitRanges(ctx, q, namespace, reportOnly)
+	if err != nil {
 		r.log.Error(err, "failed to reconcile limit ranges", "namespace", namespace, "profile", q.Name)
-		return err
+		return nil, err
 	}
 
-	r.log.Info("successfully reconciled quota profile", "namespace", namespace, "profile", q.Name)
-	return nil
-}
-
-func (r *NamespaceReconciler) reconcileResourceQuotas(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string) error {
-	r.log.Info("reconciling resource quotas", "namespace", namespace, "profile", q.Name)
-
-	rqs := &v1.ResourceQuotaList{}
-	if err := r.List(ctx, rqs, client.InNamespace(namespace)); err != nil {
-		r.log.Error(err, "failed to list resource quotas", "namespace", namespace)
-		return err
+	drift := append(rqDrift, lrDrift...)
+	for _, kind := range objectTemplateKinds() {
+		objectDrift, err := reconcileManagedObjects(ctx, r.Client, r.log, &q, namespace, kind, reportOnly)
+		if err != nil {
+			r.log.Error(err, "failed to reconcile object templates", "kind", kind.name, "namespace", namespace, "profile", q.Name)
+			return nil, err
+		}
+		drift = append(drift, objectDrift...)
 	}
 
-	if len(rqs.Items) != 0 {
-		for _, rg := range rqs.Items {
-			if _, exists := rg.Labels[quotav1alpha1.QuotaProfileLabelKey]; !exists {
-				r.log.Info("skipping unmanaged resource quota", "namespace", namespace, "name", rg.Name)
-				continue
-			}
+	r.log.Info("successfully reconciled quota profile", "namespace", namespace, "profile", q.Name)
+	return drift, nil
+}
 
-			if rg.Labels[quotav1alpha1.QuotaProfileLabelKey] != getProfileID(q.Namespace, q.Name) {
-				r.log.Info("deleting resource quota with mismatched profile", "namespace", namespace, "name", rg.Name)
-				if err := r.Delete(ctx, &rg); client.IgnoreNotFound(err) != nil {
-					r.log.Error(err, "failed to delete resource quota", "namespace", namespace, "name", rg.Name)
-				} else {
-					r.log.Info("successfully deleted resource quota", "namespace", namespace, "name", rg.Name)
-				}
-				continue
-			} else if rg.Labels[quotav1alpha1.QuotaProfileLabelKey] == getProfileID(q.Namespace, q.Name) {
-				index, err := getResourceQuotaIndex(rg.Name)
-				if err != nil {
-					r.log.Error(err, "failed to get resource quota index", "name", rg.Name)
-					continue
-				}
-
-				if index >= len(q.Spec.ResourceQuotaSpecs) {
-					r.log.Info("deleting resource quota with out of bounds index", "namespace", namespace, "name", rg.Name)
-					if err := r.Delete(ctx, &rg); client.IgnoreNotFound(err) != nil {
-						r.log.Error(err, "failed to delete resource quota", "namespace", namespace, "name", rg.Name)
-					} else {
-						r.log.Info("successfully deleted resource quota", "namespace", namespace, "name", rg.Name)
-					}
-					continue
-				}
-
-				rg.Spec = *q.Spec.ResourceQuotaSpecs[index].DeepCopy()
-				if err := r.Update(ctx, &rg); err != nil {
-					r.log.Error(err, "failed to update resource quota", "namespace", namespace, "name", rg.Name)
-				} else {
-					r.log.Info("successfully updated resource quota", "namespace", namespace, "name", rg.Name)
-				}
-			}
-		}
+func (r *NamespaceReconciler) reconcileResourceQuotas(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string, reportOnly bool) ([]string, error) {
+	adjustments, err := quota.AdjustmentsFor(ctx, r.Client, &q, namespace)
+	if err != nil {
+		r.log.Error(err, "failed to compute hard limits", "namespace", namespace, "profile", q.Name)
+		return nil, err
 	}
+	return reconcileManagedObjects(ctx, r.Client, r.log, &q, namespace, r.resourceQuotaKind(adjustments), reportOnly)
+}
 
-	for i, rqspec := range q.Spec.ResourceQuotaSpecs {
-		rq := &v1.ResourceQuota{}
-
-		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: getResourceQuotaID(q.Namespace, q.Name, strconv.Itoa(i))}, rq)
-		if client.IgnoreNotFound(err) != nil {
-			r.log.Error(err, "failed to get resource quota", "namespace", namespace, "name", rq.Name)
-			continue
-		}
+func (r *NamespaceReconciler) reconcileLimitRanges(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string, reportOnly bool) ([]string, error) {
+	return reconcileManagedObjects(ctx, r.Client, r.log, &q, namespace, limitRangeKind, reportOnly)
+}
 
-		rq.Spec = *rqspec.DeepCopy()
+func (r *NamespaceReconciler) deleteManagedResourceQuotas(ctx context.Context, namespace string) error {
+	return deleteManagedObjects(ctx, r.Client, r.log, namespace, r.resourceQuotaKind(quota.Adjustments{}))
+}
 
-		// This is the case where the resource quota is found, update it
-		if err == nil {
+func (r *NamespaceReconciler) deleteManagedLimitRanges(ctx context.Context, namespace string) error {
+	return deleteManagedObjects(ctx, r.Client, r.log, namespace, limitRangeKind)
+}
 
-			if err := r.Update(ctx, rq); err != nil {
-				r.log.Error(err, "failed to update resource quota", "namespace", namespace, "name", rq.Name)
-			} else {
-				r.log.Info("successfully updated resource quota", "namespace", namespace, "name", rq.Name)
-			}
-		} else {
-			// This is the case where the resource quota is not found, create it
-			rq.Name = getResourceQuotaID(q.Namespace, q.Name, strconv.Itoa(i))
-			rq.Namespace = namespace
-			rq.Labels = map[string]string{quotav1alpha1.QuotaProfileLabelKey: getProfileID(q.Namespace, q.Name)}
-
-			if err := r.Create(ctx, rq); err != nil {
-				r.log.Error(err, "failed to create resource quota", "namespace", namespace, "name", rq.Name)
-			} else {
-				r.log.Info("successfully created resource quota", "namespace", namespace, "name", rq.Name)
-			}
+func (r *NamespaceReconciler) deleteManagedObjectTemplates(ctx context.Context, namespace string) error {
+	var errs []error
+	for _, kind := range objectTemplateKinds() {
+		if err := deleteManagedObjects(ctx, r.Client, r.log, namespace, kind); err != nil {
+			errs = append(errs, err)
 		}
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
-func (r *NamespaceReconciler) reconcileLimitRanges(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string) error {
-	r.log.Info("reconciling limit ranges", "namespace", namespace, "profile", q.Name)
-
-	lrs := &v1.LimitRangeList{}
-	if err := r.List(ctx, lrs, client.InNamespace(namespace)); err != nil {
-		r.log.Error(err, "failed to list limit ranges", "namespace", namespace)
-		return err
-	}
-
-	if len(lrs.Items) != 0 {
-		for _, lr := range lrs.Items {
-			if _, exists := lr.Labels[quotav1alpha1.QuotaProfileLabelKey]; !exists {
-				r.log.Info("skipping unmanaged limit range", "namespace", namespace, "name", lr.Name)
-				continue
-			}
-
-			if lr.Labels[quotav1alpha1.QuotaProfileLabelKey] != getProfileID(q.Namespace, q.Name) {
-				r.log.Info("deleting limit range with mismatched profile", "namespace", namespace, "name", lr.Name)
-				if err := r.Delete(ctx, &lr); client.IgnoreNotFound(err) != nil {
-					r.log.Error(err, "failed to delete limit range", "namespace", namespace, "name", lr.Name)
-				} else {
-					r.log.Info("successfully deleted limit range", "namespace", namespace, "name", lr.Name)
-				}
-				continue
+// resourceQuotaKind describes the ResourceQuotas rendered from the resourceQuotaSpecs of a profile, and the budget
+// ResourceQuota after them if the profile has a budget, with the hard limits of quota.Hard.
+func (r *NamespaceReconciler) resourceQuotaKind(adjustments quota.Adjustments) managedObjectKind[*v1.ResourceQuota, *v1.ResourceQuotaList] {
+	return managedObjectKind[*v1.ResourceQuota, *v1.ResourceQuotaList]{
+		name:      "resource quota",
+		newObject: func() *v1.ResourceQuota { return &v1.ResourceQuota{} },
+		newList:   func() *v1.ResourceQuotaList { return &v1.ResourceQuotaList{} },
+		items:     func(l *v1.ResourceQuotaList) []*v1.ResourceQuota { return lo.ToSlicePtr(l.Items) },
+		count:     quota.ResourceQuotaCount,
+		objectName: func(q *quotav1alpha1.QuotaProfile, index int) string {
+			return quota.ResourceQuotaName(q.Namespace, q.Name, index)
+		},
+		render: func(q *quotav1alpha1.QuotaProfile, index int, rq *v1.ResourceQuota) bool {
+			desired := v1.ResourceQuotaSpec{}
+			if index < len(q.Spec.ResourceQuotaSpecs) {
+				desired = *q.Spec.ResourceQuotaSpecs[index].DeepCopy()
 			}
-
-			if lr.Labels[quotav1alpha1.QuotaProfileLabelKey] == getProfileID(q.Namespace, q.Name) {
-				index, err := getLimitRangeIndex(lr.Name)
-				if err != nil {
-					r.log.Error(err, "failed to get limit range index", "name", lr.Name)
-					continue
-				}
-
-				if index >= len(q.Spec.ResourceQuotaSpecs) {
-					r.log.Info("deleting limit range with out of bounds index", "namespace", namespace, "name", lr.Name)
-					if err := r.Delete(ctx, &lr); client.IgnoreNotFound(err) != nil {
-						r.log.Error(err, "failed to delete limit range", "namespace", namespace, "name", lr.Name)
-					} else {
-						r.log.Info("successfully deleted limit range", "namespace", namespace, "name", lr.Name)
-					}
-					continue
-				}
-
-				lr.Spec = *q.Spec.LimitRangeSpecs[index].DeepCopy()
-				if err := r.Update(ctx, &lr); err != nil {
-					r.log.Error(err, "failed to update limit range", "namespace", namespace, "name", lr.Name)
-				} else {
-					r.log.Info("successfully updated limit range", "namespace", namespace, "name", lr.Name)
-				}
-			}
-		}
-	} else {
-		for i, lrSpec := range q.Spec.LimitRangeSpecs {
-			lr := &v1.LimitRange{}
-			lr.Name = getLimitRangeID(q.Namespace, q.Name, strconv.Itoa(i))
-			lr.Namespace = namespace
-			lr.Labels = map[string]string{quotav1alpha1.QuotaProfileLabelKey: getProfileID(q.Namespace, q.Name)}
-			lr.Spec = *lrSpec.DeepCopy()
-			if err := r.Create(ctx, lr); err != nil {
-				r.log.Error(err, "failed to create limit range", "namespace", namespace, "name", lr.Name)
-			} else {
-				r.log.Info("successfully created limit range", "namespace", namespace, "name", lr.Name)
+			desired.Hard = quota.Hard(q, index, adjustments)
+			spec, ok := r.resourceQuotaSpecFor(*q, desired, rq)
+			if !ok {
+				return false
 			}
-		}
+			rq.Spec = spec
+			return true
+		},
 	}
-	return nil
 }
 
-func (r *NamespaceReconciler) deleteManagedResourceQuotas(ctx context.Context, namespace string) error {
-	r.log.Info("deleting managed resource quotas", "namespace", namespace)
-
-	rqs := &v1.ResourceQuotaList{}
-	if err := r.List(ctx, rqs, client.InNamespace(namespace)); err != nil {
-		r.log.Error(err, "failed to list resource quotas", "namespace", namespace)
-		return err
-	}
-
-	for _, rq := range rqs.Items {
-		if rq.DeletionTimestamp != nil {
-			r.log.Info("skipping resource quota with deletion timestamp", "namespace", namespace, "name", rq.Name)
-			continue
-		}
+// limitRangeKind describes the LimitRanges rendered from the limitRangeSpecs of a profile.
+var limitRangeKind = managedObjectKind[*v1.LimitRange, *v1.LimitRangeList]{
+	name:      "limit range",
+	newObject: func() *v1.LimitRange { return &v1.LimitRange{} },
+	newList:   func() *v1.LimitRangeList { return &v1.LimitRangeList{} },
+	items:     func(l *v1.LimitRangeList) []*v1.LimitRange { return lo.ToSlicePtr(l.Items) },
+	count:     func(q *quotav1alpha1.QuotaProfile) int { return len(q.Spec.LimitRangeSpecs) },
+	objectName: func(q *quotav1alpha1.QuotaProfile, index int) string {
+		return quota.LimitRangeName(q.Namespace, q.Name, index)
+	},
+	render: func(q *quotav1alpha1.QuotaProfile, index int, lr *v1.LimitRange) bool {
+		lr.Spec = *q.Spec.LimitRangeSpecs[index].DeepCopy()
+		return true
+	},
+}
 
-		if _, exists := rq.Labels[quotav1alpha1.QuotaProfileLabelKey]; !exists {
-			r.log.Info("skipping unmanaged resource quota", "namespace", namespace, "name", rq.Name)
+// resourceQuotaSpecFor returns the spec to apply to an existing managed resource quota. Hard limits of the desired
+// spec that are below the current usage are handled according to the profile's shrink policy. It returns false
+// when the resource quota must be left unchanged.
+func (r *NamespaceReconciler) resourceQuotaSpecFor(q quotav1alpha1.QuotaProfile, spec v1.ResourceQuotaSpec, rq *v1.ResourceQuota) (v1.ResourceQuotaSpec, bool) {
+	for name, hard := range spec.Hard {
+		used, ok := rq.Status.Used[name]
+		if !ok || used.Cmp(hard) <= 0 {
 			continue
 		}
 
-		if _, exists := rq.Labels[quotav1alpha1.QuotaProfileLabelKey]; exists {
-			if err := r.Delete(ctx, &rq); err != nil {
-				r.log.Error(err, "failed to delete resource quota", "namespace", namespace, "name", rq.Name)
-			} else {
-				r.log.Info("successfully deleted resource quota", "namespace", namespace, "name", rq.Name)
-			}
+		switch q.Spec.ShrinkPolicy {
+		case quotav1alpha1.QuotaShrinkPolicyBlock:
+			r.log.Info("keeping resource quota, new hard limit is below current usage", "namespace", rq.Namespace, "name", rq.Name, "resource", name, "hard", hard.String(), "used", used.String())
+			return v1.ResourceQuotaSpec{}, false
+		case quotav1alpha1.QuotaShrinkPolicyMax:
+			r.log.Info("raising hard limit to current usage", "namespace", rq.Namespace, "name", rq.Name, "resource", name, "hard", hard.String(), "used", used.String())
+			spec.Hard[name] = used.DeepCopy()
+		default:
+			r.log.Info("new hard limit is below current usage", "namespace", rq.Namespace, "name", rq.Name, "resource", name, "hard", hard.String(), "used", used.String())
 		}
 	}
 
-	return nil
+	return spec, true
 }
 
-func (r *NamespaceReconciler) deleteManagedLimitRanges(ctx context.Context, namespace string) error {
-	r.log.Info("deleting managed limit ranges", "namespace", namespace)
+// hasManagedResources reports whether the namespace already contains resources managed by the given profile.
+func (r *NamespaceReconciler) hasManagedResources(ctx context.Context, namespace, profileID string) (bool, error) {
+	rqs := &v1.ResourceQuotaList{}
+	if err := r.List(ctx, rqs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}); err != nil {
+		return false, err
+	}
+	if len(rqs.Items) > 0 {
+		return true, nil
+	}
 
 	lrs := &v1.LimitRangeList{}
-	if err := r.List(ctx, lrs, client.InNamespace(namespace)); err != nil {
-		r.log.Error(err, "failed to list limit ranges", "namespace", namespace)
-		return err
+	if err := r.List(ctx, lrs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}); err != nil {
+		return false, err
+	}
+	if len(lrs.Items) > 0 {
+		return true, nil
 	}
 
-	for _, lr := range lrs.Items {
-		if lr.DeletionTimestamp != nil {
-			r.log.Info("skipping limit range with deletion timestamp", "namespace", namespace, "name", lr.Name)
-			continue
-		}
-
-		if _, exists := lr.Labels[quotav1alpha1.QuotaProfileLabelKey]; !exists {
-			r.log.Info("skipping unmanaged limit range", "namespace", namespace, "name", lr.Name)
-			continue
+	for _, kind := range objectTemplateKinds() {
+		objs := kind.newList()
+		if err := r.List(ctx, objs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}); err != nil {
+			return false, err
 		}
-
-		if _, exists := lr.Labels[quotav1alpha1.QuotaProfileLabelKey]; exists {
-			if err := r.Delete(ctx, &lr); err != nil {
-				r.log.Error(err, "failed to delete limit range", "namespace", namespace, "name", lr.Name)
-			} else {
-				r.log.Info("successfully deleted limit range", "namespace", namespace, "name", lr.Name)
-			}
+		if len(objs.Items) > 0 {
+			return true, nil
 		}
 	}
-
-	return nil
-}
-
-// getResourceQuotaIndex splits the resource quota ID into namespace,  profile name, and index
-func getResourceQuotaIndex(id string) (int, error) {
-	parts := strings.Split(id, "-")
-
-	index, err := strconv.Atoi(parts[len(parts)-2])
-	if err != nil {
-		return -1, fmt.Errorf("invalid index: %s", parts[len(parts)-1])
-	}
-
-	return index, nil
-}
-
-// getLimitRangeIndex splits the limit range ID into namespace,  profile name, and index
-func getLimitRangeIndex(id string) (int, error) {
-	parts := strings.Split(id, "-")
-
-	index, err := strconv.Atoi(parts[len(parts)-2])
-	if err != nil {
-		return -1, fmt.Errorf("invalid index: %s", parts[len(parts)-1])
-	}
-
-	return index, nil
-}
-
-func getProfileID(namespace, profile string) string {
-	return fmt.Sprintf("%s.%s", namespace, profile)
-}
-
-func getResourceQuotaID(namespace, profile, index string) string {
-	return fmt.Sprintf("%s-%s-%s-rq", namespace, profile, index)
-}
-
-func getLimitRangeID(namespace, profile, index string) string {
-	return fmt.Sprintf("%s-%s-%s-lr", namespace, profile, index)
+	return false, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1.Namespace{}).
+		Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.namespacesSharingBudget),
+			builder.WithPredicates(r.budgetSharesChanged())).
+		Watches(&quotav1alpha1.QuotaRecommendation{}, handler.EnqueueRequestsFromMapFunc(recommendationNamespace),
+			builder.WithPredicates(recommendationChangedPredicate)).
+		Watches(&quotav1alpha1.QuotaRequest{}, handler.EnqueueRequestsFromMapFunc(quotaRequestNamespace),
+			builder.WithPredicates(quotaRequestDecidedPredicate)).
 		Named("namespace").
 		Complete(r)
 }
@@ -19,11 +19,14 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -43,6 +46,20 @@ func setupFakeClientWithScheme() *runtime.Scheme {
 	return s
 }
 
+// newFakeClientBuilder returns a fake client builder with the field indexes of the manager cache.
+func newFakeClientBuilder(s *runtime.Scheme) *fake.ClientBuilder {
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileMatchNameField, index.QuotaProfileMatchName).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileSelectorLabelKeyField, index.QuotaProfileSelectorLabelKeys).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileSelectorAnnotationKeyField, index.QuotaProfileSelectorAnnotationKeys).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileMatchNamePrefixField, index.QuotaProfileMatchNamePrefix).
+		WithIndex(&v1.Namespace{}, index.NamespaceLabelField, index.NamespaceLabels).
+		// the API server supports the reason field selector on Events, the fake client needs an index for it
+		WithIndex(&v1.Event{}, "reason", func(obj client.Object) []string { return []string{obj.(*v1.Event).Reason} }).
+		WithStatusSubresource(&quotav1alpha1.QuotaProfile{}, &quotav1alpha1.QuotaRecommendation{}, &quotav1alpha1.QuotaRequest{})
+}
+
 var _ = Describe("Namespace Controller", func() {
 	var (
 		ctx              context.Context
@@ -116,8 +133,7 @@ var _ = Describe("Namespace Controller", func() {
 			},
 		}
 
-		fakeClient = fake.NewClientBuilder().
-			WithScheme(s).
+		fakeClient = newFakeClientBuilder(s).
 			WithObjects(namespace, quotaProfile).
 			Build()
 
@@ -193,6 +209,56 @@ var _ = Describe("Namespace Controller", func() {
 			Expect(lr.Spec.Limits[0].Min).To(HaveKeyWithValue(v1.ResourceCPU, resource.MustParse("100m")))
 		})
 
+		It("should describe the bound profile in the status config map", func() {
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceName}}
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &v1.ConfigMap{}
+			cmKey := types.NamespacedName{Namespace: namespaceName, Name: quotav1alpha1.NamespaceStatusConfigMapName}
+			Expect(fakeClient.Get(ctx, cmKey, cm)).To(Succeed())
+			Expect(cm.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, "default.test-profile"))
+			Expect(cm.Data).To(HaveKeyWithValue("profile", "default/test-profile"))
+			Expect(cm.Data).To(HaveKeyWithValue("reason", "selected by label environment=test with precedence 10"))
+			Expect(cm.Data["resourceQuotas"]).To(Equal("default-test-profile-0-rq:\n  cpu: \"1\"\n  memory: 1Gi\n"))
+			Expect(cm.Data["limitRanges"]).To(ContainSubstring("default-test-profile-0-lr:"))
+			Expect(cm.Data["limitRanges"]).To(ContainSubstring("cpu: 500m"))
+			Expect(time.Parse(time.RFC3339, cm.Data["lastSyncTime"])).Error().NotTo(HaveOccurred())
+
+			By("Not writing the config map again when only the sync time changed")
+			cm.Data["lastSyncTime"] = "2025-01-01T00:00:00Z"
+			Expect(fakeClient.Update(ctx, cm)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			unchanged := &v1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, cmKey, unchanged)).To(Succeed())
+			Expect(unchanged.ResourceVersion).To(Equal(cm.ResourceVersion))
+			Expect(unchanged.Data).To(HaveKeyWithValue("lastSyncTime", "2025-01-01T00:00:00Z"))
+
+			By("Unbinding the namespace")
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: namespaceName}, namespace)).To(Succeed())
+			delete(namespace.Labels, quotav1alpha1.QuotaProfileLabelKey)
+			Expect(fakeClient.Update(ctx, namespace)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apierrors.IsNotFound(fakeClient.Get(ctx, cmKey, cm))).To(BeTrue())
+		})
+
+		It("should leave an unmanaged config map with the status name alone", func() {
+			unmanaged := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: quotav1alpha1.NamespaceStatusConfigMapName, Namespace: namespaceName},
+				Data:       map[string]string{"owner": "tenant"},
+			}
+			Expect(fakeClient.Create(ctx, unmanaged)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceName}})
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &v1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(unmanaged), cm)).To(Succeed())
+			Expect(cm.Data).To(Equal(map[string]string{"owner": "tenant"}))
+		})
+
 		It("should update ResourceQuota and LimitRange when profile changes", func() {
 			// First reconcile
 			req := reconcile.Request{
@@ -228,6 +294,72 @@ var _ = Describe("Namespace Controller", func() {
 			Expect(updatedRqList.Items[0].Spec.Hard[v1.ResourceMemory]).To(Equal(resource.MustParse("2Gi")))
 		})
 
+		It("should keep existing ResourceQuota until the rollout reaches the namespace", func() {
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: namespaceName,
+				},
+			}
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Enable staged rollout and change the profile
+			quotaProfile.Generation = 2
+			quotaProfile.Spec.Rollout = &quotav1alpha1.RolloutStrategy{MaxNamespacesPerInterval: 1}
+			quotaProfile.Spec.ResourceQuotaSpecs[0].Hard[v1.ResourceCPU] = resource.MustParse("2")
+			Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			rqList := &v1.ResourceQuotaList{}
+			Expect(fakeClient.List(ctx, rqList, client.InNamespace(namespaceName))).To(Succeed())
+			Expect(rqList.Items).To(HaveLen(1))
+			Expect(rqList.Items[0].Spec.Hard[v1.ResourceCPU]).To(Equal(resource.MustParse("1")))
+
+			// The rollout reaches the namespace
+			updatedNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: namespaceName}, updatedNs)).To(Succeed())
+			updatedNs.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] = fmt.Sprintf("%d", quotaProfile.Generation)
+			Expect(fakeClient.Update(ctx, updatedNs)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.List(ctx, rqList, client.InNamespace(namespaceName))).To(Succeed())
+			Expect(rqList.Items[0].Spec.Hard[v1.ResourceCPU]).To(Equal(resource.MustParse("2")))
+		})
+
+		It("should not lower ResourceQuota below the current usage with the Max shrink policy", func() {
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: namespaceName,
+				},
+			}
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			rqList := &v1.ResourceQuotaList{}
+			Expect(fakeClient.List(ctx, rqList, client.InNamespace(namespaceName))).To(Succeed())
+			Expect(rqList.Items).To(HaveLen(1))
+
+			rq := rqList.Items[0]
+			rq.Status.Used = v1.ResourceList{v1.ResourceCPU: resource.MustParse("800m")}
+			Expect(fakeClient.Update(ctx, &rq)).To(Succeed())
+
+			quotaProfile.Spec.ShrinkPolicy = quotav1alpha1.QuotaShrinkPolicyMax
+			quotaProfile.Spec.ResourceQuotaSpecs[0].Hard[v1.ResourceCPU] = resource.MustParse("500m")
+			Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.List(ctx, rqList, client.InNamespace(namespaceName))).To(Succeed())
+			Expect(rqList.Items[0].Spec.Hard[v1.ResourceCPU]).To(Equal(resource.MustParse("800m")))
+		})
+
 		It("should remove ResourceQuota and LimitRange when quota profile label is removed", func() {
 			req := reconcile.Request{
 				NamespacedName: types.NamespacedName{
@@ -352,8 +484,7 @@ var _ = Describe("Namespace Controller", func() {
 			}
 
 			// Create new client with both profiles
-			testClient := fake.NewClientBuilder().
-				WithScheme(s).
+			testClient := newFakeClientBuilder(s).
 				WithObjects(ns, profile1, profile2).
 				Build()
 
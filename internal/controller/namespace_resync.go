@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// maxDriftedObjectsInStatus limits the drifted objects listed in the status of a quota profile.
+const maxDriftedObjectsInStatus = 20
+
+// NamespaceResyncer periodically compares the managed resources of all bound namespaces with their quota
+// profiles. The namespace controller only runs on namespace events, so changes that no event reports, like
+// an etcd restore or an edit made while the webhooks were unavailable, are only found by the resync.
+type NamespaceResyncer struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Period is the time between two resyncs.
+	Period time.Duration
+
+	// ReportOnly records the drift in the status of the quota profiles without correcting it.
+	ReportOnly bool
+}
+
+var _ manager.LeaderElectionRunnable = &NamespaceResyncer{}
+
+// Start runs a resync every period until the context is cancelled. The first resync runs after one period,
+// as the namespace controller reconciles all namespaces on start anyway.
+func (r *NamespaceResyncer) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("resync")
+	ctx = log.IntoContext(ctx, l)
+
+	ticker := time.NewTicker(r.Period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Resync(ctx); err != nil {
+				l.Error(err, "failed to resync namespaces")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection makes only the leader correct drift.
+func (r *NamespaceResyncer) NeedLeaderElection() bool {
+	return true
+}
+
+// Resync compares the managed resources of every bound namespace with its quota profile, corrects the
+// differences unless ReportOnly is set, and records them in the status and metrics of the profiles.
+// Namespaces waiting for a staged rollout are skipped, as they are expected to differ from the profile.
+func (r *NamespaceResyncer) Resync(ctx context.Context) error {
+	l := log.FromContext(ctx)
+	l.Info("starting resync", "reportOnly", r.ReportOnly)
+
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := r.List(ctx, quotaProfiles); err != nil {
+		l.Error(err, "failed to list quota profiles")
+		return err
+	}
+	profiles := lo.SliceToMap(quotaProfiles.Items, func(q quotav1alpha1.QuotaProfile) (string, quotav1alpha1.QuotaProfile) {
+		return quota.ProfileID(q.Namespace, q.Name), q
+	})
+
+	namespaces := &v1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.HasLabels{quotav1alpha1.QuotaProfileLabelKey}); err != nil {
+		l.Error(err, "failed to list bound namespaces")
+		return err
+	}
+
+	reconciler := &NamespaceReconciler{Client: r.Client, Scheme: r.Scheme, log: l}
+	drift := map[string][]string{}
+	var errs []error
+	for _, ns := range namespaces.Items {
+		profileID := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
+		profile, found := profiles[profileID]
+		if !found || profile.DeletionTimestamp != nil || ns.DeletionTimestamp != nil {
+			continue
+		}
+		if profile.Spec.Rollout != nil && ns.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] != strconv.FormatInt(profile.Generation, 10) {
+			l.Info("skipping namespace waiting for quota profile rollout", "namespace", ns.Name, "profileID", profileID)
+			continue
+		}
+
+		nsDrift, err := reconciler.reconcileResources(ctx, profile, ns.Name, r.ReportOnly)
+		if err != nil {
+			l.Error(err, "failed to resync namespace", "namespace", ns.Name, "profileID", profileID)
+			errs = append(errs, err)
+		}
+		if len(nsDrift) > 0 {
+			l.Info("found drift", "namespace", ns.Name, "profileID", profileID, "drift", nsDrift)
+		}
+		drift[profileID] = append(drift[profileID], nsDrift...)
+	}
+
+	mode := "correct"
+	if r.ReportOnly {
+		mode = "report-only"
+	}
+	resyncs.WithLabelValues(mode).Inc()
+
+	// profiles that were deleted since the last resync must not keep reporting drift
+	driftedObjects.Reset()
+	now := metav1.Now()
+	for profileID, profile := range profiles {
+		driftedObjects.WithLabelValues(profileID).Set(float64(len(drift[profileID])))
+
+		status := &quotav1alpha1.DriftStatus{
+			LastCheckTime:  now,
+			DriftedObjects: int32(len(drift[profileID])),
+			Corrected:      !r.ReportOnly && len(drift[profileID]) > 0,
+			Objects:        lo.Slice(drift[profileID], 0, maxDriftedObjectsInStatus),
+		}
+		if !driftChanged(profile.Status.Drift, status) {
+			continue
+		}
+		if err := r.updateDriftStatus(ctx, &profile, status); client.IgnoreNotFound(err) != nil {
+			l.Error(err, "failed to update drift status", "profileID", profileID)
+			errs = append(errs, err)
+		}
+	}
+
+	l.Info("finished resync", "reportOnly", r.ReportOnly, "driftedObjects", len(lo.Flatten(lo.Values(drift))))
+	return errors.Join(errs...)
+}
+
+// driftChanged reports whether the drift differs from the recorded one other than in the check time, so that a resync
+// which finds the same drift does not write the status of every profile.
+func driftChanged(recorded, drift *quotav1alpha1.DriftStatus) bool {
+	if recorded == nil {
+		return true
+	}
+	recorded = recorded.DeepCopy()
+	recorded.LastCheckTime = drift.LastCheckTime
+	return !equality.Semantic.DeepEqual(recorded, drift)
+}
+
+// updateDriftStatus sets the drift status of the quota profile, retrying on the latest version after a conflict.
+func (r *NamespaceResyncer) updateDriftStatus(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, status *quotav1alpha1.DriftStatus) error {
+	attempt := 0
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		attempt++
+		if attempt > 1 {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(quotaProfile), quotaProfile); err != nil {
+				return err
+			}
+		}
+
+		quotaProfile.Status.Drift = status
+		return r.Status().Update(ctx, quotaProfile)
+	})
+}
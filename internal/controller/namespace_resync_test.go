@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+var _ = Describe("Namespace Resync", func() {
+	const profileID = "default.test-profile"
+
+	var (
+		ctx          context.Context
+		fakeClient   client.Client
+		s            *runtime.Scheme
+		quotaProfile *quotav1alpha1.QuotaProfile
+		resyncer     *NamespaceResyncer
+	)
+
+	rqKey := types.NamespacedName{Namespace: "team-a", Name: "default-test-profile-0-rq"}
+	lrKey := types.NamespacedName{Namespace: "team-a", Name: "default-test-profile-0-lr"}
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+		s = setupFakeClientWithScheme()
+
+		quotaProfile = &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-profile", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+				ResourceQuotaSpecs: []v1.ResourceQuotaSpec{{
+					Hard: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				}},
+				LimitRangeSpecs: []v1.LimitRangeSpec{{
+					Limits: []v1.LimitRangeItem{{
+						Type:    v1.LimitTypeContainer,
+						Default: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+					}},
+				}},
+			},
+		}
+		namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{"team": "a", quotav1alpha1.QuotaProfileLabelKey: profileID},
+		}}
+
+		fakeClient = newFakeClientBuilder(s).WithObjects(quotaProfile, namespace).Build()
+		resyncer = &NamespaceResyncer{Client: fakeClient, Scheme: s}
+
+		reconciler := &NamespaceReconciler{Client: fakeClient, Scheme: s}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// drift changes the managed resource quota and deletes the managed limit range behind the operator's back
+	drift := func() {
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, rqKey, rq)).To(Succeed())
+		rq.Spec.Hard[v1.ResourceCPU] = resource.MustParse("100")
+		Expect(fakeClient.Update(ctx, rq)).To(Succeed())
+
+		lr := &v1.LimitRange{}
+		Expect(fakeClient.Get(ctx, lrKey, lr)).To(Succeed())
+		Expect(fakeClient.Delete(ctx, lr)).To(Succeed())
+	}
+
+	driftStatus := func() *quotav1alpha1.DriftStatus {
+		q := &quotav1alpha1.QuotaProfile{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), q)).To(Succeed())
+		return q.Status.Drift
+	}
+
+	It("should report no drift when the managed resources match the profile", func() {
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, rqKey, rq)).To(Succeed())
+
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+
+		status := driftStatus()
+		Expect(status).NotTo(BeNil())
+		Expect(status.DriftedObjects).To(BeZero())
+		Expect(status.Corrected).To(BeFalse())
+		Expect(status.Objects).To(BeEmpty())
+		Expect(testutil.ToFloat64(driftedObjects.WithLabelValues(profileID))).To(BeZero())
+
+		// unchanged objects are not written again
+		unchanged := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, rqKey, unchanged)).To(Succeed())
+		Expect(unchanged.ResourceVersion).To(Equal(rq.ResourceVersion))
+	})
+
+	It("should not update the status when the drift did not change", func() {
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+		q := &quotav1alpha1.QuotaProfile{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), q)).To(Succeed())
+
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+
+		unchanged := &quotav1alpha1.QuotaProfile{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), unchanged)).To(Succeed())
+		Expect(unchanged.ResourceVersion).To(Equal(q.ResourceVersion))
+		Expect(unchanged.Status.Drift.LastCheckTime).To(Equal(q.Status.Drift.LastCheckTime))
+
+		By("updating the status once drift is found")
+		drift()
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+		Expect(driftStatus().DriftedObjects).To(BeEquivalentTo(2))
+	})
+
+	It("should correct drift and record it in the status and metrics", func() {
+		drift()
+
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, rqKey, rq)).To(Succeed())
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourceCPU, resource.MustParse("2")))
+		Expect(fakeClient.Get(ctx, lrKey, &v1.LimitRange{})).To(Succeed())
+
+		status := driftStatus()
+		Expect(status.DriftedObjects).To(BeEquivalentTo(2))
+		Expect(status.Corrected).To(BeTrue())
+		Expect(status.Objects).To(ConsistOf(
+			"resource quota team-a/default-test-profile-0-rq differs from the profile",
+			"limit range team-a/default-test-profile-0-lr is missing",
+		))
+		Expect(testutil.ToFloat64(driftedObjects.WithLabelValues(profileID))).To(BeEquivalentTo(2))
+
+		By("finding no drift in the next resync")
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+		Expect(driftStatus().DriftedObjects).To(BeZero())
+		Expect(testutil.ToFloat64(driftedObjects.WithLabelValues(profileID))).To(BeZero())
+	})
+
+	It("should only record drift in report only mode", func() {
+		resyncer.ReportOnly = true
+		drift()
+
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, rqKey, rq)).To(Succeed())
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourceCPU, resource.MustParse("100")))
+		Expect(fakeClient.Get(ctx, lrKey, &v1.LimitRange{})).NotTo(Succeed())
+
+		status := driftStatus()
+		Expect(status.DriftedObjects).To(BeEquivalentTo(2))
+		Expect(status.Corrected).To(BeFalse())
+		Expect(testutil.ToFloat64(driftedObjects.WithLabelValues(profileID))).To(BeEquivalentTo(2))
+	})
+
+	It("should skip namespaces waiting for a staged rollout", func() {
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), quotaProfile)).To(Succeed())
+		quotaProfile.Spec.Rollout = &quotav1alpha1.RolloutStrategy{MaxNamespacesPerInterval: 1}
+		quotaProfile.Generation = 2
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		drift()
+
+		Expect(resyncer.Resync(ctx)).To(Succeed())
+
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, rqKey, rq)).To(Succeed())
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourceCPU, resource.MustParse("100")))
+		Expect(driftStatus().DriftedObjects).To(BeZero())
+	})
+})
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// Keys of the namespace status ConfigMap.
+const (
+	statusProfileKey        = "profile"
+	statusReasonKey         = "reason"
+	statusResourceQuotasKey = "resourceQuotas"
+	statusLimitRangesKey    = "limitRanges"
+	statusLastSyncTimeKey   = "lastSyncTime"
+)
+
+// retainedReason is the reason of namespaces whose profile was deleted with the Retain policy.
+const retainedReason = "the profile was deleted with the Retain policy, its resources are kept until another profile binds"
+
+// writeStatus writes the status ConfigMap of a bound namespace with the profile, why it was chosen,
+// the hard limits and limits in effect and the time of the sync that last changed them.
+func (r *NamespaceReconciler) writeStatus(ctx context.Context, namespace, profileID, reason string) error {
+	data, err := r.statusData(ctx, namespace, profileID, reason)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: quotav1alpha1.NamespaceStatusConfigMapName}, cm)
+	if apierrors.IsNotFound(err) {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      quotav1alpha1.NamespaceStatusConfigMapName,
+				Namespace: namespace,
+				Labels:    map[string]string{quotav1alpha1.QuotaProfileLabelKey: profileID},
+			},
+			Data: data,
+		}
+		if err := r.Create(ctx, cm); err != nil {
+			// the cache only holds the operator's ConfigMaps, an unmanaged one with the same name is left alone
+			if apierrors.IsAlreadyExists(err) {
+				r.log.Info("skipping status config map, an unmanaged config map with the same name exists", "namespace", namespace)
+				return nil
+			}
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, managed := cm.Labels[quotav1alpha1.QuotaProfileLabelKey]; !managed {
+		r.log.Info("skipping status config map, an unmanaged config map with the same name exists", "namespace", namespace)
+		return nil
+	}
+	// only the time changed, reconciling an unchanged namespace does not write the ConfigMap again
+	if cm.Labels[quotav1alpha1.QuotaProfileLabelKey] == profileID &&
+		equality.Semantic.DeepEqual(lo.OmitByKeys(cm.Data, []string{statusLastSyncTimeKey}), lo.OmitByKeys(data, []string{statusLastSyncTimeKey})) {
+		return nil
+	}
+	cm.Labels[quotav1alpha1.QuotaProfileLabelKey] = profileID
+	cm.Data = data
+	return r.Update(ctx, cm)
+}
+
+// deleteStatus deletes the status ConfigMap of a namespace that is no longer bound to a profile.
+func (r *NamespaceReconciler) deleteStatus(ctx context.Context, namespace string) error {
+	cm := &v1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: quotav1alpha1.NamespaceStatusConfigMapName}, cm); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, managed := cm.Labels[quotav1alpha1.QuotaProfileLabelKey]; !managed {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, cm))
+}
+
+// statusData returns the content of the status ConfigMap. The limits are read from the managed objects
+// in the namespace, so they show what is enforced, e.g. while a rollout has not reached the namespace yet.
+func (r *NamespaceReconciler) statusData(ctx context.Context, namespace, profileID, reason string) (map[string]string, error) {
+	managed := client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}
+
+	rqs := &v1.ResourceQuotaList{}
+	if err := r.List(ctx, rqs, client.InNamespace(namespace), managed); err != nil {
+		return nil, err
+	}
+	hard := map[string]v1.ResourceList{}
+	for _, rq := range rqs.Items {
+		hard[rq.Name] = rq.Spec.Hard
+	}
+
+	lrs := &v1.LimitRangeList{}
+	if err := r.List(ctx, lrs, client.InNamespace(namespace), managed); err != nil {
+		return nil, err
+	}
+	limits := map[string][]v1.LimitRangeItem{}
+	for _, lr := range lrs.Items {
+		limits[lr.Name] = lr.Spec.Limits
+	}
+
+	hardYAML, err := yaml.Marshal(hard)
+	if err != nil {
+		return nil, err
+	}
+	limitsYAML, err := yaml.Marshal(limits)
+	if err != nil {
+		return nil, err
+	}
+
+	profileNamespace, profileName := quota.SplitProfileID(profileID)
+	return map[string]string{
+		statusProfileKey:        profileNamespace + "/" + profileName,
+		statusReasonKey:         reason,
+		statusResourceQuotasKey: string(hardYAML),
+		statusLimitRangesKey:    string(limitsYAML),
+		statusLastSyncTimeKey:   time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// bindingReason describes why the namespace is bound to the profile rather than to the other profiles selecting it,
+// with the decision trace of index.Decide.
+func (r *NamespaceReconciler) bindingReason(ctx context.Context, ns *v1.Namespace, profile *quotav1alpha1.QuotaProfile) (string, error) {
+	if profile.Spec.NamespaceSelector.MatchName != nil {
+		return "selected by name, name selectors take precedence over all other selectors", nil
+	}
+
+	candidates, err := index.QuotaProfilesForNamespace(ctx, r.Client, ns)
+	if err != nil {
+		return "", err
+	}
+
+	reason := fmt.Sprintf("selected by %s with precedence %d", index.SelectorString(profile.Spec.NamespaceSelector), profile.Spec.Precedence)
+	if decision := index.Decide(candidates, ns, time.Now()); len(decision.Trace) > 0 {
+		reason += "; " + strings.Join(decision.Trace, "; ")
+	}
+	return reason, nil
+}
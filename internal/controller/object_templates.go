@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+
+// objectTemplateKind describes the objects of one kind that are rendered from the objectTemplates of a profile.
+// The objects are named after their position among the templates of the same kind, e.g. "ns-profile-0-networkpolicy".
+func objectTemplateKind(gvk schema.GroupVersionKind) managedObjectKind[*unstructured.Unstructured, *unstructured.UnstructuredList] {
+	return managedObjectKind[*unstructured.Unstructured, *unstructured.UnstructuredList]{
+		name: gvk.Kind,
+		newObject: func() *unstructured.Unstructured {
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(gvk)
+			return u
+		},
+		newList: func() *unstructured.UnstructuredList {
+			l := &unstructured.UnstructuredList{}
+			l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			return l
+		},
+		items: func(l *unstructured.UnstructuredList) []*unstructured.Unstructured { return lo.ToSlicePtr(l.Items) },
+		count: func(q *quotav1alpha1.QuotaProfile) int { return len(objectTemplatesOfKind(q, gvk)) },
+		objectName: func(q *quotav1alpha1.QuotaProfile, index int) string {
+			return quota.ObjectTemplateName(q.Namespace, q.Name, index, gvk.Kind)
+		},
+		render: func(q *quotav1alpha1.QuotaProfile, index int, obj *unstructured.Unstructured) bool {
+			renderObjectTemplate(objectTemplatesOfKind(q, gvk)[index], obj)
+			return true
+		},
+	}
+}
+
+// objectTemplateKinds returns the managed object kinds of all supported object templates.
+func objectTemplateKinds() []managedObjectKind[*unstructured.Unstructured, *unstructured.UnstructuredList] {
+	return lo.Map(quotav1alpha1.ObjectTemplateKinds, func(gvk schema.GroupVersionKind, _ int) managedObjectKind[*unstructured.Unstructured, *unstructured.UnstructuredList] {
+		return objectTemplateKind(gvk)
+	})
+}
+
+// objectTemplatesOfKind returns the templates of the profile with the given kind, in the order of the profile.
+// Templates that cannot be decoded are skipped, the CRD schema only admits objects with apiVersion and kind.
+func objectTemplatesOfKind(q *quotav1alpha1.QuotaProfile, gvk schema.GroupVersionKind) []*unstructured.Unstructured {
+	var templates []*unstructured.Unstructured
+	for _, t := range q.Spec.ObjectTemplates {
+		template, err := decodeObjectTemplate(t)
+		if err != nil || template.GroupVersionKind() != gvk {
+			continue
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+// decodeObjectTemplate returns the object of the template, which is held as raw JSON when read from the API server.
+func decodeObjectTemplate(t quotav1alpha1.ObjectTemplate) (*unstructured.Unstructured, error) {
+	raw := t.Template.Raw
+	if len(raw) == 0 && t.Template.Object != nil {
+		var err error
+		if raw, err = json.Marshal(t.Template.Object); err != nil {
+			return nil, err
+		}
+	}
+
+	obj, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected an object but got %T", obj)
+	}
+	return u, nil
+}
+
+// renderObjectTemplate sets the fields of the template on the object and copies its labels and annotations.
+// The API server defaults fields the template does not set, so an object matches its template when the
+// template's fields are a subset of the object's. The hash of the template is recorded on the object, so that
+// fields removed from the template are also removed from the object, which a subset check alone would miss.
+func renderObjectTemplate(template, obj *unstructured.Unstructured) {
+	hash := objectTemplateHash(template)
+	desired := lo.OmitByKeys(template.Object, []string{"apiVersion", "kind", "metadata", "status"})
+
+	if obj.GetAnnotations()[quotav1alpha1.ObjectTemplateHashAnnotationKey] == hash &&
+		isSubset(desired, obj.Object) &&
+		isSubset(toInterfaceMap(template.GetLabels()), toInterfaceMap(obj.GetLabels())) &&
+		isSubset(toInterfaceMap(template.GetAnnotations()), toInterfaceMap(obj.GetAnnotations())) {
+		return
+	}
+
+	for key := range lo.OmitByKeys(obj.Object, []string{"apiVersion", "kind", "metadata", "status"}) {
+		delete(obj.Object, key)
+	}
+	for key, value := range desired {
+		obj.Object[key] = runtime.DeepCopyJSONValue(value)
+	}
+
+	// the profile label identifies the object as managed and cannot be overridden by the template
+	labels := lo.Assign(obj.GetLabels(), lo.OmitByKeys(template.GetLabels(), []string{quotav1alpha1.QuotaProfileLabelKey}))
+	obj.SetLabels(labels)
+	annotations := lo.Assign(obj.GetAnnotations(), template.GetAnnotations())
+	annotations[quotav1alpha1.ObjectTemplateHashAnnotationKey] = hash
+	obj.SetAnnotations(annotations)
+}
+
+// objectTemplateHash returns a short hash of the template, map keys are sorted when encoding so it is stable.
+func objectTemplateHash(template *unstructured.Unstructured) string {
+	data, _ := json.Marshal(template.Object)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// isSubset reports whether every field of desired is set to the same value in actual. Maps may contain
+// additional keys, lists must have the same length with every item being a subset of the actual one.
+func isSubset(desired, actual interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return len(d) == 0 && actual == nil
+		}
+		for key, value := range d {
+			if !isSubset(value, a[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(d) {
+			return len(d) == 0 && actual == nil
+		}
+		for i := range d {
+			if !isSubset(d[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, actual)
+	}
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	return lo.MapValues(m, func(v string, _ string) interface{} { return v })
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+var _ = Describe("Object Templates", func() {
+	const profileID = "default.tenant"
+
+	var (
+		ctx          context.Context
+		fakeClient   client.Client
+		quotaProfile *quotav1alpha1.QuotaProfile
+		namespace    *v1.Namespace
+		reconciler   *NamespaceReconciler
+	)
+
+	npKey := types.NamespacedName{Namespace: "team-a", Name: "default-tenant-0-networkpolicy"}
+	rbKey := types.NamespacedName{Namespace: "team-a", Name: "default-tenant-0-rolebinding"}
+
+	template := func(raw string) quotav1alpha1.ObjectTemplate {
+		return quotav1alpha1.ObjectTemplate{Template: runtime.RawExtension{Raw: []byte(raw)}}
+	}
+	denyEgress := template(`{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","metadata":{"labels":{"policy":"deny-egress"}},"spec":{"podSelector":{},"policyTypes":["Egress"]}}`)
+	viewers := template(`{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"RoleBinding","roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"view"},"subjects":[{"kind":"Group","name":"team-a"}]}`)
+
+	reconcileNamespace := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+		s := setupFakeClientWithScheme()
+
+		quotaProfile = &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+				ObjectTemplates:   []quotav1alpha1.ObjectTemplate{denyEgress, viewers},
+			},
+		}
+		namespace = &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{"team": "a", quotav1alpha1.QuotaProfileLabelKey: profileID},
+		}}
+
+		fakeClient = newFakeClientBuilder(s).WithObjects(quotaProfile, namespace).Build()
+		reconciler = &NamespaceReconciler{Client: fakeClient, Scheme: s}
+	})
+
+	It("should create an object for every template", func() {
+		reconcileNamespace()
+
+		np := &networkingv1.NetworkPolicy{}
+		Expect(fakeClient.Get(ctx, npKey, np)).To(Succeed())
+		Expect(np.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, profileID))
+		Expect(np.Labels).To(HaveKeyWithValue("policy", "deny-egress"))
+		Expect(np.Annotations).To(HaveKey(quotav1alpha1.ObjectTemplateHashAnnotationKey))
+		Expect(np.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeEgress))
+
+		rb := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(ctx, rbKey, rb)).To(Succeed())
+		Expect(rb.RoleRef.Name).To(Equal("view"))
+		Expect(rb.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "Group", Name: "team-a"}))
+	})
+
+	It("should keep fields defaulted by the API server", func() {
+		reconcileNamespace()
+
+		rb := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(ctx, rbKey, rb)).To(Succeed())
+		rb.Subjects[0].APIGroup = rbacv1.GroupName
+		Expect(fakeClient.Update(ctx, rb)).To(Succeed())
+
+		drift, err := reconciler.reconcileResources(ctx, *quotaProfile, "team-a", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drift).To(BeEmpty())
+
+		unchanged := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(ctx, rbKey, unchanged)).To(Succeed())
+		Expect(unchanged.ResourceVersion).To(Equal(rb.ResourceVersion))
+	})
+
+	It("should reset changed objects", func() {
+		reconcileNamespace()
+
+		np := &networkingv1.NetworkPolicy{}
+		Expect(fakeClient.Get(ctx, npKey, np)).To(Succeed())
+		np.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		Expect(fakeClient.Update(ctx, np)).To(Succeed())
+
+		drift, err := reconciler.reconcileResources(ctx, *quotaProfile, "team-a", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drift).To(ConsistOf("NetworkPolicy team-a/default-tenant-0-networkpolicy differs from the profile"))
+
+		Expect(fakeClient.Get(ctx, npKey, np)).To(Succeed())
+		Expect(np.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeEgress))
+	})
+
+	It("should remove fields that were removed from the template", func() {
+		reconcileNamespace()
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), quotaProfile)).To(Succeed())
+		quotaProfile.Spec.ObjectTemplates[0] = template(`{"apiVersion":"networking.k8s.io/v1","kind":"NetworkPolicy","spec":{"podSelector":{}}}`)
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		reconcileNamespace()
+
+		np := &networkingv1.NetworkPolicy{}
+		Expect(fakeClient.Get(ctx, npKey, np)).To(Succeed())
+		Expect(np.Spec.PolicyTypes).To(BeEmpty())
+	})
+
+	It("should delete objects whose template was removed", func() {
+		reconcileNamespace()
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), quotaProfile)).To(Succeed())
+		quotaProfile.Spec.ObjectTemplates = []quotav1alpha1.ObjectTemplate{denyEgress}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		reconcileNamespace()
+
+		Expect(fakeClient.Get(ctx, npKey, &networkingv1.NetworkPolicy{})).To(Succeed())
+		Expect(fakeClient.Get(ctx, rbKey, &rbacv1.RoleBinding{})).NotTo(Succeed())
+	})
+
+	It("should delete the objects when the namespace is unbound", func() {
+		reconcileNamespace()
+
+		unmanaged := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "allow-ingress", Namespace: "team-a"}}
+		Expect(fakeClient.Create(ctx, unmanaged)).To(Succeed())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)).To(Succeed())
+		delete(namespace.Labels, quotav1alpha1.QuotaProfileLabelKey)
+		Expect(fakeClient.Update(ctx, namespace)).To(Succeed())
+		reconcileNamespace()
+
+		Expect(fakeClient.Get(ctx, npKey, &networkingv1.NetworkPolicy{})).NotTo(Succeed())
+		Expect(fakeClient.Get(ctx, rbKey, &rbacv1.RoleBinding{})).NotTo(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(unmanaged), &networkingv1.NetworkPolicy{})).To(Succeed())
+	})
+})
@@ -0,0 +1,341 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// +kubebuilder:rbac:groups=quota.dev.operator,resources=quotarecommendations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=quota.dev.operator,resources=quotarecommendations/status,verbs=get;update;patch
+
+// maxUsageSamplesInStatus limits the samples of a ResourceQuota in the status of a QuotaRecommendation, so that
+// long windows with short intervals do not grow the object beyond what the API server accepts.
+const maxUsageSamplesInStatus = 100
+
+// QuotaRecommender periodically samples the usage of the managed ResourceQuotas of profiles that enable
+// recommendations and writes the recommended hard limits into a QuotaRecommendation in every bound namespace.
+// The samples of the window are kept in memory and in the status of the QuotaRecommendations, from which they
+// are restored after a restart or a change of the leader.
+type QuotaRecommender struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Interval is the time between two samples.
+	Interval time.Duration
+
+	// Window is how long samples are kept, the recommendations are based on the peak usage in the window.
+	Window time.Duration
+
+	mu      sync.Mutex
+	windows map[types.NamespacedName][]quotav1alpha1.UsageSample
+}
+
+var _ manager.LeaderElectionRunnable = &QuotaRecommender{}
+
+// Start takes a sample every interval until the context is cancelled.
+func (r *QuotaRecommender) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("recommender")
+	ctx = log.IntoContext(ctx, l)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Sample(ctx, time.Now()); err != nil {
+				l.Error(err, "failed to sample resource quota usage")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection makes only the leader sample, so that every sample is recorded once.
+func (r *QuotaRecommender) NeedLeaderElection() bool {
+	return true
+}
+
+// Sample records the usage of the managed ResourceQuotas of every namespace bound to a profile that enables
+// recommendations and updates the QuotaRecommendations. QuotaRecommendations of namespaces that are no longer
+// bound to such a profile are deleted.
+func (r *QuotaRecommender) Sample(ctx context.Context, now time.Time) error {
+	l := log.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.windows == nil {
+		r.windows = map[types.NamespacedName][]quotav1alpha1.UsageSample{}
+	}
+
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := r.List(ctx, quotaProfiles); err != nil {
+		l.Error(err, "failed to list quota profiles")
+		return err
+	}
+	profiles := lo.SliceToMap(lo.Filter(quotaProfiles.Items, func(q quotav1alpha1.QuotaProfile, _ int) bool {
+		return q.Spec.Recommendation != nil && q.DeletionTimestamp == nil
+	}), func(q quotav1alpha1.QuotaProfile) (string, quotav1alpha1.QuotaProfile) {
+		return quota.ProfileID(q.Namespace, q.Name), q
+	})
+
+	namespaces := &v1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.HasLabels{quotav1alpha1.QuotaProfileLabelKey}); err != nil {
+		l.Error(err, "failed to list bound namespaces")
+		return err
+	}
+
+	var errs []error
+	sampled := map[string]bool{}
+	for _, ns := range namespaces.Items {
+		profileID := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
+		profile, found := profiles[profileID]
+		if !found || ns.DeletionTimestamp != nil {
+			continue
+		}
+		sampled[ns.Name] = true
+		if err := r.recommend(ctx, profile, ns.Name, now); err != nil {
+			l.Error(err, "failed to update quota recommendation", "namespace", ns.Name, "profileID", profileID)
+			errs = append(errs, err)
+		}
+	}
+
+	for key := range r.windows {
+		if !sampled[key.Namespace] {
+			delete(r.windows, key)
+		}
+	}
+
+	recommendations := &quotav1alpha1.QuotaRecommendationList{}
+	if err := r.List(ctx, recommendations); err != nil {
+		l.Error(err, "failed to list quota recommendations")
+		return errors.Join(append(errs, err)...)
+	}
+	for i := range recommendations.Items {
+		rec := &recommendations.Items[i]
+		if rec.Name != quotav1alpha1.QuotaRecommendationName || sampled[rec.Namespace] {
+			continue
+		}
+		l.Info("deleting stale quota recommendation", "namespace", rec.Namespace, "profile", rec.Spec.QuotaProfile)
+		if err := r.Delete(ctx, rec); client.IgnoreNotFound(err) != nil {
+			l.Error(err, "failed to delete quota recommendation", "namespace", rec.Namespace)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recommend adds a sample of the managed ResourceQuotas of the namespace to their windows and writes the
+// recommendations into the QuotaRecommendation of the namespace.
+func (r *QuotaRecommender) recommend(ctx context.Context, q quotav1alpha1.QuotaProfile, namespace string, now time.Time) error {
+	rec := &quotav1alpha1.QuotaRecommendation{}
+	found := true
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: quotav1alpha1.QuotaRecommendationName}, rec); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		found = false
+		rec = &quotav1alpha1.QuotaRecommendation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      quotav1alpha1.QuotaRecommendationName,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	profile := fmt.Sprintf("%s/%s", q.Namespace, q.Name)
+	profileChanged := rec.Spec.QuotaProfile != profile
+	if profileChanged {
+		// the samples of another profile say nothing about the quotas of this one
+		rec.Spec.QuotaProfile = profile
+		rec.Labels = lo.Assign(rec.Labels, map[string]string{quotav1alpha1.QuotaProfileLabelKey: quota.ProfileID(q.Namespace, q.Name)})
+		rec.Status = quotav1alpha1.QuotaRecommendationStatus{}
+		lo.ForEach(q.Spec.ResourceQuotaSpecs, func(_ v1.ResourceQuotaSpec, i int) {
+			delete(r.windows, types.NamespacedName{Namespace: namespace, Name: quota.ResourceQuotaName(q.Namespace, q.Name, i)})
+		})
+	}
+
+	rqs := &v1.ResourceQuotaList{}
+	if err := r.List(ctx, rqs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: quota.ProfileID(q.Namespace, q.Name)}); err != nil {
+		return err
+	}
+	used := lo.SliceToMap(rqs.Items, func(rq v1.ResourceQuota) (string, v1.ResourceList) { return rq.Name, rq.Status.Used })
+	previous := lo.SliceToMap(rec.Status.ResourceQuotas, func(rq quotav1alpha1.ResourceQuotaRecommendation) (string, []quotav1alpha1.UsageSample) {
+		return rq.Name, rq.Samples
+	})
+
+	windowStart := now.Add(-r.Window)
+	ready := len(q.Spec.ResourceQuotaSpecs) > 0
+	var recommendations []quotav1alpha1.ResourceQuotaRecommendation
+	for i, spec := range q.Spec.ResourceQuotaSpecs {
+		name := quota.ResourceQuotaName(q.Namespace, q.Name, i)
+		key := types.NamespacedName{Namespace: namespace, Name: name}
+
+		samples, ok := r.windows[key]
+		if !ok {
+			samples = previous[name]
+		}
+		if u, ok := used[name]; ok && u != nil {
+			samples = append(samples, quotav1alpha1.UsageSample{
+				Time: metav1.NewTime(now),
+				Used: lo.PickByKeys(u, lo.Keys(spec.Hard)),
+			})
+		}
+		samples = lo.Filter(samples, func(s quotav1alpha1.UsageSample, _ int) bool { return !s.Time.Time.Before(windowStart) })
+		r.windows[key] = samples
+
+		// the window is covered once the oldest sample is at most one interval younger than the window
+		if len(samples) == 0 || samples[0].Time.Time.After(windowStart.Add(r.Interval)) {
+			ready = false
+		}
+
+		recommendations = append(recommendations, quotav1alpha1.ResourceQuotaRecommendation{
+			Name:        name,
+			Hard:        spec.Hard.DeepCopy(),
+			Recommended: recommendedHard(spec.Hard, samples, q.Spec.Recommendation),
+			Samples:     peakSamples(samples, maxUsageSamplesInStatus),
+		})
+	}
+
+	if !found {
+		if err := r.Create(ctx, rec); err != nil {
+			return err
+		}
+	} else if profileChanged {
+		if err := r.Update(ctx, rec); err != nil {
+			return err
+		}
+	}
+
+	status := quotav1alpha1.QuotaRecommendationStatus{
+		LastSampleTime: lo.ToPtr(metav1.NewTime(now)),
+		Ready:          ready,
+		ResourceQuotas: recommendations,
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(rec), rec); err != nil {
+			return err
+		}
+		rec.Status = status
+		return r.Status().Update(ctx, rec)
+	})
+}
+
+// peakSamples returns at most max samples. Longer windows are split into runs of consecutive samples, each of which
+// is replaced by its peak usage at the time of its first sample, so that the recommendation and the coverage of the
+// window stay the same.
+func peakSamples(samples []quotav1alpha1.UsageSample, max int) []quotav1alpha1.UsageSample {
+	if len(samples) <= max {
+		return samples
+	}
+	return lo.Map(lo.Chunk(samples, (len(samples)+max-1)/max), func(run []quotav1alpha1.UsageSample, _ int) quotav1alpha1.UsageSample {
+		peak := v1.ResourceList{}
+		for _, sample := range run {
+			for name, used := range sample.Used {
+				if current, ok := peak[name]; !ok || used.Cmp(current) > 0 {
+					peak[name] = used.DeepCopy()
+				}
+			}
+		}
+		return quotav1alpha1.UsageSample{Time: run[0].Time, Used: peak}
+	})
+}
+
+// recommendedHard returns the peak usage of every limited resource in the samples plus the headroom, within the
+// bounds of the policy. Resources without samples are not recommended.
+func recommendedHard(hard v1.ResourceList, samples []quotav1alpha1.UsageSample, policy *quotav1alpha1.RecommendationPolicy) v1.ResourceList {
+	recommended := v1.ResourceList{}
+	for name, limit := range hard {
+		var peak *resource.Quantity
+		for _, sample := range samples {
+			if used, ok := sample.Used[name]; ok && (peak == nil || used.Cmp(*peak) > 0) {
+				peak = &used
+			}
+		}
+		if peak == nil {
+			continue
+		}
+
+		// the headroom is rounded up to whole units, or millis for CPU and fractional quantities
+		scale := quota.QuantityScale(name, *peak)
+		amount := new(big.Int).Mul(big.NewInt(peak.ScaledValue(scale)), big.NewInt(100+int64(policy.HeadroomPercent)))
+		amount.Add(amount, big.NewInt(99))
+		amount.Quo(amount, big.NewInt(100))
+		quantity := *resource.NewScaledQuantity(amount.Int64(), scale)
+		quantity.Format = limit.Format
+
+		recommended[name] = quantity
+	}
+	return quota.BoundRecommendation(hard, recommended, policy)
+}
+
+// recommendationNamespace maps a QuotaRecommendation to the namespace it is in.
+func recommendationNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetName() != quotav1alpha1.QuotaRecommendationName {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+// recommendationChangedPredicate passes QuotaRecommendations whose readiness or recommended hard limits changed,
+// a new sample alone does not need the namespace to be reconciled.
+var recommendationChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldRec, ok := e.ObjectOld.(*quotav1alpha1.QuotaRecommendation)
+		if !ok {
+			return true
+		}
+		newRec, ok := e.ObjectNew.(*quotav1alpha1.QuotaRecommendation)
+		if !ok {
+			return true
+		}
+		recommended := func(rec *quotav1alpha1.QuotaRecommendation) []v1.ResourceList {
+			return lo.Map(rec.Status.ResourceQuotas, func(rq quotav1alpha1.ResourceQuotaRecommendation, _ int) v1.ResourceList {
+				return rq.Recommended
+			})
+		}
+		return oldRec.Status.Ready != newRec.Status.Ready ||
+			oldRec.Spec.QuotaProfile != newRec.Spec.QuotaProfile ||
+			!equality.Semantic.DeepEqual(recommended(oldRec), recommended(newRec))
+	},
+}
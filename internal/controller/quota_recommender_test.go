@@ -0,0 +1,214 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+var _ = Describe("Quota Recommender", func() {
+	const rqName = "default-web-0-rq"
+
+	var (
+		ctx          context.Context
+		fakeClient   client.Client
+		quotaProfile *quotav1alpha1.QuotaProfile
+		recommender  *QuotaRecommender
+		start        time.Time
+	)
+
+	setUsage := func(cpu, memory string) {
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: rqName}, rq)).To(Succeed())
+		rq.Status.Used = v1.ResourceList{
+			v1.ResourceRequestsCPU:    resource.MustParse(cpu),
+			v1.ResourceRequestsMemory: resource.MustParse(memory),
+		}
+		Expect(fakeClient.Update(ctx, rq)).To(Succeed())
+	}
+
+	getRecommendation := func() *quotav1alpha1.QuotaRecommendation {
+		rec := &quotav1alpha1.QuotaRecommendation{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: quotav1alpha1.QuotaRecommendationName}, rec)).To(Succeed())
+		return rec
+	}
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+		start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := setupFakeClientWithScheme()
+
+		quotaProfile = &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchName: lo.ToPtr("team-a")},
+				ResourceQuotaSpecs: []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{
+					v1.ResourceRequestsCPU:    resource.MustParse("10"),
+					v1.ResourceRequestsMemory: resource.MustParse("10Gi"),
+				}}},
+				Recommendation: &quotav1alpha1.RecommendationPolicy{HeadroomPercent: 20},
+			},
+		}
+
+		fakeClient = newFakeClientBuilder(s).WithObjects(
+			quotaProfile,
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "team-a",
+				Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.web"},
+			}},
+			&v1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{
+				Name:      rqName,
+				Namespace: "team-a",
+				Labels:    map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.web"},
+			}},
+		).Build()
+		recommender = &QuotaRecommender{Client: fakeClient, Scheme: s, Interval: time.Hour, Window: 3 * time.Hour}
+	})
+
+	It("should recommend the peak usage in the window plus the headroom", func() {
+		setUsage("2", "1Gi")
+		Expect(recommender.Sample(ctx, start)).To(Succeed())
+		setUsage("4", "2Gi")
+		Expect(recommender.Sample(ctx, start.Add(time.Hour))).To(Succeed())
+		setUsage("1", "1Gi")
+		Expect(recommender.Sample(ctx, start.Add(2*time.Hour))).To(Succeed())
+
+		rec := getRecommendation()
+		Expect(rec.Spec.QuotaProfile).To(Equal("default/web"))
+		Expect(rec.Status.ResourceQuotas).To(HaveLen(1))
+		Expect(rec.Status.ResourceQuotas[0].Name).To(Equal(rqName))
+		Expect(rec.Status.ResourceQuotas[0].Samples).To(HaveLen(3))
+		Expect(rec.Status.ResourceQuotas[0].Recommended).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("4800m")))
+		Expect(rec.Status.ResourceQuotas[0].Recommended).To(HaveKeyWithValue(v1.ResourceRequestsMemory, resource.MustParse("2576980378")))
+		Expect(rec.Status.Ready).To(BeTrue())
+	})
+
+	It("should drop samples older than the window", func() {
+		setUsage("8", "1Gi")
+		Expect(recommender.Sample(ctx, start)).To(Succeed())
+		Expect(getRecommendation().Status.Ready).To(BeFalse())
+
+		setUsage("1", "1Gi")
+		Expect(recommender.Sample(ctx, start.Add(4*time.Hour))).To(Succeed())
+
+		rec := getRecommendation()
+		Expect(rec.Status.ResourceQuotas[0].Samples).To(HaveLen(1))
+		Expect(rec.Status.ResourceQuotas[0].Recommended).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("1200m")))
+		Expect(rec.Status.Ready).To(BeFalse())
+	})
+
+	It("should keep the recommendations within the bounds of the profile", func() {
+		quotaProfile.Spec.Recommendation.Min = v1.ResourceList{v1.ResourceRequestsMemory: resource.MustParse("4Gi")}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		setUsage("9", "1Gi")
+		Expect(recommender.Sample(ctx, start)).To(Succeed())
+
+		recommended := getRecommendation().Status.ResourceQuotas[0].Recommended
+		Expect(recommended).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("10")))
+		Expect(recommended).To(HaveKeyWithValue(v1.ResourceRequestsMemory, resource.MustParse("4Gi")))
+
+		quotaProfile.Spec.Recommendation.Max = v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("20")}
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		Expect(recommender.Sample(ctx, start.Add(time.Hour))).To(Succeed())
+
+		recommended = getRecommendation().Status.ResourceQuotas[0].Recommended
+		Expect(recommended).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("10800m")))
+	})
+
+	It("should restore the window from the quota recommendation after a restart", func() {
+		setUsage("6", "1Gi")
+		Expect(recommender.Sample(ctx, start)).To(Succeed())
+
+		recommender = &QuotaRecommender{Client: fakeClient, Scheme: recommender.Scheme, Interval: time.Hour, Window: 3 * time.Hour}
+		setUsage("1", "1Gi")
+		Expect(recommender.Sample(ctx, start.Add(time.Hour))).To(Succeed())
+
+		rec := getRecommendation()
+		Expect(rec.Status.ResourceQuotas[0].Samples).To(HaveLen(2))
+		Expect(rec.Status.ResourceQuotas[0].Recommended).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("7200m")))
+	})
+
+	It("should store long windows as the peaks of consecutive samples", func() {
+		recommender.Interval = time.Minute
+		recommender.Window = 5 * time.Hour
+		for i := range 250 {
+			setUsage(lo.Ternary(i == 123, "7", "1"), "1Gi")
+			Expect(recommender.Sample(ctx, start.Add(time.Duration(i)*time.Minute))).To(Succeed())
+		}
+
+		rec := getRecommendation()
+		samples := rec.Status.ResourceQuotas[0].Samples
+		Expect(samples).To(HaveLen(84))
+		Expect(samples[0].Time.Time).To(BeTemporally("==", start))
+		Expect(samples[41].Used).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("7")))
+		Expect(rec.Status.ResourceQuotas[0].Recommended).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("8400m")))
+	})
+
+	It("should delete the quota recommendation when recommendations are disabled", func() {
+		setUsage("2", "1Gi")
+		Expect(recommender.Sample(ctx, start)).To(Succeed())
+		getRecommendation()
+
+		quotaProfile.Spec.Recommendation = nil
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		Expect(recommender.Sample(ctx, start.Add(time.Hour))).To(Succeed())
+
+		err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: quotav1alpha1.QuotaRecommendationName}, &quotav1alpha1.QuotaRecommendation{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should apply ready recommendations to the managed resource quotas", func() {
+		quotaProfile.Spec.Recommendation.AutoApply = true
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+		reconciler := &NamespaceReconciler{Client: fakeClient, Scheme: recommender.Scheme}
+		reconcileNamespace := func() *v1.ResourceQuota {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			rq := &v1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: rqName}, rq)).To(Succeed())
+			return rq
+		}
+
+		setUsage("2", "1Gi")
+		Expect(recommender.Sample(ctx, start)).To(Succeed())
+		Expect(reconcileNamespace().Spec.Hard).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("10")))
+
+		Expect(recommender.Sample(ctx, start.Add(2*time.Hour))).To(Succeed())
+		Expect(getRecommendation().Status.Ready).To(BeTrue())
+		hard := reconcileNamespace().Spec.Hard
+		Expect(hard).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("2400m")))
+		Expect(hard).To(HaveKeyWithValue(v1.ResourceRequestsMemory, resource.MustParse("1288490189")))
+	})
+})
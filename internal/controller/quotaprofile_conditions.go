@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// maxConditionMessageLength keeps the conditions readable when many namespaces fail with the same error.
+const maxConditionMessageLength = 1024
+
+// setConditions sets the Ready, Reconciling and Stalled conditions of the status for the generation of the
+// profile, following the kstatus conventions: the profile is ready once every selected namespace is bound and
+// rolled out, reconciling while namespaces are still being bound or rolled out, and stalled when the rollout
+// waits for a change to the profile. Reconciling and Stalled are removed while they are false.
+func setConditions(status *quotav1alpha1.QuotaProfileStatus, quotaProfile *quotav1alpha1.QuotaProfile, fanOut namespaceFanOut, reconcileErr error) {
+	rollout := status.Rollout
+	if quotaProfile.Spec.Rollout == nil || rollout == nil || rollout.ObservedGeneration != quotaProfile.Generation {
+		// the namespaces of a profile without a rollout strategy receive every generation right away
+		rollout = nil
+	}
+
+	reason, message, stalled := quotav1alpha1.QuotaProfileReconciledReason, fmt.Sprintf("bound namespaces: %d", status.BoundNamespaces), false
+	switch {
+	case reconcileErr != nil && fanOut.failed > 0:
+		reason = quotav1alpha1.QuotaProfileBindFailedReason
+		message = fmt.Sprintf("%d of %d selected namespaces could not be bound: %v", fanOut.failed, fanOut.selected, reconcileErr)
+	case reconcileErr != nil:
+		reason = quotav1alpha1.QuotaProfileReconcileFailedReason
+		message = reconcileErr.Error()
+	case rollout != nil && rollout.Paused:
+		reason, message, stalled = quotav1alpha1.QuotaProfileRolloutPausedReason, rollout.Message, true
+	case rollout != nil && rollout.UpdatedNamespaces < rollout.TotalNamespaces:
+		reason, message = quotav1alpha1.QuotaProfileRollingOutReason, rollout.Message
+	}
+
+	if len(message) > maxConditionMessageLength {
+		message = message[:maxConditionMessageLength-3] + "..."
+	}
+
+	if reason == quotav1alpha1.QuotaProfileReconciledReason {
+		setCondition(status, quotaProfile.Generation, quotav1alpha1.QuotaProfileReady, metav1.ConditionTrue, reason, message)
+		meta.RemoveStatusCondition(&status.Conditions, quotav1alpha1.QuotaProfileReconciling)
+		meta.RemoveStatusCondition(&status.Conditions, quotav1alpha1.QuotaProfileStalled)
+		return
+	}
+
+	setCondition(status, quotaProfile.Generation, quotav1alpha1.QuotaProfileReady, metav1.ConditionFalse, reason, message)
+	if stalled {
+		setCondition(status, quotaProfile.Generation, quotav1alpha1.QuotaProfileStalled, metav1.ConditionTrue, reason, message)
+		meta.RemoveStatusCondition(&status.Conditions, quotav1alpha1.QuotaProfileReconciling)
+	} else {
+		// failed reconciliations are retried, so the profile is still progressing rather than stalled
+		setCondition(status, quotaProfile.Generation, quotav1alpha1.QuotaProfileReconciling, metav1.ConditionTrue, reason, message)
+		meta.RemoveStatusCondition(&status.Conditions, quotav1alpha1.QuotaProfileStalled)
+	}
+}
+
+// setCondition sets a condition of the status, the transition time only changes with the condition status.
+func setCondition(status *quotav1alpha1.QuotaProfileStatus, generation int64, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
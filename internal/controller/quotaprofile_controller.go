@@ -18,27 +18,48 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"sort"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
 	"github.com/samber/lo"
 )
 
+// maxNamespaceConflicts is the number of conflicts listed in the status of a QuotaProfile.
+const maxNamespaceConflicts = 20
+
 // QuotaProfileReconciler reconciles a QuotaProfile object
 type QuotaProfileReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// HubEnabled tells whether the operator runs as the hub of a fleet. Without the HubReconciler, the hub finalizer
+	// that an earlier run as hub left on a deleted profile is removed here, so that it does not block the deletion.
+	HubEnabled bool
+
+	// APIReader lists the Events of the namespaces a rollout updated, which are not cached as they are by far the
+	// most frequently changing objects of a cluster. The Client is used when not set.
+	APIReader client.Reader
 }
 
 // +kubebuilder:rbac:groups=quota.dev.operator,resources=quotaprofiles,verbs=get;list;watch;create;update;patch;delete
@@ -88,152 +109,242 @@ func (r *QuotaProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	l.Info("reconciling namespaces", "quotaProfile", req.NamespacedName)
-	if err := r.reconcileNamespace(ctx, req); err != nil {
+	fanOut, err := r.reconcileNamespace(ctx, req)
+	if err != nil {
 		l.Error(err, "failed to reconcile namespaces", "quotaProfile", req.NamespacedName)
+		return ctrl.Result{}, r.failReconcile(ctx, quotaProfile, fanOut, err)
+	}
+
+	requeueAfter := fanOut.requeueAfter
+	if quotaProfile.Spec.Rollout != nil {
+		l.Info("reconciling rollout", "quotaProfile", req.NamespacedName)
+		rolloutRequeueAfter, err := r.reconcileRollout(ctx, quotaProfile)
+		if err != nil {
+			l.Error(err, "failed to reconcile rollout", "quotaProfile", req.NamespacedName)
+			return ctrl.Result{}, r.failReconcile(ctx, quotaProfile, fanOut, err)
+		}
+		requeueAfter = minRequeue(requeueAfter, rolloutRequeueAfter)
+	} else if err := r.clearRollout(ctx, quotaProfile); err != nil {
+		l.Error(err, "failed to clear rollout", "quotaProfile", req.NamespacedName)
+		return ctrl.Result{}, r.failReconcile(ctx, quotaProfile, fanOut, err)
+	}
+
+	if err := r.updateStatus(ctx, quotaProfile, fanOut, nil); err != nil {
+		l.Error(err, "failed to update status", "quotaProfile", req.NamespacedName)
 		return ctrl.Result{}, err
 	}
 
-	l.Info("successfully reconciled quota profile", "quotaProfile", req.NamespacedName)
-	return ctrl.Result{}, nil
+	l.Info("successfully reconciled quota profile", "quotaProfile", req.NamespacedName, "requeueAfter", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func (r *QuotaProfileReconciler) reconcileNamespace(ctx context.Context, req ctrl.Request) error {
-	l := log.FromContext(ctx)
+// failReconcile records the error in the status of the profile and returns it, so that the reconciliation is
+// retried while the conditions show that the namespaces are not reconciled yet.
+func (r *QuotaProfileReconciler) failReconcile(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, fanOut namespaceFanOut, err error) error {
+	if statusErr := r.updateStatus(ctx, quotaProfile, fanOut, err); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "failed to update status", "quotaProfile", client.ObjectKeyFromObject(quotaProfile))
+	}
+	return err
+}
 
-	nsList := &v1.NamespaceList{}
-	if err := r.List(ctx, nsList); err != nil {
-		l.Error(err, "failed to list namespaces")
+// updateStatus records the generation the namespaces were reconciled for, the number of bound namespaces, the
+// namespaces other profiles select as well and the conditions for the outcome of the reconciliation.
+func (r *QuotaProfileReconciler) updateStatus(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, fanOut namespaceFanOut, reconcileErr error) error {
+	namespaces, err := index.NamespacesWithLabel(ctx, r.Client, quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name))
+	if err != nil {
 		return err
 	}
 
+	status := quotaProfile.Status.DeepCopy()
+	status.ObservedGeneration = quotaProfile.Generation
+	status.BoundNamespaces = int32(len(namespaces))
+	if reconcileErr == nil {
+		// the conflicts of a failed reconciliation are incomplete, the ones of the last successful one are kept
+		status.Conflicts = fanOut.conflicts
+	}
+	setConditions(status, quotaProfile, fanOut, reconcileErr)
+	if equality.Semantic.DeepEqual(&quotaProfile.Status, status) {
+		return nil
+	}
+	quotaProfile.Status = *status
+	return r.Status().Update(ctx, quotaProfile)
+}
+
+// namespaceFanOut is the outcome of binding the namespaces a profile selects.
+type namespaceFanOut struct {
+	// conflicts are the selected namespaces that other profiles select as well.
+	conflicts []quotav1alpha1.NamespaceConflict
+	// requeueAfter is when namespaces that are selected except for their age reach it.
+	requeueAfter time.Duration
+	// selected is the number of namespaces the profile selects, failed the number of them that could not be bound.
+	selected, failed int
+}
+
+// reconcileNamespace binds the namespaces the profile selects. It returns the namespaces that other profiles
+// select as well, and when to reconcile again for namespaces that are selected except for their age.
+func (r *QuotaProfileReconciler) reconcileNamespace(ctx context.Context, req ctrl.Request) (namespaceFanOut, error) {
+	l := log.FromContext(ctx)
+
+	var fanOut namespaceFanOut
 	quotaProfile := &quotav1alpha1.QuotaProfile{}
 	if err := r.Get(ctx, req.NamespacedName, quotaProfile); err != nil {
 		l.Error(err, "failed to get quota profile", "quotaProfile", req.NamespacedName)
-		return err
+		return fanOut, err
 	}
 
-	if quotaProfile.Spec.NamespaceSelector.MatchName != nil {
-		for _, ns := range nsList.Items {
-			if ns.Name == *quotaProfile.Spec.NamespaceSelector.MatchName {
-				l.Info("found matching namespace with name selector", "namespace", ns.Name)
-				setQuotaProfileLabels(&ns, quotaProfile)
-				if err := r.Update(ctx, &ns); err != nil {
-					l.Error(err, "failed to set quota profile labels", "namespace", ns.Name)
-					return err
-				}
-			}
-		}
-	} else if quotaProfile.Spec.NamespaceSelector.MatchLabels != nil {
-		for _, ns := range nsList.Items {
-			if ns.Labels == nil {
-				continue
-			}
-
-			keys := lo.Keys(quotaProfile.Spec.NamespaceSelector.MatchLabels)
-
-			// validating webhook ensures that only one key is present
-			key := keys[0]
-			value := quotaProfile.Spec.NamespaceSelector.MatchLabels[key]
+	selector := quotaProfile.Spec.NamespaceSelector
+	namespaces, err := r.candidateNamespaces(ctx, selector)
+	if err != nil {
+		l.Error(err, "failed to list namespaces", "selector", index.SelectorString(selector))
+		return fanOut, err
+	}
 
-			if ns.Labels[key] == value {
-				l.Info("found matching namespace with label selector", "namespace", ns.Name)
-				if err := r.addLabelToNamespace(ctx, quotaProfile, &ns); err != nil {
-					l.Error(err, "failed to add label to namespace", "namespace", ns.Name)
-					return err
-				}
+	// a failing namespace must not keep the profile from the others, the errors are returned once all were tried
+	var errs []error
+	now := time.Now()
+	for _, ns := range namespaces {
+		if reason := index.SelectorMismatch(selector, &ns, now); reason != "" {
+			// namespaces that only lack the age are bound once they reach it
+			if wait := index.UntilMinAge(selector, &ns, now); wait > 0 && index.SelectorMismatch(selector, &ns, now.Add(wait)) == "" {
+				fanOut.requeueAfter = minRequeue(fanOut.requeueAfter, wait)
 			}
+			continue
+		}
+		l.Info("found matching namespace with selector", "namespace", ns.Name)
+		fanOut.selected++
+		decision, err := r.bindNamespace(ctx, &ns)
+		if err != nil {
+			l.Error(err, "failed to bind namespace", "namespace", ns.Name)
+			errs = append(errs, err)
+			fanOut.failed++
+			continue
+		}
+		if len(decision.Trace) > 0 {
+			fanOut.conflicts = append(fanOut.conflicts, quotav1alpha1.NamespaceConflict{
+				Namespace: ns.Name,
+				BoundTo:   index.ProfileRef(decision.Winner),
+				Trace:     decision.Trace,
+			})
 		}
 	}
-	return nil
+	sort.Slice(fanOut.conflicts, func(i, j int) bool { return fanOut.conflicts[i].Namespace < fanOut.conflicts[j].Namespace })
+	if len(fanOut.conflicts) > maxNamespaceConflicts {
+		fanOut.conflicts = fanOut.conflicts[:maxNamespaceConflicts]
+	}
+	return fanOut, errors.Join(errs...)
 }
 
-// addLabelToNamespace adds the quota profile label to the namespace if it doesn't exist.
-// If a label already exists, it resolves any conflicts based on precedence.
-// When precedences are equal, the new quota profile takes precedence.
-// The quota profile label key is defined in the API package.
-// Returns an error if updating the namespace fails.
-func (r *QuotaProfileReconciler) addLabelToNamespace(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, ns *v1.Namespace) error {
-	l := log.FromContext(ctx)
+// candidateNamespaces returns the namespaces that may match the selector, i.e. the namespace with its name, the
+// namespaces with one of its labels if it has any, otherwise all namespaces. Callers still have to check the selector.
+func (r *QuotaProfileReconciler) candidateNamespaces(ctx context.Context, selector quotav1alpha1.NamespaceSelector) ([]v1.Namespace, error) {
+	if selector.MatchName != nil {
+		ns := &v1.Namespace{}
+		if err := r.Get(ctx, types.NamespacedName{Name: *selector.MatchName}, ns); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return []v1.Namespace{*ns}, nil
+	}
 
-	if ns.Labels == nil {
-		ns.Labels = make(map[string]string)
+	if len(selector.MatchLabels) > 0 {
+		// every selected namespace has all of the labels, so any one of them narrows down the candidates
+		key := lo.Min(lo.Keys(selector.MatchLabels))
+		return index.NamespacesWithLabel(ctx, r.Client, key, selector.MatchLabels[key])
 	}
 
-	if ns.Labels[quotav1alpha1.QuotaProfileLabelKey] == "" {
-		l.Info("adding quota profile label to namespace", "namespace", ns.Name, "quotaProfile", quotaProfile.Name)
-		setQuotaProfileLabels(ns, quotaProfile)
-		return r.Update(ctx, ns)
+	nsList := &v1.NamespaceList{}
+	if err := r.List(ctx, nsList); err != nil {
+		return nil, err
 	}
+	return nsList.Items, nil
+}
 
-	return r.resolveConflict(ctx, quotaProfile, ns)
+// minRequeue returns the shorter of two requeue durations, where zero means no requeue.
+func minRequeue(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
 }
 
-// resolveConflict resolves conflicts between quota profiles when a namespace already has a quota profile label.
-// It compares the precedence of the existing profile with the new one.
-// If the existing profile has higher precedence, no change is made.
-// Otherwise, the namespace is updated with the new quota profile label.
-// Returns an error if getting the existing profile or updating the namespace fails.
-func (r *QuotaProfileReconciler) resolveConflict(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile, ns *v1.Namespace) error {
+// bindNamespace binds the namespace to the profile that wins out of all profiles selecting it, see index.Decide.
+// The namespace defaulting webhook ranks the profiles the same way, so both always agree on the winner no matter
+// which profile is reconciled first.
+func (r *QuotaProfileReconciler) bindNamespace(ctx context.Context, ns *v1.Namespace) (index.Decision, error) {
 	l := log.FromContext(ctx)
 
-	existingProfileID := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]
-	existingProfileNamespace, existingProfileName := splitProfileID(existingProfileID)
-
-	if existingProfileNamespace == quotaProfile.Namespace && existingProfileName == quotaProfile.Name {
-		l.Info("namespace already has this quota profile", "namespace", ns.Name, "quotaProfile", quotaProfile.Name)
-		setQuotaProfileLabels(ns, quotaProfile)
-		return r.Update(ctx, ns)
+	candidates, err := index.QuotaProfilesForNamespace(ctx, r.Client, ns)
+	if err != nil {
+		return index.Decision{}, err
 	}
-
-	existingProfile := &quotav1alpha1.QuotaProfile{}
-	if err := r.Get(ctx, types.NamespacedName{Name: existingProfileName, Namespace: existingProfileNamespace}, existingProfile); client.IgnoreNotFound(err) != nil {
-		l.Error(err, "failed to get existing quota profile", "namespace", existingProfileNamespace, "name", existingProfileName)
-		return err
+	decision := index.Decide(candidates, ns, time.Now())
+	if decision.Winner == nil {
+		return decision, nil
 	}
 
-	if (existingProfile == &quotav1alpha1.QuotaProfile{}) {
-		l.Info("existing profile not found, adding new profile", "namespace", ns.Name, "quotaProfile", quotaProfile.Name)
-		setQuotaProfileLabels(ns, quotaProfile)
-		return r.Update(ctx, ns)
-	}
-
-	if existingProfile.Spec.NamespaceSelector.MatchName != nil {
-		if ns.Name == *existingProfile.Spec.NamespaceSelector.MatchName {
-			l.Info("namespace matches name selector", "namespace", ns.Name, "existingProfile", existingProfile.Name)
-			setQuotaProfileLabels(ns, existingProfile)
-			return r.Update(ctx, ns)
+	err = r.patchNamespaceLabels(ctx, ns, func(ns *v1.Namespace) error {
+		if profileID := quota.ProfileID(decision.Winner.Namespace, decision.Winner.Name); ns.Labels[quotav1alpha1.QuotaProfileLabelKey] != profileID {
+			l.Info("updating quota profile label", "namespace", ns.Name, "oldProfile", ns.Labels[quotav1alpha1.QuotaProfileLabelKey],
+				"newProfile", profileID, "trace", decision.Trace)
 		}
-	}
-
-	if existingProfile.Spec.Precedence > quotaProfile.Spec.Precedence || existingProfile.CreationTimestamp.After(quotaProfile.CreationTimestamp.Time) {
-		l.Info("keeping existing profile due to higher precedence", "namespace", ns.Name, "existingProfile", existingProfile.Name)
-		setQuotaProfileLabels(ns, existingProfile)
-		return r.Update(ctx, ns)
-	}
+		setQuotaProfileLabels(ns, decision.Winner)
+		return nil
+	})
+	return decision, err
+}
 
-	l.Info("updating quota profile label", "namespace", ns.Name, "oldProfile", existingProfile.Name, "newProfile", quotaProfile.Name)
-	setQuotaProfileLabels(ns, quotaProfile)
+// patchNamespaceLabels applies mutate to the namespace and sends only the changed labels as a merge patch,
+// so that the labels of other tools are never clobbered. The patch is rejected with a conflict when the
+// namespace changed since it was read, in which case mutate is retried on the latest version of the namespace.
+func (r *QuotaProfileReconciler) patchNamespaceLabels(ctx context.Context, ns *v1.Namespace, mutate func(ns *v1.Namespace) error) error {
+	l := log.FromContext(ctx)
 
-	return r.Update(ctx, ns)
-}
+	attempt := 0
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		attempt++
+		if attempt > 1 {
+			l.Info("retrying namespace label patch after conflict", "namespace", ns.Name, "attempt", attempt)
+			if err := r.Get(ctx, client.ObjectKeyFromObject(ns), ns); err != nil {
+				return err
+			}
+		}
 
-func splitProfileID(profileID string) (string, string) {
-	parts := strings.Split(profileID, ".")
-	if len(parts) != 2 {
-		return "", ""
-	}
-	return parts[0], parts[1]
+		base := ns.DeepCopy()
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		if err := mutate(ns); err != nil {
+			return err
+		}
+		return r.Patch(ctx, ns, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
 }
 
 func setQuotaProfileLabels(ns *v1.Namespace, quotaProfile *quotav1alpha1.QuotaProfile) {
-	ns.Labels[quotav1alpha1.QuotaProfileLabelKey] = quotaProfile.Namespace + "." + quotaProfile.Name
+	ns.Labels[quotav1alpha1.QuotaProfileLabelKey] = quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name)
 	ns.Labels[quotav1alpha1.QuotaProfileLastUpdateTimestamp] = fmt.Sprintf("%d", time.Now().UnixMicro())
+	delete(ns.Labels, quotav1alpha1.QuotaProfileRetainedLabelKey)
+}
+
+func removeQuotaProfileLabels(ns *v1.Namespace) {
+	delete(ns.Labels, quotav1alpha1.QuotaProfileLabelKey)
+	delete(ns.Labels, quotav1alpha1.QuotaProfileLastUpdateTimestamp)
+	delete(ns.Labels, quotav1alpha1.QuotaProfileRetainedLabelKey)
+	delete(ns.Labels, quotav1alpha1.QuotaProfileGenerationLabelKey)
 }
 
 // handleDeletion handles the cleanup when a QuotaProfile is being deleted
 func (r *QuotaProfileReconciler) handleDeletion(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
 
+	if !r.HubEnabled && controllerutil.ContainsFinalizer(quotaProfile, quotav1alpha1.QuotaProfileHubFinalizer) {
+		l.Info("not running as hub, removing hub finalizer without deleting the copies from the member clusters", "quotaProfile", quotaProfile.Name)
+		controllerutil.RemoveFinalizer(quotaProfile, quotav1alpha1.QuotaProfileHubFinalizer)
+		if err := r.Update(ctx, quotaProfile); err != nil {
+			l.Error(err, "failed to remove hub finalizer", "quotaProfile", quotaProfile.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Check if finalizer exists
 	if !controllerutil.ContainsFinalizer(quotaProfile, quotav1alpha1.QuotaProfileFinalizer) {
 		l.Info("finalizer not found, skipping cleanup", "quotaProfile", quotaProfile.Name)
@@ -241,34 +352,52 @@ func (r *QuotaProfileReconciler) handleDeletion(ctx context.Context, quotaProfil
 	}
 
 	// Cleanup logic: Remove quota profile label from all namespaces that were using this profile
-	nsList := &v1.NamespaceList{}
-	if err := r.List(ctx, nsList); err != nil {
+	profileID := quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name)
+	namespaces, err := index.NamespacesWithLabel(ctx, r.Client, quotav1alpha1.QuotaProfileLabelKey, profileID)
+	if err != nil {
 		l.Error(err, "failed to list namespaces during cleanup", "quotaProfile", quotaProfile.Name)
 		return ctrl.Result{}, err
 	}
 
-	for _, ns := range nsList.Items {
-		if ns.Labels == nil {
-			continue
+	var errs []error
+	for _, ns := range namespaces {
+		if quotaProfile.Spec.DeletionPolicy == quotav1alpha1.DeletionPolicyOrphan {
+			// Strip the profile label from the managed resources first, otherwise the namespace
+			// controller deletes them as soon as the namespace label is gone.
+			if err := r.orphanManagedResources(ctx, ns.Name, profileID); err != nil {
+				l.Error(err, "failed to orphan managed resources", "namespace", ns.Name)
+				errs = append(errs, err)
+				continue
+			}
 		}
 
-		// Check if namespace has this quota profile
-		if profileID, ok := ns.Labels[quotav1alpha1.QuotaProfileLabelKey]; ok {
-			// Extract namespace and name from profile ID
-			profileNs, profileName := splitProfileID(profileID)
-			if profileNs == quotaProfile.Namespace && profileName == quotaProfile.Name {
+		if err := r.patchNamespaceLabels(ctx, &ns, func(ns *v1.Namespace) error {
+			if ns.Labels[quotav1alpha1.QuotaProfileLabelKey] != profileID {
+				l.Info("namespace is no longer bound to quota profile", "namespace", ns.Name, "quotaProfile", quotaProfile.Name)
+				return nil
+			}
+
+			switch quotaProfile.Spec.DeletionPolicy {
+			case quotav1alpha1.DeletionPolicyRetain:
+				// Keep the profile label so the namespace controller leaves the managed resources alone
+				// until a replacement profile binds to the namespace.
+				l.Info("retaining managed resources in namespace", "namespace", ns.Name, "quotaProfile", quotaProfile.Name)
+				ns.Labels[quotav1alpha1.QuotaProfileRetainedLabelKey] = "true"
+			default:
 				l.Info("removing quota profile label from namespace", "namespace", ns.Name, "quotaProfile", quotaProfile.Name)
-				// Remove the quota profile label
-				delete(ns.Labels, quotav1alpha1.QuotaProfileLabelKey)
-				delete(ns.Labels, quotav1alpha1.QuotaProfileLastUpdateTimestamp)
-				if err := r.Update(ctx, &ns); err != nil {
-					l.Error(err, "failed to remove quota profile label from namespace", "namespace", ns.Name)
-					return ctrl.Result{}, err
-				}
+				removeQuotaProfileLabels(ns)
 			}
+			return nil
+		}); err != nil {
+			l.Error(err, "failed to remove quota profile label from namespace", "namespace", ns.Name)
+			errs = append(errs, err)
 		}
 	}
 
+	if err := errors.Join(errs...); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Remove finalizer
 	l.Info("removing finalizer", "quotaProfile", quotaProfile.Name)
 	controllerutil.RemoveFinalizer(quotaProfile, quotav1alpha1.QuotaProfileFinalizer)
@@ -281,10 +410,126 @@ func (r *QuotaProfileReconciler) handleDeletion(ctx context.Context, quotaProfil
 	return ctrl.Result{}, nil
 }
 
+// orphanManagedResources removes the quota profile label from the ResourceQuotas, LimitRanges and templated
+// objects that the given profile manages in the namespace, turning them into unmanaged objects.
+func (r *QuotaProfileReconciler) orphanManagedResources(ctx context.Context, namespace, profileID string) error {
+	l := log.FromContext(ctx)
+
+	rqs := &v1.ResourceQuotaList{}
+	if err := r.List(ctx, rqs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}); err != nil {
+		l.Error(err, "failed to list resource quotas", "namespace", namespace)
+		return err
+	}
+
+	for _, rq := range rqs.Items {
+		l.Info("orphaning resource quota", "namespace", namespace, "name", rq.Name)
+		delete(rq.Labels, quotav1alpha1.QuotaProfileLabelKey)
+		if err := r.Update(ctx, &rq); err != nil {
+			l.Error(err, "failed to orphan resource quota", "namespace", namespace, "name", rq.Name)
+			return err
+		}
+	}
+
+	lrs := &v1.LimitRangeList{}
+	if err := r.List(ctx, lrs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}); err != nil {
+		l.Error(err, "failed to list limit ranges", "namespace", namespace)
+		return err
+	}
+
+	for _, lr := range lrs.Items {
+		l.Info("orphaning limit range", "namespace", namespace, "name", lr.Name)
+		delete(lr.Labels, quotav1alpha1.QuotaProfileLabelKey)
+		if err := r.Update(ctx, &lr); err != nil {
+			l.Error(err, "failed to orphan limit range", "namespace", namespace, "name", lr.Name)
+			return err
+		}
+	}
+
+	for _, kind := range objectTemplateKinds() {
+		objs := kind.newList()
+		if err := r.List(ctx, objs, client.InNamespace(namespace), client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: profileID}); err != nil {
+			l.Error(err, "failed to list objects", "kind", kind.name, "namespace", namespace)
+			return err
+		}
+
+		for _, obj := range objs.Items {
+			l.Info("orphaning object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+			labels := obj.GetLabels()
+			delete(labels, quotav1alpha1.QuotaProfileLabelKey)
+			obj.SetLabels(labels)
+			if err := r.Update(ctx, &obj); err != nil {
+				l.Error(err, "failed to orphan object", "kind", kind.name, "namespace", namespace, "name", obj.GetName())
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// quotaProfilesForNamespace maps a namespace to the quota profiles that may select it, so that namespaces
+// the mutating webhook admitted without labels, e.g. while failing open, are labelled asynchronously.
+func (r *QuotaProfileReconciler) quotaProfilesForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	l := log.FromContext(ctx)
+
+	ns, ok := obj.(*v1.Namespace)
+	if !ok {
+		return nil
+	}
+
+	quotaProfiles, err := index.QuotaProfilesForNamespace(ctx, r.Client, ns)
+	if err != nil {
+		l.Error(err, "failed to look up quota profiles for namespace", "namespace", ns.Name)
+		return nil
+	}
+
+	return lo.Map(quotaProfiles, func(q quotav1alpha1.QuotaProfile, _ int) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: q.Namespace, Name: q.Name}}
+	})
+}
+
+// quotaProfileForBoundNamespace maps a namespace to the quota profile it is bound to, so that the number of
+// bound namespaces in the status follows namespaces changing their profile or being deleted.
+func (r *QuotaProfileReconciler) quotaProfileForBoundNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	profileNamespace, profileName := quota.SplitProfileID(obj.GetLabels()[quotav1alpha1.QuotaProfileLabelKey])
+	if profileName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: profileNamespace, Name: profileName}}}
+}
+
+// quotaProfileChangedPredicate passes QuotaProfiles whose spec, labels or annotations changed, or that are being
+// deleted. Status updates, e.g. the drift recorded by every resync, do not need the profile to be reconciled.
+var quotaProfileChangedPredicate = predicate.Or(
+	predicate.GenerationChangedPredicate{},
+	predicate.LabelChangedPredicate{},
+	predicate.AnnotationChangedPredicate{},
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !e.ObjectNew.GetDeletionTimestamp().IsZero() ||
+				!equality.Semantic.DeepEqual(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers())
+		},
+	},
+)
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *QuotaProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&quotav1alpha1.QuotaProfile{}).
+		For(&quotav1alpha1.QuotaProfile{}, builder.WithPredicates(quotaProfileChangedPredicate)).
+		Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.quotaProfilesForNamespace),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				// labelled namespaces are kept up to date by the namespace controller
+				_, labelled := obj.GetLabels()[quotav1alpha1.QuotaProfileLabelKey]
+				return !labelled
+			}))).
+		Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.quotaProfileForBoundNamespace),
+			builder.WithPredicates(predicate.Funcs{
+				// both the old and the new profile are enqueued when the binding of a namespace changes
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return e.ObjectOld.GetLabels()[quotav1alpha1.QuotaProfileLabelKey] != e.ObjectNew.GetLabels()[quotav1alpha1.QuotaProfileLabelKey]
+				},
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			})).
 		Named("quotaprofile").
 		Complete(r)
 }
@@ -18,14 +18,21 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -87,8 +94,7 @@ var _ = Describe("QuotaProfile Controller", func() {
 				},
 			}
 
-			fakeClient = fake.NewClientBuilder().
-				WithScheme(s).
+			fakeClient = newFakeClientBuilder(s).
 				WithObjects(quotaProfile, testNs1, testNs2).
 				Build()
 
@@ -132,5 +138,667 @@ var _ = Describe("QuotaProfile Controller", func() {
 			Expect(updatedNs.Labels).ToNot(HaveKey(profileLabelKey))
 
 		})
+
+		It("should map unlabelled namespaces to the quota profiles that may select them", func() {
+			withLabel := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-namespace-with-label"}, withLabel)).To(Succeed())
+			Expect(reconciler.quotaProfilesForNamespace(ctx, withLabel)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"},
+			}))
+
+			withoutLabel := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-namespace-without-label"}, withoutLabel)).To(Succeed())
+			Expect(reconciler.quotaProfilesForNamespace(ctx, withoutLabel)).To(BeEmpty())
+		})
+
+		It("should record the observed generation and the bound namespaces in the status", func() {
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.ObservedGeneration).To(Equal(updated.Generation))
+			Expect(updated.Status.BoundNamespaces).To(Equal(int32(1)))
+
+			ready := meta.FindStatusCondition(updated.Status.Conditions, quotav1alpha1.QuotaProfileReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+			Expect(ready.ObservedGeneration).To(Equal(updated.Generation))
+			Expect(ready.Message).To(Equal("bound namespaces: 1"))
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, quotav1alpha1.QuotaProfileReconciling)).To(BeNil())
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, quotav1alpha1.QuotaProfileStalled)).To(BeNil())
+
+			By("Rebinding the namespace to another profile")
+			ns := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-namespace-with-label"}, ns)).To(Succeed())
+			ns.Labels[quotav1alpha1.QuotaProfileLabelKey] = "default.other"
+			Expect(fakeClient.Update(ctx, ns)).To(Succeed())
+			Expect(reconciler.quotaProfileForBoundNamespace(ctx, ns)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "other", Namespace: "default"},
+			}))
+
+			Expect(reconciler.updateStatus(ctx, updated, namespaceFanOut{}, nil)).To(Succeed())
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.BoundNamespaces).To(BeZero())
+		})
+
+		It("should only reconcile on changes of the spec, metadata or deletion", func() {
+			oldProfile := &quotav1alpha1.QuotaProfile{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default", Generation: 1}}
+			changed := func(mutate func(*quotav1alpha1.QuotaProfile)) bool {
+				newProfile := oldProfile.DeepCopy()
+				mutate(newProfile)
+				return quotaProfileChangedPredicate.Update(event.UpdateEvent{ObjectOld: oldProfile, ObjectNew: newProfile})
+			}
+
+			Expect(changed(func(q *quotav1alpha1.QuotaProfile) {
+				q.Status.Drift = &quotav1alpha1.DriftStatus{LastCheckTime: metav1.Now()}
+			})).To(BeFalse())
+			Expect(changed(func(q *quotav1alpha1.QuotaProfile) { q.Generation = 2 })).To(BeTrue())
+			Expect(changed(func(q *quotav1alpha1.QuotaProfile) { q.Labels = map[string]string{"team": "a"} })).To(BeTrue())
+			Expect(changed(func(q *quotav1alpha1.QuotaProfile) { q.Annotations = map[string]string{"note": "a"} })).To(BeTrue())
+			Expect(changed(func(q *quotav1alpha1.QuotaProfile) { q.Finalizers = []string{quotav1alpha1.QuotaProfileFinalizer} })).To(BeTrue())
+			Expect(changed(func(q *quotav1alpha1.QuotaProfile) { q.DeletionTimestamp = lo.ToPtr(metav1.Now()) })).To(BeTrue())
+		})
+	})
+
+	Context("When selecting namespaces by annotations, name prefix and age", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			reconciler *QuotaProfileReconciler
+			req        reconcile.Request
+		)
+
+		BeforeEach(func() {
+			log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+			ctx = context.Background()
+
+			s := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(s)
+			_ = quotav1alpha1.AddToScheme(s)
+
+			quotaProfile := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{
+						MatchAnnotations: map[string]string{"owner": "alice"},
+						MatchNamePrefix:  lo.ToPtr("team-"),
+						MinAge:           &metav1.Duration{Duration: 24 * time.Hour},
+					},
+				},
+			}
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(quotaProfile)}
+
+			namespace := func(name string, annotations map[string]string, age time.Duration) *v1.Namespace {
+				return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Annotations:       annotations,
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				}}
+			}
+			owner := map[string]string{"owner": "alice"}
+
+			fakeClient = newFakeClientBuilder(s).WithObjects(
+				quotaProfile,
+				namespace("team-old", owner, 48*time.Hour),
+				namespace("team-new", owner, time.Hour),
+				namespace("team-unowned", nil, 48*time.Hour),
+				namespace("platform", owner, 48*time.Hour),
+			).Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+		})
+
+		It("should bind the matching namespaces and requeue for the ones that are too young", func() {
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 23*time.Hour, time.Minute))
+
+			for name, bound := range map[string]bool{"team-old": true, "team-new": false, "team-unowned": false, "platform": false} {
+				ns := &v1.Namespace{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name}, ns)).To(Succeed())
+				if bound {
+					Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, "default.alice"), name)
+				} else {
+					Expect(ns.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey), name)
+				}
+			}
+
+		})
+
+		It("should map unlabelled namespaces to the profiles selecting them by annotation or name prefix", func() {
+			ns := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "team-unowned"}, ns)).To(Succeed())
+			Expect(reconciler.quotaProfilesForNamespace(ctx, ns)).To(ConsistOf(req))
+
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "platform"}, ns)).To(Succeed())
+			Expect(reconciler.quotaProfilesForNamespace(ctx, ns)).To(ConsistOf(req))
+		})
+	})
+
+	Context("When several profiles with the same precedence select a namespace", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			reconciler *QuotaProfileReconciler
+			requests   []reconcile.Request
+		)
+
+		BeforeEach(func() {
+			log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+			ctx = context.Background()
+
+			s := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(s)
+			_ = quotav1alpha1.AddToScheme(s)
+
+			profile := func(name string, labels map[string]string, age time.Duration) *quotav1alpha1.QuotaProfile {
+				return &quotav1alpha1.QuotaProfile{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+					Spec: quotav1alpha1.QuotaProfileSpec{
+						NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: labels},
+						Precedence:        5,
+					},
+				}
+			}
+			// the older profile used to win as the most recently reconciled one, the lexically smaller name wins now
+			older := profile("team", map[string]string{"team": "a"}, time.Hour)
+			newer := profile("environment", map[string]string{"environment": "dev"}, 0)
+			requests = []reconcile.Request{
+				{NamespacedName: client.ObjectKeyFromObject(older)},
+				{NamespacedName: client.ObjectKeyFromObject(newer)},
+			}
+
+			fakeClient = newFakeClientBuilder(s).WithObjects(
+				older, newer,
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-dev", Labels: map[string]string{"team": "a", "environment": "dev"}}},
+			).Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+		})
+
+		It("should bind the namespace to the same profile whichever is reconciled last", func() {
+			for _, order := range [][]reconcile.Request{requests, lo.Reverse(slices.Clone(requests))} {
+				for _, req := range order {
+					_, err := reconciler.Reconcile(ctx, req)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				ns := &v1.Namespace{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "team-a-dev"}, ns)).To(Succeed())
+				Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, "default.environment"))
+			}
+		})
+
+		It("should record the decision trace in the status of both profiles", func() {
+			for _, req := range requests {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			for _, req := range requests {
+				quotaProfile := &quotav1alpha1.QuotaProfile{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, quotaProfile)).To(Succeed())
+				Expect(quotaProfile.Status.Conflicts).To(ConsistOf(quotav1alpha1.NamespaceConflict{
+					Namespace: "team-a-dev",
+					BoundTo:   "default/environment",
+					Trace:     []string{"default/environment over default/team: same specificity and precedence, the lexically smaller name wins"},
+				}))
+			}
+		})
+	})
+
+	Context("When rolling out a resource", func() {
+		const (
+			resourceName = "test-resource"
+			profileID    = "default.test-resource"
+		)
+
+		var (
+			ctx          context.Context
+			fakeClient   client.Client
+			s            *runtime.Scheme
+			quotaProfile *quotav1alpha1.QuotaProfile
+			reconciler   *QuotaProfileReconciler
+			req          reconcile.Request
+		)
+
+		boundNamespace := func(name string, labels map[string]string) *v1.Namespace {
+			ns := &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+					Labels: map[string]string{
+						"environment":                      "test",
+						quotav1alpha1.QuotaProfileLabelKey: profileID,
+					},
+				},
+			}
+			for k, v := range labels {
+				ns.Labels[k] = v
+			}
+			return ns
+		}
+
+		BeforeEach(func() {
+			log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+
+			s = runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(s)
+			_ = quotav1alpha1.AddToScheme(s)
+
+			ctx = context.Background()
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}}
+
+			quotaProfile = &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       resourceName,
+					Namespace:  "default",
+					Generation: 2,
+					Finalizers: []string{quotav1alpha1.QuotaProfileFinalizer},
+				},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{
+						MatchLabels: map[string]string{"environment": "test"},
+					},
+					Rollout: &quotav1alpha1.RolloutStrategy{
+						MaxNamespacesPerInterval: 1,
+						Interval:                 metav1.Duration{Duration: time.Minute},
+						CanarySelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"canary": "true"},
+						},
+						MaxFailedAdmissions: 2,
+					},
+				},
+			}
+		})
+
+		It("should update the canary namespaces first, one batch per interval", func() {
+			fakeClient = newFakeClientBuilder(s).
+				WithObjects(quotaProfile, boundNamespace("a-ns", nil), boundNamespace("z-ns", map[string]string{"canary": "true"})).
+				WithStatusSubresource(quotaProfile).
+				Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+			canaryNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "z-ns"}, canaryNs)).To(Succeed())
+			Expect(canaryNs.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileGenerationLabelKey, "2"))
+
+			otherNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "a-ns"}, otherNs)).To(Succeed())
+			Expect(otherNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileGenerationLabelKey))
+
+			updatedProfile := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.Rollout).NotTo(BeNil())
+			Expect(updatedProfile.Status.Rollout.ObservedGeneration).To(Equal(int64(2)))
+			Expect(updatedProfile.Status.Rollout.UpdatedNamespaces).To(Equal(int32(1)))
+			Expect(updatedProfile.Status.Rollout.TotalNamespaces).To(Equal(int32(2)))
+			Expect(meta.IsStatusConditionFalse(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReady)).To(BeTrue())
+			reconciling := meta.FindStatusCondition(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReconciling)
+			Expect(reconciling).NotTo(BeNil())
+			Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+			Expect(reconciling.Reason).To(Equal(quotav1alpha1.QuotaProfileRollingOutReason))
+			Expect(reconciling.Message).To(Equal("1 of 2 namespaces updated"))
+
+			// the interval has not passed yet, so no further namespace is updated
+			result, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "a-ns"}, otherNs)).To(Succeed())
+			Expect(otherNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileGenerationLabelKey))
+
+			By("becoming ready once the rollout reached all namespaces")
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedProfile)).To(Succeed())
+			updatedProfile.Status.Rollout.LastBatchTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+			Expect(fakeClient.Status().Update(ctx, updatedProfile)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.Rollout.UpdatedNamespaces).To(Equal(int32(2)))
+			Expect(meta.IsStatusConditionTrue(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReady)).To(BeTrue())
+			Expect(meta.FindStatusCondition(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReconciling)).To(BeNil())
+		})
+
+		It("should pause the rollout when quota admission failures spike", func() {
+			quotaProfile.Status.Rollout = &quotav1alpha1.RolloutStatus{
+				ObservedGeneration: 2,
+				StartTime:          &metav1.Time{Time: time.Now().Add(-time.Hour)},
+				LastBatchTime:      &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			}
+			event := &v1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "rs.failed", Namespace: "z-ns"},
+				Reason:         "FailedCreate",
+				Message:        `pods "web-1" is forbidden: exceeded quota: default-test-resource-0-rq`,
+				Count:          5,
+				FirstTimestamp: metav1.Now(),
+				LastTimestamp:  metav1.Now(),
+			}
+
+			fakeClient = newFakeClientBuilder(s).
+				WithObjects(quotaProfile, event,
+					boundNamespace("a-ns", nil),
+					boundNamespace("z-ns", map[string]string{quotav1alpha1.QuotaProfileGenerationLabelKey: "2"})).
+				WithStatusSubresource(quotaProfile).
+				Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			otherNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "a-ns"}, otherNs)).To(Succeed())
+			Expect(otherNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileGenerationLabelKey))
+
+			updatedProfile := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.Rollout.Paused).To(BeTrue())
+			Expect(updatedProfile.Status.Rollout.FailedAdmissions).To(Equal(int32(5)))
+
+			stalled := meta.FindStatusCondition(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileStalled)
+			Expect(stalled).NotTo(BeNil())
+			Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+			Expect(stalled.Reason).To(Equal(quotav1alpha1.QuotaProfileRolloutPausedReason))
+			Expect(meta.IsStatusConditionFalse(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReady)).To(BeTrue())
+			Expect(meta.FindStatusCondition(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReconciling)).To(BeNil())
+		})
+
+		It("should not count quota admission failures of a series that started before the rollout", func() {
+			quotaProfile.Status.Rollout = &quotav1alpha1.RolloutStatus{
+				ObservedGeneration: 2,
+				StartTime:          &metav1.Time{Time: time.Now().Add(-time.Hour)},
+				LastBatchTime:      &metav1.Time{Time: time.Now()},
+			}
+			event := &v1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "rs.failing", Namespace: "z-ns"},
+				Reason:         "FailedCreate",
+				Message:        `pods "web-1" is forbidden: exceeded quota: default-test-resource-0-rq`,
+				Count:          500,
+				FirstTimestamp: metav1.NewTime(time.Now().Add(-24 * time.Hour)),
+				LastTimestamp:  metav1.Now(),
+			}
+
+			fakeClient = newFakeClientBuilder(s).
+				WithObjects(quotaProfile, event,
+					boundNamespace("a-ns", nil),
+					boundNamespace("z-ns", map[string]string{quotav1alpha1.QuotaProfileGenerationLabelKey: "2"})).
+				WithStatusSubresource(quotaProfile).
+				Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedProfile := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.Rollout.Paused).To(BeFalse())
+			Expect(updatedProfile.Status.Rollout.FailedAdmissions).To(BeZero())
+		})
+
+		It("should remove the generation label and the rollout status once the rollout is removed", func() {
+			quotaProfile.Spec.Rollout = nil
+			quotaProfile.Status.Rollout = &quotav1alpha1.RolloutStatus{
+				ObservedGeneration: 1,
+				UpdatedNamespaces:  1,
+				TotalNamespaces:    2,
+			}
+
+			fakeClient = newFakeClientBuilder(s).
+				WithObjects(quotaProfile,
+					boundNamespace("a-ns", nil),
+					boundNamespace("z-ns", map[string]string{quotav1alpha1.QuotaProfileGenerationLabelKey: "1"})).
+				WithStatusSubresource(quotaProfile).
+				Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"a-ns", "z-ns"} {
+				ns := &v1.Namespace{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name}, ns)).To(Succeed())
+				Expect(ns.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileGenerationLabelKey))
+				Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, profileID))
+			}
+
+			updatedProfile := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.Rollout).To(BeNil())
+			Expect(meta.IsStatusConditionTrue(updatedProfile.Status.Conditions, quotav1alpha1.QuotaProfileReady)).To(BeTrue())
+		})
+	})
+
+	Context("When namespace labels are changed concurrently", func() {
+		const resourceName = "test-resource"
+
+		var (
+			ctx          context.Context
+			fakeClient   client.Client
+			quotaProfile *quotav1alpha1.QuotaProfile
+			reconciler   *QuotaProfileReconciler
+			failing      map[string]bool
+		)
+
+		BeforeEach(func() {
+			log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+			ctx = context.Background()
+
+			s := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(s)
+			_ = quotav1alpha1.AddToScheme(s)
+
+			quotaProfile = &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"environment": "test"}},
+				},
+			}
+			namespaces := lo.Map([]string{"a-ns", "b-ns", "c-ns"}, func(name string, _ int) client.Object {
+				return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"environment": "test"}}}
+			})
+
+			failing = map[string]bool{}
+			raced := map[string]bool{}
+			fakeClient = newFakeClientBuilder(s).
+				WithObjects(append(namespaces, quotaProfile)...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if failing[obj.GetName()] {
+							return errors.New("admission webhook denied the request")
+						}
+						// another tool labels the namespace between the read and the first patch of the operator
+						if _, ok := obj.(*v1.Namespace); ok && !raced[obj.GetName()] {
+							raced[obj.GetName()] = true
+							ns := &v1.Namespace{}
+							Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), ns)).To(Succeed())
+							ns.Labels["owner"] = "team-a"
+							Expect(c.Update(ctx, ns)).To(Succeed())
+						}
+						return c.Patch(ctx, obj, patch, opts...)
+					},
+				}).
+				Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s}
+		})
+
+		It("should retry conflicts and keep the labels of other tools", func() {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"a-ns", "b-ns", "c-ns"} {
+				ns := &v1.Namespace{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name}, ns)).To(Succeed())
+				Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, "default."+resourceName))
+				Expect(ns.Labels).To(HaveKeyWithValue("owner", "team-a"))
+			}
+		})
+
+		It("should label the other namespaces when one of them fails", func() {
+			failing["b-ns"] = true
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}})
+			Expect(err).To(MatchError(ContainSubstring("admission webhook denied the request")))
+
+			for name, labelled := range map[string]bool{"a-ns": true, "b-ns": false, "c-ns": true} {
+				ns := &v1.Namespace{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name}, ns)).To(Succeed())
+				if labelled {
+					Expect(ns.Labels).To(HaveKey(quotav1alpha1.QuotaProfileLabelKey), name)
+				} else {
+					Expect(ns.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey), name)
+				}
+			}
+
+			By("reporting the namespace that is not bound yet in the conditions")
+			updated := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), updated)).To(Succeed())
+			Expect(updated.Status.BoundNamespaces).To(Equal(int32(2)))
+			reconciling := meta.FindStatusCondition(updated.Status.Conditions, quotav1alpha1.QuotaProfileReconciling)
+			Expect(reconciling).NotTo(BeNil())
+			Expect(reconciling.Reason).To(Equal(quotav1alpha1.QuotaProfileBindFailedReason))
+			Expect(reconciling.Message).To(HavePrefix("1 of 3 selected namespaces could not be bound"))
+			Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, quotav1alpha1.QuotaProfileReady)).To(BeTrue())
+
+			failing["b-ns"] = false
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaProfile), updated)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, quotav1alpha1.QuotaProfileReady)).To(BeTrue())
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, quotav1alpha1.QuotaProfileReconciling)).To(BeNil())
+		})
+	})
+
+	Context("When deleting a resource", func() {
+		const (
+			resourceName  = "test-resource"
+			namespaceName = "test-namespace"
+			profileID     = "default.test-resource"
+		)
+
+		var (
+			ctx          context.Context
+			fakeClient   client.Client
+			s            *runtime.Scheme
+			quotaProfile *quotav1alpha1.QuotaProfile
+			reconciler   *QuotaProfileReconciler
+			req          reconcile.Request
+		)
+
+		BeforeEach(func() {
+			log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+
+			s = runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(s)
+			_ = quotav1alpha1.AddToScheme(s)
+
+			ctx = context.Background()
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: resourceName, Namespace: "default"}}
+
+			quotaProfile = &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       resourceName,
+					Namespace:  "default",
+					Finalizers: []string{quotav1alpha1.QuotaProfileFinalizer},
+				},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{
+						MatchName: &[]string{namespaceName}[0],
+					},
+				},
+			}
+		})
+
+		setup := func(policy quotav1alpha1.DeletionPolicy) {
+			quotaProfile.Spec.DeletionPolicy = policy
+
+			ns := &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   namespaceName,
+					Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: profileID},
+				},
+			}
+			rq := &v1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default-test-resource-0-rq",
+					Namespace: namespaceName,
+					Labels:    map[string]string{quotav1alpha1.QuotaProfileLabelKey: profileID},
+				},
+			}
+
+			fakeClient = newFakeClientBuilder(s).
+				WithObjects(quotaProfile, ns, rq).
+				Build()
+
+			reconciler = &QuotaProfileReconciler{
+				Client: fakeClient,
+				Scheme: s,
+			}
+
+			Expect(fakeClient.Delete(ctx, quotaProfile)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("should remove the profile label from namespaces with the Delete policy", func() {
+			setup(quotav1alpha1.DeletionPolicyDelete)
+
+			updatedNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: namespaceName}, updatedNs)).To(Succeed())
+			Expect(updatedNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey))
+
+			rq := &v1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "default-test-resource-0-rq", Namespace: namespaceName}, rq)).To(Succeed())
+			Expect(rq.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, profileID))
+		})
+
+		It("should leave unmanaged resource quotas behind with the Orphan policy", func() {
+			setup(quotav1alpha1.DeletionPolicyOrphan)
+
+			updatedNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: namespaceName}, updatedNs)).To(Succeed())
+			Expect(updatedNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey))
+
+			rq := &v1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "default-test-resource-0-rq", Namespace: namespaceName}, rq)).To(Succeed())
+			Expect(rq.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey))
+		})
+
+		It("should keep the namespace bound with the Retain policy", func() {
+			setup(quotav1alpha1.DeletionPolicyRetain)
+
+			updatedNs := &v1.Namespace{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: namespaceName}, updatedNs)).To(Succeed())
+			Expect(updatedNs.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, profileID))
+			Expect(updatedNs.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileRetainedLabelKey, "true"))
+
+			rq := &v1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "default-test-resource-0-rq", Namespace: namespaceName}, rq)).To(Succeed())
+			Expect(rq.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, profileID))
+		})
+
+		It("should remove the hub finalizer left by an earlier run as hub", func() {
+			quotaProfile.Finalizers = append(quotaProfile.Finalizers, quotav1alpha1.QuotaProfileHubFinalizer)
+			setup(quotav1alpha1.DeletionPolicyDelete)
+
+			err := fakeClient.Get(ctx, req.NamespacedName, &quotav1alpha1.QuotaProfile{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should leave the hub finalizer to the hub controller when running as hub", func() {
+			quotaProfile.Finalizers = append(quotaProfile.Finalizers, quotav1alpha1.QuotaProfileHubFinalizer)
+			fakeClient = newFakeClientBuilder(s).WithObjects(quotaProfile).Build()
+			reconciler = &QuotaProfileReconciler{Client: fakeClient, Scheme: s, HubEnabled: true}
+
+			Expect(fakeClient.Delete(ctx, quotaProfile)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &quotav1alpha1.QuotaProfile{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Finalizers).To(Equal([]string{quotav1alpha1.QuotaProfileHubFinalizer}))
+		})
 	})
 })
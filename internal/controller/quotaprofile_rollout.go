@@ -0,0 +1,262 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
+
+// reconcileRollout moves the staged rollout of the quota profile forward by at most one batch.
+// Bound namespaces receive the new profile generation through the generation label, which the
+// namespace controller waits for before touching the managed resources.
+// It returns the time after which the next batch can be started, or zero when the rollout is done or paused.
+func (r *QuotaProfileReconciler) reconcileRollout(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile) (time.Duration, error) {
+	l := log.FromContext(ctx)
+	strategy := quotaProfile.Spec.Rollout
+
+	namespaces, err := index.NamespacesWithLabel(ctx, r.Client, quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name))
+	if err != nil {
+		l.Error(err, "failed to list bound namespaces")
+		return 0, err
+	}
+
+	status := quotaProfile.Status.Rollout.DeepCopy()
+	if status == nil || status.ObservedGeneration != quotaProfile.Generation {
+		l.Info("starting rollout", "quotaProfile", quotaProfile.Name, "generation", quotaProfile.Generation)
+		status = &quotav1alpha1.RolloutStatus{
+			ObservedGeneration: quotaProfile.Generation,
+			StartTime:          &metav1.Time{Time: time.Now()},
+		}
+	}
+
+	generation := strconv.FormatInt(quotaProfile.Generation, 10)
+	var updated, pending []v1.Namespace
+	for _, ns := range namespaces {
+		if ns.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] == generation {
+			updated = append(updated, ns)
+		} else {
+			pending = append(pending, ns)
+		}
+	}
+
+	var requeueAfter time.Duration
+	if strategy.MaxFailedAdmissions > 0 && !status.Paused {
+		failed, err := r.countFailedAdmissions(ctx, updated, status.StartTime.Time)
+		if err != nil {
+			l.Error(err, "failed to count failed admissions")
+			return 0, err
+		}
+		status.FailedAdmissions = failed
+		if failed > strategy.MaxFailedAdmissions {
+			l.Info("pausing rollout due to failed admissions", "quotaProfile", quotaProfile.Name, "failedAdmissions", failed)
+			status.Paused = true
+			status.Message = fmt.Sprintf("paused after %d pod creations failed quota admission, update the profile to resume", failed)
+		}
+	}
+
+	if !status.Paused && len(pending) > 0 {
+		wait := time.Duration(0)
+		if status.LastBatchTime != nil {
+			wait = time.Until(status.LastBatchTime.Add(strategy.Interval.Duration))
+		}
+
+		if wait > 0 {
+			requeueAfter = wait
+		} else {
+			batch, err := nextRolloutBatch(pending, strategy)
+			if err != nil {
+				l.Error(err, "failed to select rollout batch")
+				return 0, err
+			}
+
+			// namespaces that fail are left pending and picked up again by the next batch
+			var errs []error
+			for _, ns := range batch {
+				l.Info("rolling out quota profile to namespace", "namespace", ns.Name, "generation", generation)
+				if err := r.patchNamespaceLabels(ctx, &ns, func(ns *v1.Namespace) error {
+					ns.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] = generation
+					return nil
+				}); err != nil {
+					l.Error(err, "failed to roll out quota profile to namespace", "namespace", ns.Name)
+					errs = append(errs, err)
+					continue
+				}
+				updated = append(updated, ns)
+			}
+			if err := errors.Join(errs...); err != nil {
+				return 0, err
+			}
+
+			status.LastBatchTime = &metav1.Time{Time: time.Now()}
+			if len(updated) < len(namespaces) {
+				requeueAfter = strategy.Interval.Duration
+				if requeueAfter == 0 {
+					requeueAfter = time.Second
+				}
+			}
+		}
+	}
+
+	status.UpdatedNamespaces = int32(len(updated))
+	status.TotalNamespaces = int32(len(namespaces))
+	if !status.Paused {
+		if len(updated) == len(namespaces) {
+			status.Message = "rollout complete"
+		} else {
+			status.Message = fmt.Sprintf("%d of %d namespaces updated", len(updated), len(namespaces))
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(status, quotaProfile.Status.Rollout) {
+		quotaProfile.Status.Rollout = status
+		if err := r.Status().Update(ctx, quotaProfile); err != nil {
+			l.Error(err, "failed to update rollout status", "quotaProfile", quotaProfile.Name)
+			return 0, err
+		}
+	}
+
+	return requeueAfter, nil
+}
+
+// clearRollout removes the generation label from the bound namespaces and the rollout status once the
+// rollout strategy was removed from the profile, so that a strategy added later starts from a clean state.
+func (r *QuotaProfileReconciler) clearRollout(ctx context.Context, quotaProfile *quotav1alpha1.QuotaProfile) error {
+	l := log.FromContext(ctx)
+
+	namespaces, err := index.NamespacesWithLabel(ctx, r.Client, quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name))
+	if err != nil {
+		l.Error(err, "failed to list bound namespaces")
+		return err
+	}
+
+	var errs []error
+	for _, ns := range namespaces {
+		if _, ok := ns.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey]; !ok {
+			continue
+		}
+		l.Info("removing rollout generation label from namespace", "namespace", ns.Name)
+		if err := r.patchNamespaceLabels(ctx, &ns, func(ns *v1.Namespace) error {
+			delete(ns.Labels, quotav1alpha1.QuotaProfileGenerationLabelKey)
+			return nil
+		}); err != nil {
+			l.Error(err, "failed to remove rollout generation label from namespace", "namespace", ns.Name)
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if quotaProfile.Status.Rollout != nil {
+		quotaProfile.Status.Rollout = nil
+		if err := r.Status().Update(ctx, quotaProfile); err != nil {
+			l.Error(err, "failed to clear rollout status", "quotaProfile", quotaProfile.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// nextRolloutBatch returns the pending namespaces to update next. Namespaces matching the canary
+// selector are always rolled out before all others.
+func nextRolloutBatch(pending []v1.Namespace, strategy *quotav1alpha1.RolloutStrategy) ([]v1.Namespace, error) {
+	canary := labels.Nothing()
+	if strategy.CanarySelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(strategy.CanarySelector)
+		if err != nil {
+			return nil, err
+		}
+		canary = selector
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		ci, cj := canary.Matches(labels.Set(pending[i].Labels)), canary.Matches(labels.Set(pending[j].Labels))
+		if ci != cj {
+			return ci
+		}
+		return pending[i].Name < pending[j].Name
+	})
+
+	size := int(strategy.MaxNamespacesPerInterval)
+	if size <= 0 || size > len(pending) {
+		size = len(pending)
+	}
+	return pending[:size], nil
+}
+
+// countFailedAdmissions counts the pod creations that were rejected by quota admission in the
+// given namespaces since the given time, based on the FailedCreate events of their controllers.
+// The count of an event covers its whole series, so only series that started after the given time are
+// counted, a controller that was already failing before does not pause the rollout right away.
+func (r *QuotaProfileReconciler) countFailedAdmissions(ctx context.Context, namespaces []v1.Namespace, since time.Time) (int32, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	var failed int32
+	for _, ns := range namespaces {
+		events := &v1.EventList{}
+		if err := reader.List(ctx, events, client.InNamespace(ns.Name), client.MatchingFields{"reason": "FailedCreate"}); err != nil {
+			return 0, err
+		}
+
+		for _, e := range events.Items {
+			if e.Reason != "FailedCreate" || !strings.Contains(e.Message, "exceeded quota") {
+				continue
+			}
+
+			first := e.FirstTimestamp.Time
+			if first.IsZero() {
+				first = e.EventTime.Time
+			}
+			if first.Before(since) {
+				continue
+			}
+
+			count := e.Count
+			if e.Series != nil && e.Series.Count > count {
+				count = e.Series.Count
+			}
+			if count > 0 {
+				failed += count
+			} else {
+				failed++
+			}
+		}
+	}
+	return failed, nil
+}
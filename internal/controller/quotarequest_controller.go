@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+)
+
+// QuotaRequestReconciler reconciles a QuotaRequest object
+type QuotaRequestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=quota.dev.operator,resources=quotarequests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=quota.dev.operator,resources=quotarequests/status,verbs=get;update;patch
+
+// Reconcile decides on pending QuotaRequests: requests within the auto-approval threshold of the profile are
+// approved right away, larger ones once an approver sets the approved-by annotation, and requests that exceed
+// the ceiling of the profile are denied. Approved and denied requests are final.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.20.0/pkg/reconcile
+func (r *QuotaRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+
+	quotaRequest := &quotav1alpha1.QuotaRequest{}
+	if err := r.Get(ctx, req.NamespacedName, quotaRequest); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		l.Error(err, "failed to get quota request", "quotaRequest", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+	if quotaRequest.Status.Phase == quotav1alpha1.QuotaRequestApproved || quotaRequest.Status.Phase == quotav1alpha1.QuotaRequestDenied {
+		return ctrl.Result{}, nil
+	}
+
+	status, err := r.decide(ctx, quotaRequest)
+	if err != nil {
+		l.Error(err, "failed to decide on quota request", "quotaRequest", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+	if status.Phase == quotaRequest.Status.Phase && status.Message == quotaRequest.Status.Message {
+		return ctrl.Result{}, nil
+	}
+	if status.Phase != quotav1alpha1.QuotaRequestPending {
+		status.DecisionTime = lo.ToPtr(metav1.Now())
+	}
+
+	l.Info("updating quota request", "quotaRequest", req.NamespacedName, "phase", status.Phase, "message", status.Message)
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, req.NamespacedName, quotaRequest); err != nil {
+			return err
+		}
+		quotaRequest.Status = status
+		return r.Status().Update(ctx, quotaRequest)
+	}); err != nil {
+		l.Error(err, "failed to update quota request status", "quotaRequest", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// decide returns the status of the quota request according to the quota request policy of the profile its
+// namespace is bound to.
+func (r *QuotaRequestReconciler) decide(ctx context.Context, quotaRequest *quotav1alpha1.QuotaRequest) (quotav1alpha1.QuotaRequestStatus, error) {
+	denied := func(format string, args ...any) (quotav1alpha1.QuotaRequestStatus, error) {
+		return quotav1alpha1.QuotaRequestStatus{Phase: quotav1alpha1.QuotaRequestDenied, Message: fmt.Sprintf(format, args...)}, nil
+	}
+
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: quotaRequest.Namespace}, ns); err != nil {
+		return quotav1alpha1.QuotaRequestStatus{}, err
+	}
+	profileNamespace, profileName := quota.SplitProfileID(ns.Labels[quotav1alpha1.QuotaProfileLabelKey])
+	profile := &quotav1alpha1.QuotaProfile{}
+	if profileName != "" {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: profileNamespace, Name: profileName}, profile); client.IgnoreNotFound(err) != nil {
+			return quotav1alpha1.QuotaRequestStatus{}, err
+		}
+	}
+	policy := profile.Spec.QuotaRequests
+	if policy == nil || profile.DeletionTimestamp != nil {
+		return denied("namespace %s is not bound to a quota profile that accepts quota requests", quotaRequest.Namespace)
+	}
+	profileRef := fmt.Sprintf("%s/%s", profile.Namespace, profile.Name)
+
+	approved, err := quota.ApprovedQuotaRequestResources(ctx, r.Client, quotaRequest.Namespace, profileRef, quotaRequest.Name)
+	if err != nil {
+		return quotav1alpha1.QuotaRequestStatus{}, err
+	}
+
+	names := lo.Keys(quotaRequest.Spec.Resources)
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	autoApprove := true
+	for _, name := range names {
+		requested := quotaRequest.Spec.Resources[name]
+		ceiling, ok := policy.Ceiling[name]
+		if !ok {
+			return denied("resource %s cannot be requested, quota profile %s has no ceiling for it", name, profileRef)
+		}
+		total := approved[name]
+		total.Add(requested)
+		if total.Cmp(ceiling) > 0 {
+			already := approved[name]
+			return denied("%s=%s would exceed the ceiling of %s of quota profile %s, %s is already approved",
+				name, requested.String(), ceiling.String(), profileRef, already.String())
+		}
+		if threshold, ok := policy.AutoApprove[name]; !ok || requested.Cmp(threshold) > 0 {
+			autoApprove = false
+		}
+	}
+
+	status := quotav1alpha1.QuotaRequestStatus{Phase: quotav1alpha1.QuotaRequestApproved, QuotaProfile: profileRef}
+	approver := quotaRequest.Annotations[quotav1alpha1.QuotaRequestApprovedByAnnotationKey]
+	switch {
+	case autoApprove:
+		status.Message = "approved automatically, the request is within the auto-approval threshold"
+	case approver != "":
+		status.ApprovedBy = approver
+		status.Message = fmt.Sprintf("approved by %s", approver)
+	default:
+		status.Phase = quotav1alpha1.QuotaRequestPending
+		status.Message = fmt.Sprintf("the request exceeds the auto-approval threshold and needs the %s annotation of an approver", quotav1alpha1.QuotaRequestApprovedByAnnotationKey)
+	}
+	return status, nil
+}
+
+// quotaRequestNamespace maps a QuotaRequest to the namespace it is in.
+func quotaRequestNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+// quotaRequestDecidedPredicate passes QuotaRequests whose phase changed, e.g. when they are approved.
+var quotaRequestDecidedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldRequest, ok := e.ObjectOld.(*quotav1alpha1.QuotaRequest)
+		if !ok {
+			return true
+		}
+		newRequest, ok := e.ObjectNew.(*quotav1alpha1.QuotaRequest)
+		if !ok {
+			return true
+		}
+		return oldRequest.Status.Phase != newRequest.Status.Phase
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QuotaRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&quotav1alpha1.QuotaRequest{}).
+		Named("quotarequest").
+		Complete(r)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+var _ = Describe("QuotaRequest Controller", func() {
+	var (
+		ctx          context.Context
+		fakeClient   client.Client
+		quotaProfile *quotav1alpha1.QuotaProfile
+		reconciler   *QuotaRequestReconciler
+	)
+
+	createRequest := func(name string, resources v1.ResourceList, annotations map[string]string) *quotav1alpha1.QuotaRequest {
+		quotaRequest := &quotav1alpha1.QuotaRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Annotations: annotations},
+			Spec:       quotav1alpha1.QuotaRequestSpec{Resources: resources},
+		}
+		Expect(fakeClient.Create(ctx, quotaRequest)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(quotaRequest)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaRequest), quotaRequest)).To(Succeed())
+		return quotaRequest
+	}
+
+	cpu := func(quantity string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse(quantity)}
+	}
+
+	BeforeEach(func() {
+		log.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+		ctx = context.Background()
+		s := setupFakeClientWithScheme()
+
+		quotaProfile = &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchName: lo.ToPtr("team-a")},
+				ResourceQuotaSpecs: []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{
+					v1.ResourceRequestsCPU: resource.MustParse("10"),
+					v1.ResourcePods:        resource.MustParse("20"),
+				}}},
+				QuotaRequests: &quotav1alpha1.QuotaRequestPolicy{
+					Ceiling:     cpu("8"),
+					AutoApprove: cpu("2"),
+				},
+			},
+		}
+
+		fakeClient = newFakeClientBuilder(s).WithObjects(
+			quotaProfile,
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "team-a",
+				Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.web"},
+			}},
+		).Build()
+		reconciler = &QuotaRequestReconciler{Client: fakeClient, Scheme: s}
+	})
+
+	It("should approve requests within the auto-approval threshold", func() {
+		quotaRequest := createRequest("small", cpu("2"), nil)
+
+		Expect(quotaRequest.Status.Phase).To(Equal(quotav1alpha1.QuotaRequestApproved))
+		Expect(quotaRequest.Status.QuotaProfile).To(Equal("default/web"))
+		Expect(quotaRequest.Status.ApprovedBy).To(BeEmpty())
+		Expect(quotaRequest.Status.DecisionTime).NotTo(BeNil())
+	})
+
+	It("should wait for an approver for requests above the threshold", func() {
+		quotaRequest := createRequest("large", cpu("4"), nil)
+		Expect(quotaRequest.Status.Phase).To(Equal(quotav1alpha1.QuotaRequestPending))
+		Expect(quotaRequest.Status.Message).To(ContainSubstring(quotav1alpha1.QuotaRequestApprovedByAnnotationKey))
+
+		quotaRequest.Annotations = map[string]string{quotav1alpha1.QuotaRequestApprovedByAnnotationKey: "alice"}
+		Expect(fakeClient.Update(ctx, quotaRequest)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(quotaRequest)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(quotaRequest), quotaRequest)).To(Succeed())
+		Expect(quotaRequest.Status.Phase).To(Equal(quotav1alpha1.QuotaRequestApproved))
+		Expect(quotaRequest.Status.ApprovedBy).To(Equal("alice"))
+	})
+
+	It("should deny requests that exceed the ceiling together with the approved ones", func() {
+		createRequest("first", cpu("2"), nil)
+		createRequest("second", cpu("5"), map[string]string{quotav1alpha1.QuotaRequestApprovedByAnnotationKey: "alice"})
+
+		quotaRequest := createRequest("third", cpu("2"), nil)
+		Expect(quotaRequest.Status.Phase).To(Equal(quotav1alpha1.QuotaRequestDenied))
+		Expect(quotaRequest.Status.Message).To(Equal("requests.cpu=2 would exceed the ceiling of 8 of quota profile default/web, 7 is already approved"))
+	})
+
+	It("should deny requests for resources without a ceiling", func() {
+		quotaRequest := createRequest("pods", v1.ResourceList{v1.ResourcePods: resource.MustParse("1")}, nil)
+
+		Expect(quotaRequest.Status.Phase).To(Equal(quotav1alpha1.QuotaRequestDenied))
+		Expect(quotaRequest.Status.Message).To(ContainSubstring("quota profile default/web has no ceiling for it"))
+	})
+
+	It("should deny requests in namespaces whose profile does not accept them", func() {
+		quotaProfile.Spec.QuotaRequests = nil
+		Expect(fakeClient.Update(ctx, quotaProfile)).To(Succeed())
+
+		quotaRequest := createRequest("small", cpu("1"), nil)
+		Expect(quotaRequest.Status.Phase).To(Equal(quotav1alpha1.QuotaRequestDenied))
+	})
+
+	It("should add approved requests to the managed resource quotas", func() {
+		createRequest("first", cpu("2"), nil)
+		createRequest("second", cpu("1"), nil)
+		createRequest("pending", cpu("4"), nil)
+
+		namespaceReconciler := &NamespaceReconciler{Client: fakeClient, Scheme: reconciler.Scheme}
+		_, err := namespaceReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		rq := &v1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "default-web-0-rq"}, rq)).To(Succeed())
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourceRequestsCPU, resource.MustParse("13")))
+		Expect(rq.Spec.Hard).To(HaveKeyWithValue(v1.ResourcePods, resource.MustParse("20")))
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crdschema validates objects against the OpenAPI schema and the CEL rules of a
+// CustomResourceDefinition, the way the API server does on create.
+package crdschema
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"sigs.k8s.io/yaml"
+
+	"github.com/abdullah599/namespace-quota-operator/config/crd"
+)
+
+// Validator validates the objects of all versions of one CustomResourceDefinition.
+type Validator struct {
+	crd      *apiextensions.CustomResourceDefinition
+	versions map[string]*versionValidator
+}
+
+type versionValidator struct {
+	schema     validation.SchemaValidator
+	structural *schema.Structural
+	cel        *cel.Validator
+}
+
+// New returns a Validator for the CustomResourceDefinition in the YAML manifest.
+func New(manifest []byte) (*Validator, error) {
+	external := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(manifest, external); err != nil {
+		return nil, fmt.Errorf("invalid CustomResourceDefinition: %w", err)
+	}
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := apiextensionsv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(external, internal, nil); err != nil {
+		return nil, err
+	}
+
+	v := &Validator{crd: internal, versions: map[string]*versionValidator{}}
+	for _, version := range internal.Spec.Versions {
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		schemaValidator, _, err := validation.NewSchemaValidator(version.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema of version %s: %w", version.Name, err)
+		}
+		structural, err := schema.NewStructural(version.Schema.OpenAPIV3Schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema of version %s: %w", version.Name, err)
+		}
+		v.versions[version.Name] = &versionValidator{
+			schema:     schemaValidator,
+			structural: structural,
+			cel:        cel.NewValidator(structural, true, celconfig.PerCallLimit),
+		}
+	}
+	return v, nil
+}
+
+// NewQuotaProfileValidator returns a Validator for the QuotaProfile CustomResourceDefinition of the operator.
+func NewQuotaProfileValidator() (*Validator, error) {
+	return New(crd.QuotaProfiles)
+}
+
+// CustomResourceDefinition returns the internal version of the CustomResourceDefinition.
+func (v *Validator) CustomResourceDefinition() *apiextensions.CustomResourceDefinition {
+	return v.crd
+}
+
+// Validate validates the object against the schema of its version.
+func (v *Validator) Validate(ctx context.Context, obj runtime.Object) (field.ErrorList, error) {
+	u, ok := obj.(runtime.Unstructured)
+	var content map[string]interface{}
+	if ok {
+		content = u.UnstructuredContent()
+	} else {
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Group != v.crd.Spec.Group || gvk.Kind != v.crd.Spec.Names.Kind {
+		return nil, fmt.Errorf("expected a %s of group %s but got %s", v.crd.Spec.Names.Kind, v.crd.Spec.Group, gvk)
+	}
+	version, found := v.versions[gvk.Version]
+	if !found {
+		return nil, fmt.Errorf("version %s of %s is not served", gvk.Version, v.crd.Spec.Names.Kind)
+	}
+
+	errs := validation.ValidateCustomResource(nil, content, version.schema)
+	celErrs, _ := version.cel.Validate(ctx, nil, version.structural, content, nil, celconfig.RuntimeCELCostBudget)
+	return append(errs, celErrs...), nil
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdschema
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apiextensionsvalidation "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// validateSpec validates a QuotaProfile with the spec in the given version.
+func validateSpec(t *testing.T, v *Validator, version, spec string) field.ErrorList {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte("apiVersion: quota.dev.operator/"+version+"\nkind: QuotaProfile\nmetadata:\n  name: test\nspec:\n"+spec), &obj.Object); err != nil {
+		t.Fatal(err)
+	}
+	errs, err := v.Validate(context.Background(), obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return errs
+}
+
+func TestCRDIsValid(t *testing.T) {
+	v, err := NewQuotaProfileValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crd := v.CustomResourceDefinition().DeepCopy()
+	// the stored versions are set by the API server
+	crd.Status.StoredVersions = []string{"v1beta1"}
+	if errs := apiextensionsvalidation.ValidateCustomResourceDefinition(context.Background(), crd); len(errs) > 0 {
+		t.Fatalf("the API server would reject the CRD: %v", errs.ToAggregate())
+	}
+}
+
+func TestCRDValidation(t *testing.T) {
+	v, err := NewQuotaProfileValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const v1alpha1Quota = `
+  resourceQuotaSpecs:
+  - hard:
+      requests.cpu: "1"
+`
+	const v1beta1Quota = `
+  resourceQuotas:
+  - name: compute
+    hard:
+      requests.cpu: "1"
+`
+
+	for name, tc := range map[string]struct {
+		version  string
+		spec     string
+		expected string
+	}{
+		"v1alpha1 valid matchLabels": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchLabels:\n      environment: dev" + v1alpha1Quota,
+		},
+		"v1alpha1 valid matchName with a LimitRange only": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchName: dev\n  limitRangeSpecs:\n  - limits:\n    - type: Container\n      default:\n        cpu: 500m\n",
+		},
+		"v1alpha1 no selector": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector: {}" + v1alpha1Quota,
+			expected: "one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions, namespaceSelector.matchName, namespaceSelector.matchAnnotations or namespaceSelector.matchNamePrefix must be set",
+		},
+		"v1alpha1 both selectors": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n    matchLabels:\n      environment: dev" + v1alpha1Quota,
+			expected: "only one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions or namespaceSelector.matchName can be set",
+		},
+		"v1alpha1 valid matchAnnotations with a name prefix and minimum age": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchAnnotations:\n      owner: alice\n    matchNamePrefix: team-\n    minAge: 24h" + v1alpha1Quota,
+		},
+		"v1alpha1 matchName with matchAnnotations": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n    matchAnnotations:\n      owner: alice" + v1alpha1Quota,
+			expected: "namespaceSelector.matchName cannot be combined with matchAnnotations, matchNamePrefix or minAge",
+		},
+		"v1alpha1 minAge only": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    minAge: 24h" + v1alpha1Quota,
+			expected: "one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions, namespaceSelector.matchName, namespaceSelector.matchAnnotations or namespaceSelector.matchNamePrefix must be set",
+		},
+		"v1alpha1 valid labels and expressions": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchLabels:\n      environment: dev\n      team: a\n    matchExpressions:\n    - key: tier\n      operator: NotIn\n      values: [sandbox]" + v1alpha1Quota,
+		},
+		"v1alpha1 empty matchLabels": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchLabels: {}" + v1alpha1Quota,
+			expected: "spec.namespaceSelector.matchLabels",
+		},
+		"v1alpha1 matchName with matchExpressions": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n    matchExpressions:\n    - key: tier\n      operator: Exists" + v1alpha1Quota,
+			expected: "only one of namespaceSelector.matchLabels, namespaceSelector.matchExpressions or namespaceSelector.matchName can be set",
+		},
+		"v1alpha1 precedence out of range": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  precedence: 65536" + v1alpha1Quota,
+			expected: "spec.precedence",
+		},
+		"v1alpha1 no specs": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n",
+			expected: "at least one of resourceQuotaSpecs, limitRangeSpecs, objectTemplates, budget or aggregateHard must be set",
+		},
+		"v1alpha1 empty ResourceQuota spec": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  resourceQuotaSpecs:\n  - {}\n",
+			expected: "every resourceQuotaSpecs entry must set hard limits",
+		},
+		"v1alpha1 empty LimitRange spec": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  limitRangeSpecs:\n  - limits: []\n",
+			expected: "every limitRangeSpecs entry must set limits",
+		},
+		"v1alpha1 valid NetworkPolicy template only": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchName: dev\n  objectTemplates:\n  - template:\n      apiVersion: networking.k8s.io/v1\n      kind: NetworkPolicy\n      spec:\n        podSelector: {}\n        policyTypes: [Egress]\n",
+		},
+		"v1alpha1 unsupported template kind": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  objectTemplates:\n  - template:\n      apiVersion: v1\n      kind: Secret\n",
+			expected: "only NetworkPolicy, RoleBinding and PodDisruptionBudget objects are supported",
+		},
+		"v1alpha1 template without kind": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  objectTemplates:\n  - template:\n      spec: {}\n",
+			expected: "spec.objectTemplates[0]",
+		},
+		"v1alpha1 named template": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  objectTemplates:\n  - template:\n      apiVersion: policy/v1\n      kind: PodDisruptionBudget\n      metadata:\n        name: pdb\n",
+			expected: "the name of the object is set by the operator",
+		},
+		"v1alpha1 valid budget only": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchName: dev\n  budget:\n    hard:\n      requests.cpu: \"10\"\n    strategy: Weighted\n    weightLabel: quota.dev.operator/weight\n",
+		},
+		"v1alpha1 valid aggregateHard only": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchName: dev\n  aggregateHard:\n    requests.cpu: \"100\"\n",
+		},
+		"v1alpha1 empty aggregateHard only": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  aggregateHard: {}\n",
+			expected: "aggregateHard must be set",
+		},
+		"v1alpha1 empty budget": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  budget:\n    hard: {}\n",
+			expected: "hard must not be empty",
+		},
+		"v1alpha1 weighted budget without weight label": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  budget:\n    hard:\n      requests.cpu: \"10\"\n    strategy: Weighted\n",
+			expected: "weightLabel must be set for the Weighted strategy",
+		},
+		"v1alpha1 budget allocations without Fixed strategy": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  budget:\n    hard:\n      requests.cpu: \"10\"\n    allocations:\n      team-a:\n        requests.cpu: \"4\"\n",
+			expected: "allocations can only be set for the Fixed strategy",
+		},
+		"v1alpha1 recommendation headroom above maximum": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  resourceQuotaSpecs:\n  - hard:\n      pods: \"10\"\n  recommendation:\n    headroomPercent: 1001\n",
+			expected: "spec.recommendation.headroomPercent",
+		},
+		"v1alpha1 quotaRequests with an empty ceiling": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  quotaRequests:\n    ceiling: {}\n" + v1alpha1Quota,
+			expected: "ceiling must not be empty",
+		},
+		"v1alpha1 valid conflictPolicy": {
+			version: "v1alpha1",
+			spec:    "  namespaceSelector:\n    matchName: dev\n  conflictPolicy: Reject" + v1alpha1Quota,
+		},
+		"v1alpha1 unknown conflictPolicy": {
+			version:  "v1alpha1",
+			spec:     "  namespaceSelector:\n    matchName: dev\n  conflictPolicy: Ignore" + v1alpha1Quota,
+			expected: "spec.conflictPolicy",
+		},
+		"v1beta1 valid labelSelector": {
+			version: "v1beta1",
+			spec:    "  namespaceSelector:\n    labelSelector:\n      matchLabels:\n        environment: dev" + v1beta1Quota,
+		},
+		"v1beta1 no selector": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector: {}" + v1beta1Quota,
+			expected: "one of namespaceSelector.name, namespaceSelector.labelSelector, namespaceSelector.annotations or namespaceSelector.namePrefix must be set",
+		},
+		"v1beta1 both selectors": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n    labelSelector:\n      matchLabels:\n        environment: dev" + v1beta1Quota,
+			expected: "only one of namespaceSelector.name or namespaceSelector.labelSelector can be set",
+		},
+		"v1beta1 valid annotations": {
+			version: "v1beta1",
+			spec:    "  namespaceSelector:\n    annotations:\n      cost-center: \"42\"" + v1beta1Quota,
+		},
+		"v1beta1 name with namePrefix": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n    namePrefix: de" + v1beta1Quota,
+			expected: "namespaceSelector.name cannot be combined with annotations, namePrefix or minAge",
+		},
+		"v1beta1 valid labels and expressions": {
+			version: "v1beta1",
+			spec:    "  namespaceSelector:\n    labelSelector:\n      matchLabels:\n        environment: dev\n        team: a\n      matchExpressions:\n      - key: tier\n        operator: In\n        values: [gold, silver]" + v1beta1Quota,
+		},
+		"v1beta1 empty labelSelector": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    labelSelector: {}" + v1beta1Quota,
+			expected: "labelSelector must contain at least one of matchLabels or matchExpressions",
+		},
+		"v1beta1 precedence out of range": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n  precedence: -1" + v1beta1Quota,
+			expected: "spec.precedence",
+		},
+		"v1beta1 unknown conflictPolicy": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n  conflictPolicy: Ignore" + v1beta1Quota,
+			expected: "spec.conflictPolicy",
+		},
+		"v1beta1 no specs": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n",
+			expected: "at least one of resourceQuotas, limitRanges, objects, budget or aggregateHard must be set",
+		},
+		"v1beta1 empty ResourceQuota spec": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n  resourceQuotas:\n  - name: compute\n",
+			expected: "every resourceQuotas entry must set hard limits",
+		},
+		"v1beta1 valid RoleBinding object": {
+			version: "v1beta1",
+			spec:    "  namespaceSelector:\n    name: dev\n  objects:\n  - name: viewers\n    template:\n      apiVersion: rbac.authorization.k8s.io/v1\n      kind: RoleBinding\n      roleRef:\n        apiGroup: rbac.authorization.k8s.io\n        kind: ClusterRole\n        name: view\n      subjects:\n      - kind: Group\n        name: team-a\n",
+		},
+		"v1beta1 unsupported object kind": {
+			version:  "v1beta1",
+			spec:     "  namespaceSelector:\n    name: dev\n  objects:\n  - name: secret\n    template:\n      apiVersion: v1\n      kind: Secret\n",
+			expected: "only NetworkPolicy, RoleBinding and PodDisruptionBudget objects are supported",
+		},
+		"v1beta1 valid fixed budget": {
+			version: "v1beta1",
+			spec:    "  namespaceSelector:\n    name: dev\n  budget:\n    hard:\n      requests.cpu: \"10\"\n    strategy: Fixed\n    allocations:\n      team-a:\n        requests.cpu: \"4\"\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			errs := validateSpec(t, v, tc.version, tc.spec)
+			if tc.expected == "" {
+				if len(errs) > 0 {
+					t.Fatalf("expected the spec to be valid, got %v", errs.ToAggregate())
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs.ToAggregate().Error(), tc.expected) {
+				t.Fatalf("expected an error containing %q, got %v", tc.expected, errs.ToAggregate())
+			}
+		})
+	}
+}
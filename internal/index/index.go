@@ -0,0 +1,391 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package index defines the field indexes of the manager cache, so that the controllers and
+// webhooks can look up the QuotaProfiles of a namespace without scanning every object.
+package index
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+const (
+	// QuotaProfileMatchNameField indexes QuotaProfiles by the namespace name they select
+	QuotaProfileMatchNameField = "spec.namespaceSelector.matchName"
+
+	// QuotaProfileSelectorLabelKeyField indexes QuotaProfiles by the label keys a namespace must have to be selected
+	QuotaProfileSelectorLabelKeyField = "spec.namespaceSelector.matchLabels.key"
+
+	// QuotaProfileSelectorAnnotationKeyField indexes QuotaProfiles by the annotation keys they select namespaces by
+	QuotaProfileSelectorAnnotationKeyField = "spec.namespaceSelector.matchAnnotations.key"
+
+	// QuotaProfileMatchNamePrefixField indexes QuotaProfiles by the namespace name prefix they select
+	QuotaProfileMatchNamePrefixField = "spec.namespaceSelector.matchNamePrefix"
+
+	// AnyLabelKey is the QuotaProfileSelectorLabelKeyField index value of profiles whose label expressions also
+	// select namespaces without the labels, i.e. only use the NotIn and DoesNotExist operators
+	AnyLabelKey = "*"
+
+	// NamespaceLabelField indexes Namespaces by their labels as key=value pairs, which covers both
+	// the QuotaProfileLabelKey binding and the labels QuotaProfiles select namespaces by
+	NamespaceLabelField = "metadata.labels"
+)
+
+// QuotaProfileMatchName extracts the value of the QuotaProfileMatchNameField index.
+func QuotaProfileMatchName(obj client.Object) []string {
+	quotaProfile, ok := obj.(*quotav1alpha1.QuotaProfile)
+	if !ok || quotaProfile.Spec.NamespaceSelector.MatchName == nil {
+		return nil
+	}
+	return []string{*quotaProfile.Spec.NamespaceSelector.MatchName}
+}
+
+// QuotaProfileSelectorLabelKeys extracts the values of the QuotaProfileSelectorLabelKeyField index, the keys of
+// matchLabels and of the In and Exists expressions.
+func QuotaProfileSelectorLabelKeys(obj client.Object) []string {
+	quotaProfile, ok := obj.(*quotav1alpha1.QuotaProfile)
+	if !ok {
+		return nil
+	}
+	selector := quotaProfile.Spec.NamespaceSelector
+	keys := lo.Keys(selector.MatchLabels)
+	for _, requirement := range selector.MatchExpressions {
+		if requirement.Operator == metav1.LabelSelectorOpIn || requirement.Operator == metav1.LabelSelectorOpExists {
+			keys = append(keys, requirement.Key)
+		}
+	}
+	if len(keys) == 0 && len(selector.MatchExpressions) > 0 {
+		return []string{AnyLabelKey}
+	}
+	return lo.Uniq(keys)
+}
+
+// QuotaProfileSelectorAnnotationKeys extracts the values of the QuotaProfileSelectorAnnotationKeyField index.
+func QuotaProfileSelectorAnnotationKeys(obj client.Object) []string {
+	quotaProfile, ok := obj.(*quotav1alpha1.QuotaProfile)
+	if !ok {
+		return nil
+	}
+	return lo.Keys(quotaProfile.Spec.NamespaceSelector.MatchAnnotations)
+}
+
+// QuotaProfileMatchNamePrefix extracts the value of the QuotaProfileMatchNamePrefixField index.
+func QuotaProfileMatchNamePrefix(obj client.Object) []string {
+	quotaProfile, ok := obj.(*quotav1alpha1.QuotaProfile)
+	if !ok || quotaProfile.Spec.NamespaceSelector.MatchNamePrefix == nil {
+		return nil
+	}
+	return []string{*quotaProfile.Spec.NamespaceSelector.MatchNamePrefix}
+}
+
+// NamespaceLabels extracts the values of the NamespaceLabelField index.
+func NamespaceLabels(obj client.Object) []string {
+	return lo.MapToSlice(obj.GetLabels(), func(key, value string) string {
+		return Label(key, value)
+	})
+}
+
+// Label returns the NamespaceLabelField index value of a label.
+func Label(key, value string) string {
+	return key + "=" + value
+}
+
+// SetupIndexes registers all indexes with the field indexer of the manager.
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &quotav1alpha1.QuotaProfile{}, QuotaProfileMatchNameField, QuotaProfileMatchName); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &quotav1alpha1.QuotaProfile{}, QuotaProfileSelectorLabelKeyField, QuotaProfileSelectorLabelKeys); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &quotav1alpha1.QuotaProfile{}, QuotaProfileSelectorAnnotationKeyField, QuotaProfileSelectorAnnotationKeys); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &quotav1alpha1.QuotaProfile{}, QuotaProfileMatchNamePrefixField, QuotaProfileMatchNamePrefix); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &v1.Namespace{}, NamespaceLabelField, NamespaceLabels)
+}
+
+// NamespacesWithLabel returns the namespaces that have the label.
+func NamespacesWithLabel(ctx context.Context, c client.Reader, key, value string) ([]v1.Namespace, error) {
+	nsList := &v1.NamespaceList{}
+	if err := c.List(ctx, nsList, client.MatchingFields{NamespaceLabelField: Label(key, value)}); err != nil {
+		return nil, err
+	}
+	return nsList.Items, nil
+}
+
+// QuotaProfilesForNamespace returns the QuotaProfiles that may select the namespace, i.e. the ones selecting it by
+// name, by one of its label or annotation keys, by label expressions that also select namespaces without the
+// labels or by a prefix of its name, ordered by creation time. Callers still
+// have to check the selectors with SelectorMismatch.
+func QuotaProfilesForNamespace(ctx context.Context, c client.Reader, ns *v1.Namespace) ([]quotav1alpha1.QuotaProfile, error) {
+	byName := &quotav1alpha1.QuotaProfileList{}
+	if err := c.List(ctx, byName, client.MatchingFields{QuotaProfileMatchNameField: ns.Name}); err != nil {
+		return nil, err
+	}
+	quotaProfiles := byName.Items
+
+	lookups := make([]client.MatchingFields, 0, len(ns.Labels)+len(ns.Annotations)+len(ns.Name)+1)
+	lookups = append(lookups, client.MatchingFields{QuotaProfileSelectorLabelKeyField: AnyLabelKey})
+	for key := range ns.Labels {
+		lookups = append(lookups, client.MatchingFields{QuotaProfileSelectorLabelKeyField: key})
+	}
+	for key := range ns.Annotations {
+		lookups = append(lookups, client.MatchingFields{QuotaProfileSelectorAnnotationKeyField: key})
+	}
+	for i := 1; i <= len(ns.Name); i++ {
+		lookups = append(lookups, client.MatchingFields{QuotaProfileMatchNamePrefixField: ns.Name[:i]})
+	}
+	for _, lookup := range lookups {
+		list := &quotav1alpha1.QuotaProfileList{}
+		if err := c.List(ctx, list, lookup); err != nil {
+			return nil, err
+		}
+		quotaProfiles = append(quotaProfiles, list.Items...)
+	}
+
+	quotaProfiles = lo.UniqBy(quotaProfiles, func(q quotav1alpha1.QuotaProfile) string {
+		return q.Namespace + "/" + q.Name
+	})
+	sort.SliceStable(quotaProfiles, func(i, j int) bool {
+		return quotaProfiles[i].CreationTimestamp.Before(&quotaProfiles[j].CreationTimestamp)
+	})
+	return quotaProfiles, nil
+}
+
+// SelectorMismatch returns why the selector does not select the namespace at the given time, or an empty string
+// when it does. Namespaces that are being created have no creation timestamp yet and are treated as brand new.
+func SelectorMismatch(selector quotav1alpha1.NamespaceSelector, ns *v1.Namespace, now time.Time) string {
+	if selector.MatchName != nil {
+		if ns.Name != *selector.MatchName {
+			return fmt.Sprintf("name is not %s", *selector.MatchName)
+		}
+		return ""
+	}
+
+	for _, key := range sortedKeys(selector.MatchLabels) {
+		value, ok := ns.Labels[key]
+		if !ok {
+			return fmt.Sprintf("label %s is missing", key)
+		}
+		if value != selector.MatchLabels[key] {
+			return fmt.Sprintf("label %s is %q", key, value)
+		}
+	}
+	for _, requirement := range selector.MatchExpressions {
+		if reason := RequirementMismatch(requirement, ns.Labels); reason != "" {
+			return reason
+		}
+	}
+	for _, key := range sortedKeys(selector.MatchAnnotations) {
+		value, ok := ns.Annotations[key]
+		if !ok {
+			return fmt.Sprintf("annotation %s is missing", key)
+		}
+		if value != selector.MatchAnnotations[key] {
+			return fmt.Sprintf("annotation %s is %q", key, value)
+		}
+	}
+	if selector.MatchNamePrefix != nil && !strings.HasPrefix(ns.Name, *selector.MatchNamePrefix) {
+		return fmt.Sprintf("name does not start with %s", *selector.MatchNamePrefix)
+	}
+	if remaining := UntilMinAge(selector, ns, now); remaining > 0 {
+		return fmt.Sprintf("namespace is younger than %s", selector.MinAge.Duration)
+	}
+	return ""
+}
+
+// RequirementMismatch returns why the labels do not meet the label selector requirement, or an empty string when
+// they do.
+func RequirementMismatch(requirement metav1.LabelSelectorRequirement, labels map[string]string) string {
+	value, ok := labels[requirement.Key]
+	switch requirement.Operator {
+	case metav1.LabelSelectorOpIn:
+		if !ok {
+			return fmt.Sprintf("label %s is missing", requirement.Key)
+		}
+		if !lo.Contains(requirement.Values, value) {
+			return fmt.Sprintf("label %s is %q", requirement.Key, value)
+		}
+	case metav1.LabelSelectorOpNotIn:
+		if ok && lo.Contains(requirement.Values, value) {
+			return fmt.Sprintf("label %s is %q", requirement.Key, value)
+		}
+	case metav1.LabelSelectorOpExists:
+		if !ok {
+			return fmt.Sprintf("label %s is missing", requirement.Key)
+		}
+	case metav1.LabelSelectorOpDoesNotExist:
+		if ok {
+			return fmt.Sprintf("label %s is set", requirement.Key)
+		}
+	default:
+		return fmt.Sprintf("label %s has the unsupported operator %s", requirement.Key, requirement.Operator)
+	}
+	return ""
+}
+
+// UntilMinAge returns how long it takes until the namespace reaches the minimum age of the selector, zero when
+// it already has or the selector has none.
+func UntilMinAge(selector quotav1alpha1.NamespaceSelector, ns *v1.Namespace, now time.Time) time.Duration {
+	if selector.MinAge == nil {
+		return 0
+	}
+	created := ns.CreationTimestamp.Time
+	if created.IsZero() {
+		created = now
+	}
+	return max(created.Add(selector.MinAge.Duration).Sub(now), 0)
+}
+
+// SelectorString formats a namespace selector, e.g. "name=team-a" or "label team=a, annotation owner=alice".
+func SelectorString(selector quotav1alpha1.NamespaceSelector) string {
+	if selector.MatchName != nil {
+		return "name=" + *selector.MatchName
+	}
+
+	var parts []string
+	for _, key := range sortedKeys(selector.MatchLabels) {
+		parts = append(parts, fmt.Sprintf("label %s=%s", key, selector.MatchLabels[key]))
+	}
+	for _, requirement := range selector.MatchExpressions {
+		parts = append(parts, "label "+RequirementString(requirement))
+	}
+	for _, key := range sortedKeys(selector.MatchAnnotations) {
+		parts = append(parts, fmt.Sprintf("annotation %s=%s", key, selector.MatchAnnotations[key]))
+	}
+	if selector.MatchNamePrefix != nil {
+		parts = append(parts, "name prefix "+*selector.MatchNamePrefix)
+	}
+	if selector.MinAge != nil {
+		parts = append(parts, "min age "+selector.MinAge.Duration.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RequirementString formats a label selector requirement like a label selector string, e.g. "tier in (a,b)",
+// "tier notin (c)", "tier" or "!tier".
+func RequirementString(requirement metav1.LabelSelectorRequirement) string {
+	switch requirement.Operator {
+	case metav1.LabelSelectorOpExists:
+		return requirement.Key
+	case metav1.LabelSelectorOpDoesNotExist:
+		return "!" + requirement.Key
+	default:
+		return fmt.Sprintf("%s %s (%s)", requirement.Key, strings.ToLower(string(requirement.Operator)), strings.Join(requirement.Values, ","))
+	}
+}
+
+// SelectorsOverlap reports whether a namespace can exist that both selectors select, ignoring their minimum age.
+// Name selectors never overlap with other selectors as they always take precedence.
+func SelectorsOverlap(a, b quotav1alpha1.NamespaceSelector) bool {
+	if a.MatchName != nil || b.MatchName != nil {
+		return a.MatchName != nil && b.MatchName != nil && *a.MatchName == *b.MatchName
+	}
+
+	compatible := func(x, y map[string]string) bool {
+		for key, value := range x {
+			if other, ok := y[key]; ok && other != value {
+				return false
+			}
+		}
+		return true
+	}
+	if !labelsCompatible(a, b) || !compatible(a.MatchAnnotations, b.MatchAnnotations) {
+		return false
+	}
+	if a.MatchNamePrefix != nil && b.MatchNamePrefix != nil {
+		return strings.HasPrefix(*a.MatchNamePrefix, *b.MatchNamePrefix) || strings.HasPrefix(*b.MatchNamePrefix, *a.MatchNamePrefix)
+	}
+	return true
+}
+
+// labelConstraint is what the labels and label expressions of selectors require of one label key.
+type labelConstraint struct {
+	// allowed are the values the label may have, nil when any value is allowed
+	allowed   []string
+	forbidden []string
+	exists    bool
+	absent    bool
+}
+
+// labelsCompatible reports whether a namespace can have labels that match the labels and label expressions of
+// both selectors.
+func labelsCompatible(a, b quotav1alpha1.NamespaceSelector) bool {
+	constraints := map[string]*labelConstraint{}
+	constraint := func(key string) *labelConstraint {
+		if constraints[key] == nil {
+			constraints[key] = &labelConstraint{}
+		}
+		return constraints[key]
+	}
+	allow := func(c *labelConstraint, values []string) {
+		if c.allowed == nil {
+			c.allowed = append([]string{}, values...)
+		} else {
+			c.allowed = lo.Intersect(c.allowed, values)
+		}
+		c.exists = true
+	}
+
+	for _, selector := range []quotav1alpha1.NamespaceSelector{a, b} {
+		for key, value := range selector.MatchLabels {
+			allow(constraint(key), []string{value})
+		}
+		for _, requirement := range selector.MatchExpressions {
+			c := constraint(requirement.Key)
+			switch requirement.Operator {
+			case metav1.LabelSelectorOpIn:
+				allow(c, requirement.Values)
+			case metav1.LabelSelectorOpNotIn:
+				c.forbidden = append(c.forbidden, requirement.Values...)
+			case metav1.LabelSelectorOpExists:
+				c.exists = true
+			case metav1.LabelSelectorOpDoesNotExist:
+				c.absent = true
+			}
+		}
+	}
+
+	for _, c := range constraints {
+		if c.exists && c.absent {
+			return false
+		}
+		if c.allowed != nil && len(lo.Without(c.allowed, c.forbidden...)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := lo.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,478 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+const (
+	benchmarkNamespaces = 10000
+	benchmarkProfiles   = 500
+)
+
+// indexedReader is a client.Reader backed by the same thread safe indexers the informers of the
+// manager cache use, the fake client evaluates field selectors by scanning every object instead.
+type indexedReader struct {
+	stores map[reflect.Type]toolscache.Indexer
+}
+
+func newIndexedReader() *indexedReader {
+	indexers := func(fields map[string]client.IndexerFunc) toolscache.Indexers {
+		result := toolscache.Indexers{}
+		for field, extract := range fields {
+			result[field] = func(obj interface{}) ([]string, error) {
+				return extract(obj.(client.Object)), nil
+			}
+		}
+		return result
+	}
+
+	return &indexedReader{stores: map[reflect.Type]toolscache.Indexer{
+		reflect.TypeOf(&quotav1alpha1.QuotaProfileList{}): toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, indexers(map[string]client.IndexerFunc{
+			QuotaProfileMatchNameField:             QuotaProfileMatchName,
+			QuotaProfileSelectorLabelKeyField:      QuotaProfileSelectorLabelKeys,
+			QuotaProfileSelectorAnnotationKeyField: QuotaProfileSelectorAnnotationKeys,
+			QuotaProfileMatchNamePrefixField:       QuotaProfileMatchNamePrefix,
+		})),
+		reflect.TypeOf(&v1.NamespaceList{}): toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, indexers(map[string]client.IndexerFunc{
+			NamespaceLabelField: NamespaceLabels,
+		})),
+	}}
+}
+
+func (r *indexedReader) add(list client.ObjectList, obj client.Object) {
+	if err := r.stores[reflect.TypeOf(list)].Add(obj); err != nil {
+		panic(err)
+	}
+}
+
+func (r *indexedReader) Get(_ context.Context, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *indexedReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	store := r.stores[reflect.TypeOf(list)]
+	objs := store.List()
+	if listOpts.FieldSelector != nil {
+		requirement := listOpts.FieldSelector.Requirements()[0]
+		var err error
+		if objs, err = store.ByIndex(requirement.Field, requirement.Value); err != nil {
+			return err
+		}
+	}
+
+	items := make([]runtime.Object, 0, len(objs))
+	for _, obj := range objs {
+		items = append(items, obj.(runtime.Object))
+	}
+	return meta.SetList(list, items)
+}
+
+// newBenchmarkReader returns a reader with half of the profiles selecting a namespace by name and
+// the other half selecting namespaces by a team label, with every namespace bound to one profile.
+func newBenchmarkReader() *indexedReader {
+	r := newIndexedReader()
+
+	for i := 0; i < benchmarkProfiles; i++ {
+		q := &quotav1alpha1.QuotaProfile{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("profile-%d", i), Namespace: "quota-system"}}
+		if i%2 == 0 {
+			name := fmt.Sprintf("ns-%d", i)
+			q.Spec.NamespaceSelector.MatchName = &name
+		} else {
+			q.Spec.NamespaceSelector.MatchLabels = map[string]string{fmt.Sprintf("team-%d", i): "true"}
+		}
+		r.add(&quotav1alpha1.QuotaProfileList{}, q)
+	}
+
+	for i := 0; i < benchmarkNamespaces; i++ {
+		r.add(&v1.NamespaceList{}, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("ns-%d", i),
+			Labels: map[string]string{
+				"kubernetes.io/metadata.name":                   fmt.Sprintf("ns-%d", i),
+				fmt.Sprintf("team-%d", (i%benchmarkProfiles)|1): "true",
+				quotav1alpha1.QuotaProfileLabelKey:              fmt.Sprintf("quota-system.profile-%d", (i%benchmarkProfiles)|1),
+			},
+		}})
+	}
+	return r
+}
+
+// scanQuotaProfilesForNamespace is the lookup the namespace defaulter did before the indexes, listing every profile.
+func scanQuotaProfilesForNamespace(ctx context.Context, c client.Reader, ns *v1.Namespace) ([]quotav1alpha1.QuotaProfile, error) {
+	quotaProfiles := &quotav1alpha1.QuotaProfileList{}
+	if err := c.List(ctx, quotaProfiles); err != nil {
+		return nil, err
+	}
+
+	var result []quotav1alpha1.QuotaProfile
+	for _, q := range quotaProfiles.Items {
+		if q.Spec.NamespaceSelector.MatchName != nil && *q.Spec.NamespaceSelector.MatchName == ns.Name {
+			result = append(result, q)
+			continue
+		}
+		for key := range q.Spec.NamespaceSelector.MatchLabels {
+			if _, ok := ns.Labels[key]; ok {
+				result = append(result, q)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// scanNamespacesWithLabel is the lookup the QuotaProfile controller did before the indexes, listing every namespace.
+func scanNamespacesWithLabel(ctx context.Context, c client.Reader, key, value string) ([]v1.Namespace, error) {
+	nsList := &v1.NamespaceList{}
+	if err := c.List(ctx, nsList); err != nil {
+		return nil, err
+	}
+
+	var result []v1.Namespace
+	for _, ns := range nsList.Items {
+		if ns.Labels[key] == value {
+			result = append(result, ns)
+		}
+	}
+	return result, nil
+}
+
+func TestIndexedLookupsMatchFullScan(t *testing.T) {
+	ctx := context.Background()
+	r := newBenchmarkReader()
+
+	for _, name := range []string{"ns-0", "ns-1", "ns-42", "ns-9999"} {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		objs, _ := r.stores[reflect.TypeOf(&v1.NamespaceList{})].ByIndex(NamespaceLabelField, Label("kubernetes.io/metadata.name", name))
+		ns.Labels = objs[0].(*v1.Namespace).Labels
+
+		indexed, err := QuotaProfilesForNamespace(ctx, r, ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanned, err := scanQuotaProfilesForNamespace(ctx, r, ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(indexed) != len(scanned) || len(indexed) == 0 {
+			t.Errorf("namespace %s: indexed lookup found %d profiles, full scan found %d", name, len(indexed), len(scanned))
+		}
+	}
+
+	indexed, err := NamespacesWithLabel(ctx, r, quotav1alpha1.QuotaProfileLabelKey, "quota-system.profile-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanned, err := scanNamespacesWithLabel(ctx, r, quotav1alpha1.QuotaProfileLabelKey, "quota-system.profile-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexed) != len(scanned) || len(indexed) == 0 {
+		t.Errorf("indexed lookup found %d namespaces, full scan found %d", len(indexed), len(scanned))
+	}
+}
+
+func TestQuotaProfilesForNamespaceByAnnotationAndNamePrefix(t *testing.T) {
+	ctx := context.Background()
+	r := newIndexedReader()
+
+	for _, q := range []*quotav1alpha1.QuotaProfile{
+		{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "quota-system"},
+			Spec: quotav1alpha1.QuotaProfileSpec{NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"owner": "alice"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "teams", Namespace: "quota-system"},
+			Spec: quotav1alpha1.QuotaProfileSpec{NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-")}}},
+	} {
+		r.add(&quotav1alpha1.QuotaProfileList{}, q)
+	}
+
+	for name, tc := range map[string]struct {
+		ns       *v1.Namespace
+		expected []string
+	}{
+		"annotation and prefix": {
+			ns:       &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{"owner": "bob"}}},
+			expected: []string{"owner", "teams"},
+		},
+		"prefix only": {
+			ns:       &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			expected: []string{"teams"},
+		},
+		"neither": {
+			ns: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tea"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			quotaProfiles, err := QuotaProfilesForNamespace(ctx, r, tc.ns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, q := range quotaProfiles {
+				names = append(names, q.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("expected candidates %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestQuotaProfilesForNamespaceByLabelExpressions(t *testing.T) {
+	ctx := context.Background()
+	r := newIndexedReader()
+
+	expressions := func(requirements ...metav1.LabelSelectorRequirement) quotav1alpha1.QuotaProfileSpec {
+		return quotav1alpha1.QuotaProfileSpec{NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchExpressions: requirements}}
+	}
+	for _, q := range []*quotav1alpha1.QuotaProfile{
+		{ObjectMeta: metav1.ObjectMeta{Name: "tiers", Namespace: "quota-system"},
+			Spec: expressions(metav1.LabelSelectorRequirement{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"gold", "silver"}})},
+		{ObjectMeta: metav1.ObjectMeta{Name: "untiered", Namespace: "quota-system"},
+			Spec: expressions(metav1.LabelSelectorRequirement{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist})},
+	} {
+		r.add(&quotav1alpha1.QuotaProfileList{}, q)
+	}
+
+	for name, tc := range map[string]struct {
+		ns       *v1.Namespace
+		expected []string
+	}{
+		"with the label": {
+			ns:       &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tier": "gold"}}},
+			expected: []string{"tiers", "untiered"},
+		},
+		"without labels": {
+			ns:       &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			expected: []string{"untiered"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			quotaProfiles, err := QuotaProfilesForNamespace(ctx, r, tc.ns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := lo.Map(quotaProfiles, func(q quotav1alpha1.QuotaProfile, _ int) string { return q.Name })
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("expected candidates %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestSelectorMismatch(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:              "team-a",
+		Labels:            map[string]string{"environment": "dev"},
+		Annotations:       map[string]string{"owner": "alice", "cost-center": "42"},
+		CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+	}}
+	age := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+
+	for name, tc := range map[string]struct {
+		selector quotav1alpha1.NamespaceSelector
+		expected string
+	}{
+		"label and annotations": {
+			selector: quotav1alpha1.NamespaceSelector{
+				MatchLabels:      map[string]string{"environment": "dev"},
+				MatchAnnotations: map[string]string{"owner": "alice", "cost-center": "42"},
+			},
+		},
+		"wrong annotation value": {
+			selector: quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"owner": "bob"}},
+			expected: `annotation owner is "alice"`,
+		},
+		"missing annotation": {
+			selector: quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"team": "a"}},
+			expected: "annotation team is missing",
+		},
+		"prefix and age": {
+			selector: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-"), MinAge: age(time.Hour)},
+		},
+		"other prefix": {
+			selector: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("platform-")},
+			expected: "name does not start with platform-",
+		},
+		"too young": {
+			selector: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-"), MinAge: age(3 * time.Hour)},
+			expected: "namespace is younger than 3h0m0s",
+		},
+		"label expressions": {
+			selector: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "environment", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev", "test"}},
+				{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+		},
+		"label not in values": {
+			selector: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "environment", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"dev"}},
+			}},
+			expected: `label environment is "dev"`,
+		},
+		"label does not exist": {
+			selector: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "environment", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			expected: "label environment is set",
+		},
+		"label exists": {
+			selector: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+			}},
+			expected: "label tier is missing",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if reason := SelectorMismatch(tc.selector, ns, now); reason != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, reason)
+			}
+		})
+	}
+
+	if wait := UntilMinAge(quotav1alpha1.NamespaceSelector{MinAge: age(3 * time.Hour)}, ns, now); wait != time.Hour {
+		t.Errorf("expected the namespace to reach the minimum age in 1h, got %s", wait)
+	}
+}
+
+func TestSelectorsOverlap(t *testing.T) {
+	for name, tc := range map[string]struct {
+		a, b     quotav1alpha1.NamespaceSelector
+		expected bool
+	}{
+		"label and annotation": {
+			a:        quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+			b:        quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"owner": "alice"}},
+			expected: true,
+		},
+		"different annotation values": {
+			a: quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"owner": "alice"}},
+			b: quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"owner": "bob"}},
+		},
+		"nested prefixes": {
+			a:        quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-")},
+			b:        quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-a-")},
+			expected: true,
+		},
+		"disjoint prefixes": {
+			a: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-")},
+			b: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("platform-")},
+		},
+		"name and prefix": {
+			a: quotav1alpha1.NamespaceSelector{MatchName: lo.ToPtr("team-a")},
+			b: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-")},
+		},
+		"label in values": {
+			a:        quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"tier": "gold"}},
+			b:        quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"gold", "silver"}}}},
+			expected: true,
+		},
+		"disjoint values": {
+			a: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"bronze"}}}},
+			b: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"gold", "silver"}}}},
+		},
+		"label not in values": {
+			a: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"tier": "gold"}},
+			b: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"gold"}}}},
+		},
+		"label exists and does not exist": {
+			a: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpExists}}},
+			b: quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist}}},
+		},
+		"different label keys": {
+			a:        quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist}}},
+			b:        quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a", "environment": "dev"}},
+			expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if overlap := SelectorsOverlap(tc.a, tc.b); overlap != tc.expected {
+				t.Errorf("expected overlap %t, got %t", tc.expected, overlap)
+			}
+		})
+	}
+}
+
+// BenchmarkQuotaProfilesForNamespace measures the QuotaProfile lookup of the namespace defaulter and the
+// namespace watch of the QuotaProfile controller, with 10k namespaces and 500 profiles.
+func BenchmarkQuotaProfilesForNamespace(b *testing.B) {
+	ctx := context.Background()
+	r := newBenchmarkReader()
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-42",
+		Labels: map[string]string{"kubernetes.io/metadata.name": "ns-42", "team-43": "true"},
+	}}
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := QuotaProfilesForNamespace(ctx, r, ns); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("full-scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := scanQuotaProfilesForNamespace(ctx, r, ns); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkNamespacesWithLabel measures the namespace lookup of the QuotaProfile controller when reconciling,
+// rolling out or deleting a profile, with 10k namespaces and 500 profiles.
+func BenchmarkNamespacesWithLabel(b *testing.B) {
+	ctx := context.Background()
+	r := newBenchmarkReader()
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NamespacesWithLabel(ctx, r, quotav1alpha1.QuotaProfileLabelKey, "quota-system.profile-1"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("full-scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := scanNamespacesWithLabel(ctx, r, quotav1alpha1.QuotaProfileLabelKey, "quota-system.profile-1"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// Decision is the outcome of ranking the QuotaProfiles that select a namespace.
+type Decision struct {
+	// Winner is the profile the namespace is bound to, nil when no profile selects it
+	Winner *quotav1alpha1.QuotaProfile
+
+	// Trace explains for every other profile selecting the namespace which rule decided for the winner
+	Trace []string
+}
+
+// Decide returns the profile out of the candidates that the namespace is bound to at the given time. Profiles
+// that are being deleted or do not select the namespace are skipped, the others are ranked with Compare, so the
+// result does not depend on the order of the candidates.
+func Decide(candidates []quotav1alpha1.QuotaProfile, ns *v1.Namespace, now time.Time) Decision {
+	var selecting []*quotav1alpha1.QuotaProfile
+	for i := range candidates {
+		q := &candidates[i]
+		if q.DeletionTimestamp != nil || SelectorMismatch(q.Spec.NamespaceSelector, ns, now) != "" {
+			continue
+		}
+		selecting = append(selecting, q)
+	}
+	if len(selecting) == 0 {
+		return Decision{}
+	}
+
+	sort.Slice(selecting, func(i, j int) bool {
+		result, _ := Compare(selecting[i], selecting[j])
+		return result > 0
+	})
+	decision := Decision{Winner: selecting[0]}
+	for _, q := range selecting[1:] {
+		_, rule := Compare(decision.Winner, q)
+		decision.Trace = append(decision.Trace, fmt.Sprintf("%s over %s: %s", ProfileRef(decision.Winner), ProfileRef(q), rule))
+	}
+	return decision
+}
+
+// Compare ranks two profiles selecting the same namespace. It returns a positive number when a wins and a
+// negative number when b wins, together with the rule that decided, in this order:
+//
+//  1. a selector by exact name wins over all other selectors
+//  2. a selector by name prefix wins over selectors without one, the longer prefix wins between two
+//  3. the selector with more label and annotation terms wins, every label expression is a label term
+//  4. the higher precedence wins
+//  5. the lexically smaller <namespace>/<name> wins
+//
+// Only profiles that are the same object compare as equal.
+func Compare(a, b *quotav1alpha1.QuotaProfile) (int, string) {
+	sa, sb := a.Spec.NamespaceSelector, b.Spec.NamespaceSelector
+
+	if result := cmp.Compare(boolRank(sa.MatchName != nil), boolRank(sb.MatchName != nil)); result != 0 {
+		return result, "selects the namespace by exact name"
+	}
+	if result := cmp.Compare(prefixLength(sa), prefixLength(sb)); result != 0 {
+		winner, loser := ordered(result, sa, sb)
+		if loser.MatchNamePrefix == nil {
+			return result, fmt.Sprintf("selects the namespace by name prefix %s", *winner.MatchNamePrefix)
+		}
+		return result, fmt.Sprintf("name prefix %s is longer than %s", *winner.MatchNamePrefix, *loser.MatchNamePrefix)
+	}
+	if result := cmp.Compare(labelTerms(sa), labelTerms(sb)); result != 0 {
+		winner, loser := ordered(result, sa, sb)
+		return result, fmt.Sprintf("more label and annotation terms (%d over %d)", labelTerms(winner), labelTerms(loser))
+	}
+	if result := cmp.Compare(a.Spec.Precedence, b.Spec.Precedence); result != 0 {
+		winner, loser := a, b
+		if result < 0 {
+			winner, loser = b, a
+		}
+		return result, fmt.Sprintf("higher precedence (%d over %d)", winner.Spec.Precedence, loser.Spec.Precedence)
+	}
+	if result := cmp.Compare(ProfileRef(b), ProfileRef(a)); result != 0 {
+		return result, "same specificity and precedence, the lexically smaller name wins"
+	}
+	return 0, ""
+}
+
+// ProfileRef returns the <namespace>/<name> reference of a profile.
+func ProfileRef(q *quotav1alpha1.QuotaProfile) string {
+	return q.Namespace + "/" + q.Name
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func prefixLength(selector quotav1alpha1.NamespaceSelector) int {
+	if selector.MatchNamePrefix == nil {
+		return 0
+	}
+	return len(*selector.MatchNamePrefix)
+}
+
+func labelTerms(selector quotav1alpha1.NamespaceSelector) int {
+	return len(selector.MatchLabels) + len(selector.MatchExpressions) + len(selector.MatchAnnotations)
+}
+
+// ordered returns the selectors as winner and loser according to the result of a comparison.
+func ordered(result int, a, b quotav1alpha1.NamespaceSelector) (quotav1alpha1.NamespaceSelector, quotav1alpha1.NamespaceSelector) {
+	if result > 0 {
+		return a, b
+	}
+	return b, a
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "team-a-dev",
+		Labels:      map[string]string{"team": "a", "environment": "dev"},
+		Annotations: map[string]string{"owner": "alice"},
+	}}
+
+	profile := func(name string, precedence uint16, created time.Duration, selector quotav1alpha1.NamespaceSelector) quotav1alpha1.QuotaProfile {
+		return quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "quota-system", CreationTimestamp: metav1.NewTime(now.Add(-created))},
+			Spec:       quotav1alpha1.QuotaProfileSpec{NamespaceSelector: selector, Precedence: precedence},
+		}
+	}
+	byLabel := func(key, value string) quotav1alpha1.NamespaceSelector {
+		return quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{key: value}}
+	}
+
+	for name, tc := range map[string]struct {
+		candidates []quotav1alpha1.QuotaProfile
+		winner     string
+		trace      []string
+	}{
+		"exact name wins over a higher precedence": {
+			candidates: []quotav1alpha1.QuotaProfile{
+				profile("by-label", 100, 0, byLabel("team", "a")),
+				profile("by-name", 0, 0, quotav1alpha1.NamespaceSelector{MatchName: lo.ToPtr("team-a-dev")}),
+			},
+			winner: "by-name",
+			trace:  []string{"quota-system/by-name over quota-system/by-label: selects the namespace by exact name"},
+		},
+		"longer name prefix wins": {
+			candidates: []quotav1alpha1.QuotaProfile{
+				profile("team", 0, 0, quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-")}),
+				profile("team-a", 0, 0, quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("team-a-")}),
+				profile("by-label", 100, 0, byLabel("team", "a")),
+			},
+			winner: "team-a",
+			trace: []string{
+				"quota-system/team-a over quota-system/team: name prefix team-a- is longer than team-",
+				"quota-system/team-a over quota-system/by-label: selects the namespace by name prefix team-a-",
+			},
+		},
+		"more label and annotation terms win": {
+			candidates: []quotav1alpha1.QuotaProfile{
+				profile("by-label", 100, 0, byLabel("team", "a")),
+				profile("by-label-and-annotation", 0, 0, quotav1alpha1.NamespaceSelector{
+					MatchLabels:      map[string]string{"team": "a"},
+					MatchAnnotations: map[string]string{"owner": "alice"},
+				}),
+			},
+			winner: "by-label-and-annotation",
+			trace:  []string{"quota-system/by-label-and-annotation over quota-system/by-label: more label and annotation terms (2 over 1)"},
+		},
+		"higher precedence wins": {
+			candidates: []quotav1alpha1.QuotaProfile{
+				profile("dev", 1, 0, byLabel("environment", "dev")),
+				profile("team-a", 5, 0, byLabel("team", "a")),
+			},
+			winner: "team-a",
+			trace:  []string{"quota-system/team-a over quota-system/dev: higher precedence (5 over 1)"},
+		},
+		"lexically smaller name wins regardless of the creation time": {
+			candidates: []quotav1alpha1.QuotaProfile{
+				profile("b", 0, 0, byLabel("environment", "dev")),
+				profile("a", 0, time.Hour, byLabel("team", "a")),
+			},
+			winner: "a",
+			trace:  []string{"quota-system/a over quota-system/b: same specificity and precedence, the lexically smaller name wins"},
+		},
+		"profiles that do not select the namespace are skipped": {
+			candidates: []quotav1alpha1.QuotaProfile{
+				profile("other-team", 100, 0, byLabel("team", "b")),
+				profile("dev", 0, 0, byLabel("environment", "dev")),
+			},
+			winner: "dev",
+		},
+		"no profile selects the namespace": {
+			candidates: []quotav1alpha1.QuotaProfile{profile("other-team", 0, 0, byLabel("team", "b"))},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// the decision must not depend on the order the cache returns the candidates in
+			for _, candidates := range [][]quotav1alpha1.QuotaProfile{tc.candidates, lo.Reverse(slices.Clone(tc.candidates))} {
+				decision := Decide(candidates, ns, now)
+				winner := ""
+				if decision.Winner != nil {
+					winner = decision.Winner.Name
+				}
+				if winner != tc.winner {
+					t.Errorf("expected winner %q, got %q", tc.winner, winner)
+				}
+				if !reflect.DeepEqual(decision.Trace, tc.trace) {
+					t.Errorf("expected trace %v, got %v", tc.trace, decision.Trace)
+				}
+			}
+		})
+	}
+
+	t.Run("profiles being deleted are skipped", func(t *testing.T) {
+		deleting := profile("by-name", 0, 0, quotav1alpha1.NamespaceSelector{MatchName: lo.ToPtr("team-a-dev")})
+		deleting.DeletionTimestamp = lo.ToPtr(metav1.NewTime(now))
+		decision := Decide([]quotav1alpha1.QuotaProfile{deleting, profile("dev", 0, 0, byLabel("environment", "dev"))}, ns, now)
+		if decision.Winner == nil || decision.Winner.Name != "dev" || len(decision.Trace) != 0 {
+			t.Errorf("expected dev to win without a trace, got %+v", decision)
+		}
+	})
+}
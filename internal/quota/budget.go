@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+)
+
+// BudgetShare returns the hard limits of the budget ResourceQuota of the namespace, i.e. its share of the budget
+// of the profile among all namespaces bound to the profile. The reader must support the field indexes of the
+// index package.
+func BudgetShare(ctx context.Context, c client.Reader, q *quotav1alpha1.QuotaProfile, namespace string) (v1.ResourceList, error) {
+	namespaces, err := index.NamespacesWithLabel(ctx, c, quotav1alpha1.QuotaProfileLabelKey, ProfileID(q.Namespace, q.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// terminating namespaces give their share back to the others
+	namespaces = lo.Filter(namespaces, func(ns v1.Namespace, _ int) bool {
+		return ns.DeletionTimestamp == nil || ns.Name == namespace
+	})
+
+	// the namespace may not be in the cache yet, e.g. right after it was bound
+	if !lo.ContainsBy(namespaces, func(ns v1.Namespace) bool { return ns.Name == namespace }) {
+		ns := &v1.Namespace{}
+		if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, *ns)
+	}
+
+	return BudgetShares(q.Spec.Budget, namespaces)[namespace], nil
+}
+
+// BudgetShares splits the budget across the namespaces according to its strategy and returns the share of every
+// namespace by name. The shares of every resource add up to the budget, except for fixed allocations that exceed it.
+func BudgetShares(budget *quotav1alpha1.QuotaBudget, namespaces []v1.Namespace) map[string]v1.ResourceList {
+	// the namespaces are sorted so that the remainders of uneven splits always go to the same namespaces
+	names := lo.Map(namespaces, func(ns v1.Namespace, _ int) string { return ns.Name })
+	sort.Strings(names)
+	labels := lo.SliceToMap(namespaces, func(ns v1.Namespace) (string, map[string]string) { return ns.Name, ns.Labels })
+
+	shares := make(map[string]v1.ResourceList, len(names))
+	for _, name := range names {
+		shares[name] = v1.ResourceList{}
+	}
+
+	for resourceName, hard := range budget.Hard {
+		remaining := hard.DeepCopy()
+		var split []string
+		var weights []int64
+
+		for _, name := range names {
+			switch budget.Strategy {
+			case quotav1alpha1.BudgetStrategyFixed:
+				if allocation, ok := budget.Allocations[name][resourceName]; ok {
+					shares[name][resourceName] = allocation.DeepCopy()
+					remaining.Sub(allocation)
+					continue
+				}
+				split, weights = append(split, name), append(weights, 1)
+			case quotav1alpha1.BudgetStrategyWeighted:
+				split, weights = append(split, name), append(weights, namespaceWeight(labels[name], budget.WeightLabel))
+			default:
+				split, weights = append(split, name), append(weights, 1)
+			}
+		}
+
+		if remaining.Sign() < 0 {
+			remaining = *resource.NewQuantity(0, hard.Format)
+		}
+		for i, share := range splitQuantity(resourceName, remaining, weights) {
+			shares[split[i]][resourceName] = share
+		}
+	}
+
+	return shares
+}
+
+// namespaceWeight returns the weight in the given label of the namespace. Namespaces without a non-negative
+// integer weight have a weight of 1.
+func namespaceWeight(labels map[string]string, key string) int64 {
+	weight, err := strconv.ParseInt(labels[key], 10, 64)
+	if err != nil || weight < 0 {
+		return 1
+	}
+	return weight
+}
+
+// splitQuantity splits the quantity in proportion to the weights with the largest remainder method, so that
+// the shares add up to the quantity. CPU and fractional quantities are split in millis, the others in whole units.
+func splitQuantity(name v1.ResourceName, total resource.Quantity, weights []int64) []resource.Quantity {
+	shares := make([]resource.Quantity, len(weights))
+
+	sum := big.NewInt(0)
+	for _, weight := range weights {
+		sum.Add(sum, big.NewInt(weight))
+	}
+	if sum.Sign() == 0 {
+		for i := range shares {
+			shares[i] = *resource.NewQuantity(0, total.Format)
+		}
+		return shares
+	}
+
+	scale := QuantityScale(name, total)
+	amount := big.NewInt(total.ScaledValue(scale))
+
+	bases := make([]*big.Int, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	left := new(big.Int).Set(amount)
+	for i, weight := range weights {
+		bases[i], remainders[i] = new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(weight)), sum, new(big.Int))
+		left.Sub(left, bases[i])
+	}
+
+	// the units left over go to the largest remainders, ties go to the earlier shares
+	order := lo.Range(len(weights))
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]].Cmp(remainders[order[b]]) > 0 })
+	for _, i := range order[:left.Int64()] {
+		bases[i].Add(bases[i], big.NewInt(1))
+	}
+
+	for i, base := range bases {
+		shares[i] = *resource.NewScaledQuantity(base.Int64(), scale)
+		shares[i].Format = total.Format
+	}
+	return shares
+}
+
+// QuantityScale returns the scale quantities of the resource are computed in: millis for CPU and fractional
+// quantities, whole units for the others.
+func QuantityScale(name v1.ResourceName, quantity resource.Quantity) resource.Scale {
+	if strings.HasSuffix(string(name), "cpu") || quantity.MilliValue()%1000 != 0 {
+		return resource.Milli
+	}
+	return 0
+}
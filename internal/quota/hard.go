@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// Adjustments are what the hard limits of the managed ResourceQuotas of a namespace depend on besides the profile.
+type Adjustments struct {
+	// Budget is the share of the namespace of the budget of the profile, the hard limits of the budget ResourceQuota
+	Budget v1.ResourceList
+
+	// Recommended are the applied recommendations by ResourceQuota name, they replace the hard limits of the profile
+	Recommended map[string]v1.ResourceList
+
+	// Extra are the resources of the approved QuotaRequests, added to every ResourceQuota that limits them
+	Extra v1.ResourceList
+}
+
+// AdjustmentsFor reads the budget share, the applied recommendations and the approved QuotaRequests of the
+// namespace. The reader must support the field indexes of the index package.
+func AdjustmentsFor(ctx context.Context, c client.Reader, q *quotav1alpha1.QuotaProfile, namespace string) (Adjustments, error) {
+	var adjustments Adjustments
+	if q.Spec.Budget != nil {
+		share, err := BudgetShare(ctx, c, q, namespace)
+		if err != nil {
+			return Adjustments{}, err
+		}
+		adjustments.Budget = share
+	}
+
+	recommended, err := AppliedRecommendations(ctx, c, q, namespace)
+	if err != nil {
+		return Adjustments{}, err
+	}
+	adjustments.Recommended = recommended
+
+	extra, err := QuotaRequestExtra(ctx, c, q, namespace)
+	if err != nil {
+		return Adjustments{}, err
+	}
+	adjustments.Extra = extra
+	return adjustments, nil
+}
+
+// ResourceQuotaCount returns the number of managed ResourceQuotas of the profile, one for every resourceQuotaSpec
+// and the budget ResourceQuota after them if the profile has a budget.
+func ResourceQuotaCount(q *quotav1alpha1.QuotaProfile) int {
+	if q.Spec.Budget != nil {
+		return len(q.Spec.ResourceQuotaSpecs) + 1
+	}
+	return len(q.Spec.ResourceQuotaSpecs)
+}
+
+// Hard returns the hard limits of the managed ResourceQuota of the profile with the given index, before the shrink
+// policy is applied. The budget ResourceQuota gets the budget share. Recommended hard limits replace the ones of
+// the resourceQuotaSpecs, and the extra resources of approved QuotaRequests are added to every ResourceQuota of
+// the resourceQuotaSpecs that limits them.
+func Hard(q *quotav1alpha1.QuotaProfile, index int, adjustments Adjustments) v1.ResourceList {
+	if index >= len(q.Spec.ResourceQuotaSpecs) {
+		return adjustments.Budget.DeepCopy()
+	}
+
+	hard := q.Spec.ResourceQuotaSpecs[index].Hard.DeepCopy()
+	if rec, ok := adjustments.Recommended[ResourceQuotaName(q.Namespace, q.Name, index)]; ok {
+		for name, quantity := range BoundRecommendation(hard, rec, q.Spec.Recommendation) {
+			if _, limited := hard[name]; limited {
+				hard[name] = quantity
+			}
+		}
+	}
+	for name, quantity := range adjustments.Extra {
+		if limit, limited := hard[name]; limited {
+			limit.Add(quantity)
+			hard[name] = limit
+		}
+	}
+	return hard
+}
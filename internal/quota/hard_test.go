@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+func TestHard(t *testing.T) {
+	profile := &quotav1alpha1.QuotaProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "quota-system"},
+		Spec: quotav1alpha1.QuotaProfileSpec{
+			ResourceQuotaSpecs: []v1.ResourceQuotaSpec{{Hard: v1.ResourceList{
+				v1.ResourceRequestsCPU:    resource.MustParse("4"),
+				v1.ResourceRequestsMemory: resource.MustParse("8Gi"),
+			}}},
+			Budget:         &quotav1alpha1.QuotaBudget{Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("20")}},
+			Recommendation: &quotav1alpha1.RecommendationPolicy{AutoApply: true},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		index       int
+		adjustments Adjustments
+		want        v1.ResourceList
+	}{
+		"the hard limits of the profile": {
+			want: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("4"), v1.ResourceRequestsMemory: resource.MustParse("8Gi")},
+		},
+		"recommendations within the hard limits of the profile": {
+			adjustments: Adjustments{Recommended: map[string]v1.ResourceList{"quota-system-web-0-rq": {
+				v1.ResourceRequestsCPU:    resource.MustParse("2"),
+				v1.ResourceRequestsMemory: resource.MustParse("16Gi"),
+				v1.ResourcePods:           resource.MustParse("10"),
+			}}},
+			want: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("2"), v1.ResourceRequestsMemory: resource.MustParse("8Gi")},
+		},
+		"approved quota requests on top of the recommendations": {
+			adjustments: Adjustments{
+				Recommended: map[string]v1.ResourceList{"quota-system-web-0-rq": {v1.ResourceRequestsCPU: resource.MustParse("2")}},
+				Extra:       v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("1"), v1.ResourcePods: resource.MustParse("5")},
+			},
+			want: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("3"), v1.ResourceRequestsMemory: resource.MustParse("8Gi")},
+		},
+		"the budget share for the budget resource quota": {
+			index: 1,
+			adjustments: Adjustments{
+				Budget: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")},
+				Extra:  v1.ResourceList{v1.ResourcePods: resource.MustParse("5")},
+			},
+			want: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := Hard(profile, tc.index, tc.adjustments); !equality.Semantic.DeepEqual(got, tc.want) {
+				t.Errorf("Hard() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if got := ResourceQuotaCount(profile); got != 2 {
+		t.Errorf("ResourceQuotaCount() = %d, want 2", got)
+	}
+}
+
+func TestProfileID(t *testing.T) {
+	profileID := ProfileID("quota-system", "web")
+	if profileID != "quota-system.web" {
+		t.Errorf("ProfileID() = %q, want quota-system.web", profileID)
+	}
+	if namespace, name := SplitProfileID(profileID); namespace != "quota-system" || name != "web" {
+		t.Errorf("SplitProfileID(%q) = %q, %q", profileID, namespace, name)
+	}
+	if namespace, name := SplitProfileID("invalid"); namespace != "" || name != "" {
+		t.Errorf("SplitProfileID(invalid) = %q, %q, want empty strings", namespace, name)
+	}
+}
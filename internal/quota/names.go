@@ -0,0 +1,54 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota computes the names and the hard limits of the objects the operator manages for a QuotaProfile,
+// so that the controllers that render them and the webhooks that check them agree.
+package quota
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProfileID returns the value of the quota.dev.operator/profile label of the objects bound to the profile.
+func ProfileID(namespace, profile string) string {
+	return fmt.Sprintf("%s.%s", namespace, profile)
+}
+
+// SplitProfileID returns the namespace and the name of the profile of a profile ID, or empty strings if it is invalid.
+func SplitProfileID(profileID string) (string, string) {
+	parts := strings.Split(profileID, ".")
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// ResourceQuotaName returns the name of the managed ResourceQuota of the profile with the given index.
+func ResourceQuotaName(namespace, profile string, index int) string {
+	return fmt.Sprintf("%s-%s-%d-rq", namespace, profile, index)
+}
+
+// LimitRangeName returns the name of the managed LimitRange of the profile with the given index.
+func LimitRangeName(namespace, profile string, index int) string {
+	return fmt.Sprintf("%s-%s-%d-lr", namespace, profile, index)
+}
+
+// ObjectTemplateName returns the name of the object rendered from the object template of the profile with the
+// given index.
+func ObjectTemplateName(namespace, profile string, index int, kind string) string {
+	return fmt.Sprintf("%s-%s-%d-%s", namespace, profile, index, strings.ToLower(kind))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// ApprovedQuotaRequestResources sums the resources of the approved quota requests of the namespace that were
+// decided for the given profile, as <namespace>/<name>, except for the quota request with the given name.
+func ApprovedQuotaRequestResources(ctx context.Context, c client.Reader, namespace, profile, except string) (v1.ResourceList, error) {
+	quotaRequests := &quotav1alpha1.QuotaRequestList{}
+	if err := c.List(ctx, quotaRequests, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	approved := v1.ResourceList{}
+	for _, quotaRequest := range quotaRequests.Items {
+		if quotaRequest.Name == except || quotaRequest.Status.Phase != quotav1alpha1.QuotaRequestApproved || quotaRequest.Status.QuotaProfile != profile {
+			continue
+		}
+		for name, quantity := range quotaRequest.Spec.Resources {
+			sum := approved[name]
+			sum.Add(quantity)
+			approved[name] = sum
+		}
+	}
+	return approved, nil
+}
+
+// QuotaRequestExtra returns the resources the approved quota requests add to the managed ResourceQuotas of the
+// namespace, at most the ceiling of the profile.
+func QuotaRequestExtra(ctx context.Context, c client.Reader, q *quotav1alpha1.QuotaProfile, namespace string) (v1.ResourceList, error) {
+	policy := q.Spec.QuotaRequests
+	if policy == nil {
+		return nil, nil
+	}
+
+	approved, err := ApprovedQuotaRequestResources(ctx, c, namespace, fmt.Sprintf("%s/%s", q.Namespace, q.Name), "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the ceiling may have been lowered since the requests were approved
+	extra := v1.ResourceList{}
+	for name, quantity := range approved {
+		ceiling, ok := policy.Ceiling[name]
+		if !ok {
+			continue
+		}
+		if quantity.Cmp(ceiling) > 0 {
+			quantity = ceiling.DeepCopy()
+		}
+		extra[name] = quantity
+	}
+	return extra, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// AppliedRecommendations returns the recommended hard limits, by ResourceQuota name, that replace the ones of the
+// profile in the namespace. They are only applied if the profile enables auto-apply and the window is covered.
+func AppliedRecommendations(ctx context.Context, c client.Reader, q *quotav1alpha1.QuotaProfile, namespace string) (map[string]v1.ResourceList, error) {
+	if q.Spec.Recommendation == nil || !q.Spec.Recommendation.AutoApply {
+		return nil, nil
+	}
+
+	rec := &quotav1alpha1.QuotaRecommendation{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: quotav1alpha1.QuotaRecommendationName}, rec); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if !rec.Status.Ready || rec.Spec.QuotaProfile != fmt.Sprintf("%s/%s", q.Namespace, q.Name) {
+		return nil, nil
+	}
+
+	return lo.SliceToMap(rec.Status.ResourceQuotas, func(rq quotav1alpha1.ResourceQuotaRecommendation) (string, v1.ResourceList) {
+		return rq.Name, rq.Recommended
+	}), nil
+}
+
+// BoundRecommendation clamps the recommended hard limits to the min and max of the policy. Resources without a
+// max are capped at the hard limit of the profile, the min wins over the max.
+func BoundRecommendation(hard, recommended v1.ResourceList, policy *quotav1alpha1.RecommendationPolicy) v1.ResourceList {
+	bounded := v1.ResourceList{}
+	for name, quantity := range recommended {
+		upper, ok := policy.Max[name]
+		if !ok {
+			upper, ok = hard[name]
+		}
+		if ok && quantity.Cmp(upper) > 0 {
+			quantity = upper.DeepCopy()
+		}
+		if lower, ok := policy.Min[name]; ok && quantity.Cmp(lower) < 0 {
+			quantity = lower.DeepCopy()
+		}
+		bounded[name] = quantity
+	}
+	return bounded
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resolver resolves namespaces to QuotaProfiles outside of the operator, e.g. in the kubectl plugin
+// and the offline simulator, by running the Namespace defaulter of the operator against an in-memory client.
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+	webhookv1 "github.com/abdullah599/namespace-quota-operator/internal/webhook/v1"
+)
+
+// Resolver holds a set of QuotaProfiles in an in-memory client, indexed the same way as the operator's
+// cache, so that namespaces resolve exactly like they do on admission.
+type Resolver struct {
+	c         client.Client
+	defaulter *webhookv1.NamespaceCustomDefaulter
+}
+
+// New returns a Resolver for the QuotaProfiles. The scheme must contain the core and quota APIs.
+func New(scheme *runtime.Scheme, quotaProfiles []quotav1alpha1.QuotaProfile) *Resolver {
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileMatchNameField, index.QuotaProfileMatchName).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileSelectorLabelKeyField, index.QuotaProfileSelectorLabelKeys).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileSelectorAnnotationKeyField, index.QuotaProfileSelectorAnnotationKeys).
+		WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileMatchNamePrefixField, index.QuotaProfileMatchNamePrefix).
+		WithIndex(&v1.Namespace{}, index.NamespaceLabelField, index.NamespaceLabels).
+		WithLists(&quotav1alpha1.QuotaProfileList{Items: quotaProfiles}).
+		Build()
+	return &Resolver{c: c, defaulter: webhookv1.NewNamespaceCustomDefaulter(c)}
+}
+
+// Client returns the in-memory client holding the QuotaProfiles, other objects may be added to it.
+func (r *Resolver) Client() client.Client {
+	return r.c
+}
+
+// Resolve returns the ID of the profile the namespace would be bound to on its next admission.
+func (r *Resolver) Resolve(ctx context.Context, ns *v1.Namespace) (string, error) {
+	ns = ns.DeepCopy()
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	if err := r.defaulter.Default(ctx, ns); err != nil {
+		return "", err
+	}
+	return ns.Labels[quotav1alpha1.QuotaProfileLabelKey], nil
+}
+
+// Candidates returns the profiles that may select the namespace, see index.QuotaProfilesForNamespace.
+func (r *Resolver) Candidates(ctx context.Context, ns *v1.Namespace) ([]quotav1alpha1.QuotaProfile, error) {
+	return index.QuotaProfilesForNamespace(ctx, r.c, ns)
+}
+
+// Get returns the profile with the given ID, or nil when there is none.
+func (r *Resolver) Get(ctx context.Context, profileID string) (*quotav1alpha1.QuotaProfile, error) {
+	namespace, name, ok := strings.Cut(profileID, ".")
+	if !ok {
+		return nil, nil
+	}
+	quotaProfile := &quotav1alpha1.QuotaProfile{}
+	if err := r.c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, quotaProfile); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return quotaProfile, nil
+}
+
+// ProfileID returns the ID namespaces are labelled with when bound to the profile.
+func ProfileID(quotaProfile *quotav1alpha1.QuotaProfile) string {
+	return quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name)
+}
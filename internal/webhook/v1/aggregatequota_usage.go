@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The usage below follows the Pod and PersistentVolumeClaim evaluators of the ResourceQuota admission of the
+// Kubernetes API server, so that aggregate limits count the same resources as the per-namespace quotas.
+
+var (
+	// computeResources are the resources whose requests are also counted without the requests. prefix.
+	computeResources = sets.New(v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage)
+
+	// claimResources are the resources counted for PersistentVolumeClaims, besides the per storage class ones.
+	claimResources = sets.New(v1.ResourcePersistentVolumeClaims, "count/persistentvolumeclaims", v1.ResourceRequestsStorage)
+)
+
+// isClaimResource reports whether the quota resource is counted for PersistentVolumeClaims instead of Pods.
+func isClaimResource(name v1.ResourceName) bool {
+	return claimResources.Has(name) || strings.Contains(string(name), ".storageclass.storage.k8s.io/")
+}
+
+// usageOf returns the quota usage of a Pod or PersistentVolumeClaim, nil objects and other kinds use nothing.
+func usageOf(obj client.Object) v1.ResourceList {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		return podUsage(o)
+	case *v1.PersistentVolumeClaim:
+		return claimUsage(o)
+	default:
+		return v1.ResourceList{}
+	}
+}
+
+// podUsage returns the quota usage of the pod. Pods that have terminated use nothing.
+func podUsage(pod *v1.Pod) v1.ResourceList {
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return v1.ResourceList{}
+	}
+
+	usage := v1.ResourceList{
+		v1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI),
+		"count/pods":    *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	for name, quantity := range podResources(pod, false) {
+		usage[v1.DefaultResourceRequestsPrefix+name] = quantity
+		if computeResources.Has(name) {
+			usage[name] = quantity
+		}
+	}
+	for name, quantity := range podResources(pod, true) {
+		usage["limits."+name] = quantity
+	}
+	return usage
+}
+
+// podResources returns the requests, or the limits, of the pod the way the scheduler counts them: the larger of
+// all containers and sidecars together and every init container with the sidecars started before it, plus the
+// pod overhead.
+func podResources(pod *v1.Pod, limits bool) v1.ResourceList {
+	resources := func(r v1.ResourceRequirements) v1.ResourceList {
+		if limits {
+			return r.Limits
+		}
+		return r.Requests
+	}
+
+	total := v1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResources(total, resources(c.Resources))
+	}
+
+	sidecars := v1.ResourceList{}
+	initContainers := v1.ResourceList{}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == v1.ContainerRestartPolicyAlways {
+			addResources(total, resources(c.Resources))
+			addResources(sidecars, resources(c.Resources))
+			continue
+		}
+		running := sidecars.DeepCopy()
+		addResources(running, resources(c.Resources))
+		maxResources(initContainers, running)
+	}
+	maxResources(total, initContainers)
+
+	for name, quantity := range pod.Spec.Overhead {
+		// the overhead only counts towards limits that are set
+		if _, ok := total[name]; ok || !limits {
+			addResources(total, v1.ResourceList{name: quantity})
+		}
+	}
+	return total
+}
+
+// claimUsage returns the quota usage of the PersistentVolumeClaim.
+func claimUsage(pvc *v1.PersistentVolumeClaim) v1.ResourceList {
+	storage := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	usage := v1.ResourceList{
+		v1.ResourcePersistentVolumeClaims: *resource.NewQuantity(1, resource.DecimalSI),
+		"count/persistentvolumeclaims":    *resource.NewQuantity(1, resource.DecimalSI),
+		v1.ResourceRequestsStorage:        storage.DeepCopy(),
+	}
+	if class := pvc.Spec.StorageClassName; class != nil && *class != "" {
+		usage[v1.ResourceName(*class+".storageclass.storage.k8s.io/"+string(v1.ResourcePersistentVolumeClaims))] = *resource.NewQuantity(1, resource.DecimalSI)
+		usage[v1.ResourceName(*class+".storageclass.storage.k8s.io/"+string(v1.ResourceRequestsStorage))] = storage.DeepCopy()
+	}
+	return usage
+}
+
+// addResources adds the quantities of b to a.
+func addResources(a, b v1.ResourceList) {
+	for name, quantity := range b {
+		sum := a[name]
+		sum.Add(quantity)
+		a[name] = sum
+	}
+}
+
+// maxResources raises the quantities of a to the ones of b where b is larger.
+func maxResources(a, b v1.ResourceList) {
+	for name, quantity := range b {
+		if current, ok := a[name]; !ok || quantity.Cmp(current) > 0 {
+			a[name] = quantity.DeepCopy()
+		}
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var aggregatequotalog = logf.Log.WithName("aggregatequota-resource")
+
+// +kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims,verbs=get;list;watch
+
+// SetupAggregateQuotaWebhooksWithManager registers the webhooks that enforce the aggregateHard limits of
+// QuotaProfiles for Pods and PersistentVolumeClaims. The usage is read from the manager cache, whose
+// informers for both kinds are started with the manager.
+func SetupAggregateQuotaWebhooksWithManager(mgr ctrl.Manager) error {
+	aggregatequotalog.Info("setting up aggregate quota webhooks with manager")
+	for _, obj := range []client.Object{&v1.Pod{}, &v1.PersistentVolumeClaim{}} {
+		if _, err := mgr.GetCache().GetInformer(context.Background(), obj); err != nil {
+			return err
+		}
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).
+			WithValidator(&AggregateQuotaCustomValidator{Client: mgr.GetClient()}).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// The webhooks fail open, so that pods, including the operator's own, can still be created while the
+// operator is unavailable, and time out early, as they are called for every pod of the bound namespaces.
+// +kubebuilder:webhook:path=/validate--v1-pod,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=vpod-v1.kb.io,admissionReviewVersions=v1,timeoutSeconds=3
+// +kubebuilder:webhook:path=/validate--v1-persistentvolumeclaim,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=persistentvolumeclaims,verbs=create;update,versions=v1,name=vpersistentvolumeclaim-v1.kb.io,admissionReviewVersions=v1,timeoutSeconds=3
+
+// AggregateQuotaCustomValidator struct is responsible for denying Pods and PersistentVolumeClaims that would
+// take the total usage of the namespaces bound to a QuotaProfile above its aggregateHard limits.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+type AggregateQuotaCustomValidator struct {
+	// Client reads namespaces, QuotaProfiles and the usage, it must support the field indexes of the index package
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &AggregateQuotaCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for Pods and PersistentVolumeClaims.
+func (v *AggregateQuotaCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, obj, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for Pods and PersistentVolumeClaims.
+// Only the increase of the usage, e.g. a PersistentVolumeClaim that is expanded, counts towards the limits.
+func (v *AggregateQuotaCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, newObj, oldObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for Pods and PersistentVolumeClaims.
+func (v *AggregateQuotaCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate denies the object if its usage, minus the usage of the old object on updates, does not fit into the
+// aggregateHard limits of the profile its namespace is bound to. It is best-effort: the usage is read from the
+// cache, so objects that are being admitted concurrently, or were admitted but are not cached yet, are not counted
+// and together can exceed the limits.
+func (v *AggregateQuotaCustomValidator) validate(ctx context.Context, obj, oldObj runtime.Object) error {
+	o, ok := obj.(client.Object)
+	if !ok {
+		aggregatequotalog.Error(nil, "received invalid object type", "got", fmt.Sprintf("%T", obj))
+		return fmt.Errorf("expected a Kubernetes object but got %T", obj)
+	}
+
+	ns := &v1.Namespace{}
+	if err := v.Client.Get(ctx, types.NamespacedName{Name: o.GetNamespace()}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	profileID := ns.Labels[v1alpha1.QuotaProfileLabelKey]
+	if profileID == "" {
+		return nil
+	}
+	profileNamespace, profileName := quota.SplitProfileID(profileID)
+
+	profile := &v1alpha1.QuotaProfile{}
+	if err := v.Client.Get(ctx, types.NamespacedName{Namespace: profileNamespace, Name: profileName}, profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		aggregatequotalog.Error(err, "failed to get quota profile", "profileID", profileID)
+		return err
+	}
+	hard := profile.Spec.AggregateHard
+	if len(hard) == 0 {
+		return nil
+	}
+
+	requested := usageOf(o)
+	if old, ok := oldObj.(client.Object); ok {
+		for name, quantity := range usageOf(old) {
+			delta := requested[name]
+			delta.Sub(quantity)
+			requested[name] = delta
+		}
+	}
+	requested = lo.PickBy(requested, func(name v1.ResourceName, quantity resource.Quantity) bool {
+		_, limited := hard[name]
+		return limited && quantity.Sign() > 0
+	})
+	if len(requested) == 0 {
+		return nil
+	}
+
+	used, err := v.aggregateUsage(ctx, profileID, hard)
+	if err != nil {
+		aggregatequotalog.Error(err, "failed to compute aggregate usage", "profileID", profileID)
+		return err
+	}
+
+	var exceeded []v1.ResourceName
+	for name, quantity := range requested {
+		total := used[name]
+		total.Add(quantity)
+		if total.Cmp(hard[name]) > 0 {
+			exceeded = append(exceeded, name)
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	sort.Slice(exceeded, func(i, j int) bool { return exceeded[i] < exceeded[j] })
+	aggregatequotalog.Info("denying object that exceeds the aggregate quota", "namespace", o.GetNamespace(), "name", o.GetName(), "profileID", profileID, "resources", exceeded)
+	return fmt.Errorf("exceeded aggregate quota of quota profile %s/%s: requested: %s, used: %s, limited: %s",
+		profileNamespace, profileName, formatResources(requested, exceeded), formatResources(used, exceeded), formatResources(hard, exceeded))
+}
+
+// aggregateUsage sums the usage of the limited resources over all namespaces bound to the profile. Pods and
+// PersistentVolumeClaims are only listed when a resource they use is limited.
+func (v *AggregateQuotaCustomValidator) aggregateUsage(ctx context.Context, profileID string, hard v1.ResourceList) (v1.ResourceList, error) {
+	namespaces, err := index.NamespacesWithLabel(ctx, v.Client, v1alpha1.QuotaProfileLabelKey, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	countClaims := lo.SomeBy(lo.Keys(hard), isClaimResource)
+	countPods := lo.SomeBy(lo.Keys(hard), func(name v1.ResourceName) bool { return !isClaimResource(name) })
+
+	used := v1.ResourceList{}
+	for _, ns := range namespaces {
+		if countPods {
+			pods := &v1.PodList{}
+			if err := v.Client.List(ctx, pods, client.InNamespace(ns.Name)); err != nil {
+				return nil, err
+			}
+			for i := range pods.Items {
+				addResources(used, podUsage(&pods.Items[i]))
+			}
+		}
+		if countClaims {
+			pvcs := &v1.PersistentVolumeClaimList{}
+			if err := v.Client.List(ctx, pvcs, client.InNamespace(ns.Name)); err != nil {
+				return nil, err
+			}
+			for i := range pvcs.Items {
+				addResources(used, claimUsage(&pvcs.Items[i]))
+			}
+		}
+	}
+	return used, nil
+}
+
+// formatResources formats the quantities of the given resources like the ResourceQuota admission of the
+// API server, e.g. requests.cpu=2,requests.memory=4Gi.
+func formatResources(list v1.ResourceList, names []v1.ResourceName) string {
+	return strings.Join(lo.Map(names, func(name v1.ResourceName, _ int) string {
+		quantity := list[name]
+		return fmt.Sprintf("%s=%s", name, quantity.String())
+	}), ",")
+}
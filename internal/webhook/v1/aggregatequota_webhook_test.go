@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("AggregateQuota Webhook", func() {
+	var (
+		ctx       context.Context
+		validator AggregateQuotaCustomValidator
+	)
+
+	boundNamespace := func(name, profileID string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: profileID},
+		}}
+	}
+
+	pod := func(namespace, name, cpu string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name:      "app",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}},
+			}}},
+		}
+	}
+
+	claim := func(namespace, name, storage string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: lo.ToPtr("fast"),
+				Resources: v1.VolumeResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(storage)},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+
+		profile := &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+				AggregateHard: v1.ResourceList{
+					v1.ResourceRequestsCPU: resource.MustParse("4"),
+					v1.ResourcePods:        resource.MustParse("10"),
+					"fast.storageclass.storage.k8s.io/requests.storage": resource.MustParse("10Gi"),
+				},
+			},
+		}
+		unlimited := &quotav1alpha1.QuotaProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "unlimited", Namespace: "default"},
+			Spec: quotav1alpha1.QuotaProfileSpec{
+				NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "b"}},
+			},
+		}
+
+		finished := pod("team-a-dev", "finished", "2")
+		finished.Status.Phase = v1.PodSucceeded
+
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(setupFakeClientWithScheme()).
+			WithObjects(
+				profile, unlimited,
+				boundNamespace("team-a-dev", "default.team"),
+				boundNamespace("team-a-prod", "default.team"),
+				boundNamespace("team-b", "default.unlimited"),
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unbound"}},
+				pod("team-a-dev", "web", "1500m"),
+				pod("team-a-prod", "api", "1500m"),
+				finished,
+				pod("team-b", "batch", "8"),
+				claim("team-a-prod", "data", "6Gi"),
+			).
+			WithIndex(&v1.Namespace{}, index.NamespaceLabelField, index.NamespaceLabels).
+			Build()
+		validator = AggregateQuotaCustomValidator{Client: fakeClient}
+	})
+
+	Context("When creating Pods under Validating Webhook", func() {
+		It("Should allow pods that fit into the aggregate quota", func() {
+			Expect(validator.ValidateCreate(ctx, pod("team-a-dev", "worker", "1"))).Error().ToNot(HaveOccurred())
+		})
+
+		It("Should deny pods that exceed the usage summed over all bound namespaces", func() {
+			_, err := validator.ValidateCreate(ctx, pod("team-a-dev", "worker", "1500m"))
+			Expect(err).To(MatchError(Equal("exceeded aggregate quota of quota profile default/team: " +
+				"requested: requests.cpu=1500m, used: requests.cpu=3, limited: requests.cpu=4")))
+		})
+
+		It("Should count the largest init container instead of the sum", func() {
+			p := pod("team-a-prod", "migrate", "500m")
+			p.Spec.InitContainers = []v1.Container{
+				{Name: "init-a", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}},
+				{Name: "init-b", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}},
+			}
+			Expect(validator.ValidateCreate(ctx, p)).Error().ToNot(HaveOccurred())
+
+			p.Spec.InitContainers[1].RestartPolicy = lo.ToPtr(v1.ContainerRestartPolicyAlways)
+			Expect(validator.ValidateCreate(ctx, p)).Error().To(MatchError(ContainSubstring("requested: requests.cpu=1500m")))
+		})
+
+		It("Should allow pods in namespaces whose profile has no aggregate quota", func() {
+			Expect(validator.ValidateCreate(ctx, pod("team-b", "worker", "8"))).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateCreate(ctx, pod("unbound", "worker", "8"))).Error().ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When creating or updating PersistentVolumeClaims under Validating Webhook", func() {
+		It("Should deny claims that exceed the storage class quota", func() {
+			Expect(validator.ValidateCreate(ctx, claim("team-a-dev", "cache", "4Gi"))).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateCreate(ctx, claim("team-a-dev", "cache", "5Gi"))).Error().To(
+				MatchError(ContainSubstring("fast.storageclass.storage.k8s.io/requests.storage=5Gi")))
+		})
+
+		It("Should only count the increase when a claim is expanded", func() {
+			old := claim("team-a-prod", "data", "6Gi")
+			Expect(validator.ValidateUpdate(ctx, old, claim("team-a-prod", "data", "10Gi"))).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, old, claim("team-a-prod", "data", "11Gi"))).Error().To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// serviceAccountTokenPath is where the token of the pod's service account is mounted.
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// BreakGlassEventReason is the reason of the Events recorded when a break-glass user mutates a managed object.
+const BreakGlassEventReason = "BreakGlassBypass"
+
+var authorizationlog = logf.Log.WithName("managed-object-authorization")
+
+// namespaceCleanupUsers may delete managed objects: the namespace controller deletes the contents of terminating
+// namespaces and the garbage collector deletes owned objects. The webhooks fail closed, so denying them would leave
+// bound namespaces stuck in Terminating.
+var namespaceCleanupUsers = []string{
+	"system:serviceaccount:kube-system:namespace-controller",
+	"system:serviceaccount:kube-system:generic-garbage-collector",
+	// kube-controller-manager runs its controllers with its own credentials without --use-service-account-credentials
+	"system:kube-controller-manager",
+}
+
+// ManagedObjectAuthorizer decides which users are allowed to create, update or delete
+// ResourceQuotas, LimitRanges and templated objects that are managed by a QuotaProfile.
+type ManagedObjectAuthorizer struct {
+	// OperatorServiceAccount is the username of the operator's service account,
+	// e.g. "system:serviceaccount:namespace-quota-operator-system:namespace-quota-operator-controller-manager"
+	OperatorServiceAccount string
+
+	// BreakGlassUsers and BreakGlassGroups are allowed to bypass the protection, every bypass is recorded as an Event
+	BreakGlassUsers  []string
+	BreakGlassGroups []string
+
+	// Recorder records the break-glass Events, no Events are recorded when it is nil
+	Recorder record.EventRecorder
+}
+
+// isAllowed reports whether the user of the admission request may perform the operation on the managed object.
+// A nil authorizer only denies.
+func (a *ManagedObjectAuthorizer) isAllowed(ctx context.Context, obj runtime.Object, operation string) bool {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		authorizationlog.Error(err, "failed to get request from context")
+		return false
+	}
+
+	if a == nil {
+		return false
+	}
+
+	user := req.UserInfo
+	if a.OperatorServiceAccount != "" && user.Username == a.OperatorServiceAccount {
+		return true
+	}
+
+	if operation == "delete" && lo.Contains(namespaceCleanupUsers, user.Username) {
+		return true
+	}
+
+	if !lo.Contains(a.BreakGlassUsers, user.Username) && !lo.Some(a.BreakGlassGroups, user.Groups) {
+		return false
+	}
+
+	authorizationlog.Info("allowing break-glass request", "user", user.Username, "groups", user.Groups, "operation", operation)
+	if a.Recorder != nil {
+		a.Recorder.AnnotatedEventf(obj, map[string]string{"user": user.Username, "uid": user.UID}, v1.EventTypeWarning, BreakGlassEventReason,
+			"break-glass user %s (groups: %s) bypassed the operator's protection to %s this object", user.Username, strings.Join(user.Groups, ","), operation)
+	}
+	return true
+}
+
+// DetectOperatorServiceAccount returns the username of the service account the operator runs as,
+// read from the subject of the mounted service account token.
+func DetectOperatorServiceAccount() (string, error) {
+	token, err := os.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(token)), ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid service account token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid service account token payload: %w", err)
+	}
+
+	claims := struct {
+		Subject string `json:"sub"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("invalid service account token claims: %w", err)
+	}
+
+	if !strings.HasPrefix(claims.Subject, "system:serviceaccount:") {
+		return "", fmt.Errorf("unexpected service account token subject: %s", claims.Subject)
+	}
+	return claims.Subject, nil
+}
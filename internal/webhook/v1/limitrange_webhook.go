@@ -33,9 +33,10 @@ import (
 var limitrangelog = logf.Log.WithName("limitrange-resource")
 
 // SetupLimitRangeWebhookWithManager registers the webhook for LimitRange in the manager.
-func SetupLimitRangeWebhookWithManager(mgr ctrl.Manager) error {
+// Only the operator and the break-glass users of the authorizer may mutate managed limit ranges.
+func SetupLimitRangeWebhookWithManager(mgr ctrl.Manager, authorizer *ManagedObjectAuthorizer) error {
 	return ctrl.NewWebhookManagedBy(mgr).For(&v1.LimitRange{}).
-		WithValidator(&LimitRangeCustomValidator{}).
+		WithValidator(&LimitRangeCustomValidator{Authorizer: authorizer}).
 		Complete()
 }
 
@@ -49,6 +50,8 @@ func SetupLimitRangeWebhookWithManager(mgr ctrl.Manager) error {
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type LimitRangeCustomValidator struct {
+	// Authorizer decides who may mutate managed limit ranges, everyone is denied when it is nil
+	Authorizer *ManagedObjectAuthorizer
 }
 
 var _ webhook.CustomValidator = &LimitRangeCustomValidator{}
@@ -67,9 +70,9 @@ func (v *LimitRangeCustomValidator) ValidateCreate(ctx context.Context, obj runt
 		return nil, nil
 	}
 
-	if !isServiceAccount(ctx) {
+	if !v.Authorizer.isAllowed(ctx, limitrange, "create") {
 		limitrangelog.Info("unauthorized request", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
-		return nil, fmt.Errorf("only service accounts are allowed to create managed limit ranges")
+		return nil, fmt.Errorf("only the operator and break-glass users are allowed to create managed limit ranges")
 	}
 
 	limitrangelog.Info("limitrange creation validated successfully", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
@@ -78,6 +81,11 @@ func (v *LimitRangeCustomValidator) ValidateCreate(ctx context.Context, obj runt
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type LimitRange.
 func (v *LimitRangeCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldLimitRange, ok := oldObj.(*v1.LimitRange)
+	if !ok {
+		limitrangelog.Error(nil, "received invalid object type", "expected", "LimitRange", "got", fmt.Sprintf("%T", oldObj))
+		return nil, fmt.Errorf("expected a LimitRange object for the oldObj but got %T", oldObj)
+	}
 	limitrange, ok := newObj.(*v1.LimitRange)
 	if !ok {
 		limitrangelog.Error(nil, "received invalid object type", "expected", "LimitRange", "got", fmt.Sprintf("%T", newObj))
@@ -85,14 +93,15 @@ func (v *LimitRangeCustomValidator) ValidateUpdate(ctx context.Context, oldObj,
 	}
 	limitrangelog.Info("validating limitrange update", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
 
-	if !isManagedByQuotaProfile(limitrange.GetLabels()) {
+	// removing the profile label in the same update would otherwise release the object from the protection
+	if !isManagedByQuotaProfile(oldLimitRange.GetLabels()) && !isManagedByQuotaProfile(limitrange.GetLabels()) {
 		limitrangelog.Info("limitrange is not managed by quota profile, skipping validation", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
 		return nil, nil
 	}
 
-	if !isServiceAccount(ctx) {
+	if !v.Authorizer.isAllowed(ctx, limitrange, "update") {
 		limitrangelog.Info("unauthorized request", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
-		return nil, fmt.Errorf("only service accounts are allowed to update managed limit ranges")
+		return nil, fmt.Errorf("only the operator and break-glass users are allowed to update managed limit ranges")
 	}
 
 	limitrangelog.Info("limitrange update validated successfully", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
@@ -114,9 +123,9 @@ func (v *LimitRangeCustomValidator) ValidateDelete(ctx context.Context, obj runt
 		return nil, nil
 	}
 
-	if !isServiceAccount(ctx) {
+	if !v.Authorizer.isAllowed(ctx, limitrange, "delete") {
 		limitrangelog.Info("unauthorized request", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
-		return nil, fmt.Errorf("only service accounts are allowed to delete managed limit ranges")
+		return nil, fmt.Errorf("only the operator and break-glass users are allowed to delete managed limit ranges")
 	}
 
 	limitrangelog.Info("limitrange deletion validated successfully", "name", limitrange.GetName(), "namespace", limitrange.GetNamespace())
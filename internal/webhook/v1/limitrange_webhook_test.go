@@ -26,6 +26,7 @@ import (
 	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
@@ -96,6 +97,10 @@ var _ = Describe("LimitRangee Webhook", func() {
 		It("Should allow update by user if a LimitRange is not managed by operator", func() {
 			Expect(validator.ValidateUpdate(ctx, unmanagedObj, unmanagedObj)).Error().ToNot(HaveOccurred())
 		})
+
+		It("Should deny update by user that removes the profile label of a managed LimitRange", func() {
+			Expect(validator.ValidateUpdate(ctx, managedObj, unmanagedObj)).Error().To(HaveOccurred())
+		})
 		It("Should deny deletion by user if a LimitRange is managed by operator", func() {
 			Expect(validator.ValidateDelete(ctx, managedObj)).Error().To(HaveOccurred())
 		})
@@ -104,4 +109,51 @@ var _ = Describe("LimitRangee Webhook", func() {
 		})
 	})
 
+	Context("When authorizing mutations of managed LimitRanges", func() {
+		var recorder *record.FakeRecorder
+
+		requestBy := func(username string, groups ...string) context.Context {
+			return admission.NewContextWithRequest(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+				},
+			})
+		}
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			validator = LimitRangeCustomValidator{Authorizer: &ManagedObjectAuthorizer{
+				OperatorServiceAccount: "system:serviceaccount:operator-system:controller-manager",
+				BreakGlassUsers:        []string{"admin@example.com"},
+				BreakGlassGroups:       []string{"sre"},
+				Recorder:               recorder,
+			}}
+		})
+
+		It("Should allow the operator service account", func() {
+			ctx := requestBy("system:serviceaccount:operator-system:controller-manager")
+			Expect(validator.ValidateCreate(ctx, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should deny other service accounts", func() {
+			ctx := requestBy("system:serviceaccount:tenant:ci-bot", "system:serviceaccounts")
+			Expect(validator.ValidateCreate(ctx, managedObj)).Error().To(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().To(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, managedObj)).Error().To(HaveOccurred())
+		})
+
+		It("Should allow break-glass users and record an event", func() {
+			Expect(validator.ValidateDelete(requestBy("admin@example.com"), managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(BreakGlassEventReason), ContainSubstring("admin@example.com"))))
+		})
+
+		It("Should allow members of break-glass groups and record an event", func() {
+			Expect(validator.ValidateUpdate(requestBy("jane", "developers", "sre"), managedObj, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("jane")))
+		})
+	})
+
 })
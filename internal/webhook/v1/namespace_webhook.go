@@ -24,25 +24,50 @@ import (
 	"time"
 
 	"github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // nolint:unused
 // log is for logging in this package.
 var namespacelog = logf.Log.WithName("namespace-resource")
 
+// NamespaceWebhookOptions configures the Namespace webhooks.
+type NamespaceWebhookOptions struct {
+	// Authorizer decides who may change the quota profile labels of namespaces
+	Authorizer *ManagedObjectAuthorizer
+
+	// LockSelectorLabels also protects the labels and annotations the bound profile selects the namespace by
+	LockSelectorLabels bool
+
+	// FailOpen admits namespaces unchanged when the QuotaProfiles cannot be looked up,
+	// the QuotaProfile controller labels them asynchronously instead
+	FailOpen bool
+}
+
 // SetupNamespaceWebhookWithManager registers the webhook for Namespace in the manager.
-func SetupNamespaceWebhookWithManager(mgr ctrl.Manager) error {
-	namespacelog.Info("setting up namespace webhook")
+// The QuotaProfiles are looked up from the manager cache through the indexes of the index package.
+func SetupNamespaceWebhookWithManager(mgr ctrl.Manager, opts NamespaceWebhookOptions) error {
+	namespacelog.Info("setting up namespace webhook", "lockSelectorLabels", opts.LockSelectorLabels, "failOpen", opts.FailOpen)
+	defaulter := NewNamespaceCustomDefaulter(mgr.GetClient())
+	defaulter.FailOpen = opts.FailOpen
 	return ctrl.NewWebhookManagedBy(mgr).For(&v1.Namespace{}).
-		WithDefaulter(&NamespaceCustomDefaulter{c: mgr.GetClient()}).
+		WithDefaulter(defaulter).
+		WithValidator(&NamespaceCustomValidator{
+			defaulter:          defaulter,
+			Authorizer:         opts.Authorizer,
+			LockSelectorLabels: opts.LockSelectorLabels,
+		}).
 		Complete()
 }
 
@@ -55,10 +80,20 @@ func SetupNamespaceWebhookWithManager(mgr ctrl.Manager) error {
 // as it is used only for temporary operations and does not need to be deeply copied.
 type NamespaceCustomDefaulter struct {
 	c client.Client
+
+	// FailOpen admits the namespace unchanged when the QuotaProfiles cannot be looked up
+	FailOpen bool
 }
 
 var _ webhook.CustomDefaulter = &NamespaceCustomDefaulter{}
 
+// NewNamespaceCustomDefaulter returns a defaulter that looks up the QuotaProfiles with the given client,
+// which must support the field indexes of the index package. It is also used by the kubectl plugin to
+// resolve namespaces against a snapshot of the cluster.
+func NewNamespaceCustomDefaulter(c client.Client) *NamespaceCustomDefaulter {
+	return &NamespaceCustomDefaulter{c: c}
+}
+
 // Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Namespace.
 func (d *NamespaceCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
 	namespace, ok := obj.(*v1.Namespace)
@@ -68,118 +103,233 @@ func (d *NamespaceCustomDefaulter) Default(ctx context.Context, obj runtime.Obje
 	}
 	namespacelog.Info("defaulting for namespace", "name", namespace.GetName())
 
-	quotaProfiles := &v1alpha1.QuotaProfileList{}
-	if err := d.c.List(ctx, quotaProfiles); err != nil {
-		namespacelog.Error(err, "failed to list quota profiles")
+	quotaProfiles, err := index.QuotaProfilesForNamespace(ctx, d.c, namespace)
+	if err != nil {
+		if d.FailOpen {
+			namespacelog.Error(err, "failed to look up quota profiles, admitting namespace unchanged", "namespace", namespace.GetName())
+			return nil
+		}
+		namespacelog.Error(err, "failed to look up quota profiles")
 		return err
 	}
 
-	sort.Slice(quotaProfiles.Items, func(i, j int) bool {
-		return quotaProfiles.Items[i].CreationTimestamp.Before(&quotaProfiles.Items[j].CreationTimestamp)
-	})
-
-	matched := false
+	// the same ranking as in the QuotaProfile controller, so both bind the namespace to the same profile
+	decision := index.Decide(quotaProfiles, namespace, time.Now())
+	if decision.Winner != nil {
+		namespacelog.Info("matched namespace", "namespace", namespace.GetName(), "quotaProfile", decision.Winner.Name,
+			"selector", index.SelectorString(decision.Winner.Spec.NamespaceSelector), "trace", decision.Trace)
+		setQuotaProfileLabels(namespace, decision.Winner)
+		return nil
+	}
 
-	for _, quotaProfile := range quotaProfiles.Items {
-		if quotaProfile.DeletionTimestamp != nil {
-			namespacelog.Info("quota profile is being deleted, skipping", "quotaProfile", quotaProfile.Name)
-			continue
-		}
-		if quotaProfile.Spec.NamespaceSelector.MatchName != nil {
-			if *quotaProfile.Spec.NamespaceSelector.MatchName == namespace.GetName() {
-				namespacelog.Info("matched namespace by name", "namespace", namespace.GetName(), "quotaProfile", quotaProfile.Name)
-				setQuotaProfileLabels(namespace, &quotaProfile)
-				return nil
-			}
-		} else if quotaProfile.Spec.NamespaceSelector.MatchLabels != nil {
-			keys := lo.Keys(quotaProfile.Spec.NamespaceSelector.MatchLabels)
-			key := keys[0]
-			value := quotaProfile.Spec.NamespaceSelector.MatchLabels[key]
-
-			if _, ok := namespace.GetLabels()[key]; !ok {
-				namespacelog.Info("namespace does not have required label", "namespace", namespace.GetName(), "label", key)
-				continue
-			}
-			// selector match
-			if namespace.GetLabels()[key] == value {
-				namespacelog.Info("matched namespace by label", "namespace", namespace.GetName(), "quotaProfile", quotaProfile.Name, "label", fmt.Sprintf("%s=%s", key, value))
-				if _, ok := namespace.Labels[v1alpha1.QuotaProfileLabelKey]; !ok {
-					setQuotaProfileLabels(namespace, &quotaProfile)
-					matched = true
-				} else {
-					existingProfileID := namespace.Labels[v1alpha1.QuotaProfileLabelKey]
-					existingProfileNamespace, existingProfileName := splitProfileID(existingProfileID)
-					if existingProfileNamespace == quotaProfile.Namespace && existingProfileName == quotaProfile.Name {
-						namespacelog.Info("namespace already has matching quota profile", "namespace", namespace.GetName(), "quotaProfile", quotaProfile.Name)
-						setQuotaProfileLabels(namespace, &quotaProfile)
-						matched = true
-						continue
-					} else {
-						namespacelog.Info("resolving conflict between quota profiles", "namespace", namespace.GetName(), "existing", existingProfileID, "new", quotaProfile.Name)
-						if err := d.resolveConflict(ctx, &quotaProfile, namespace); err != nil {
-							namespacelog.Error(err, "failed to resolve conflict")
-							return err
-						}
-						matched = true
-					}
-				}
-			}
-		}
+	if namespace.Labels[v1alpha1.QuotaProfileRetainedLabelKey] != "" {
+		namespacelog.Info("no matching quota profile found, keeping retained labels", "namespace", namespace.GetName())
+		return nil
 	}
+	namespacelog.Info("no matching quota profile found, removing labels", "namespace", namespace.GetName())
+	removeLabel(namespace)
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate--v1-namespace,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=namespaces,verbs=create;update,versions=v1,name=vnamespace-v1.kb.io,admissionReviewVersions=v1
 
-	if !matched {
-		namespacelog.Info("no matching quota profile found, removing labels", "namespace", namespace.GetName())
-		removeLabel(namespace)
+// NamespaceCustomValidator struct is responsible for validating the Namespace resource
+// when it is created or updated, so that tenants cannot escape or change their quota by editing labels.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+type NamespaceCustomValidator struct {
+	defaulter *NamespaceCustomDefaulter
+
+	// Authorizer decides who may change the protected labels, everyone is denied when it is nil
+	Authorizer *ManagedObjectAuthorizer
+
+	// LockSelectorLabels rejects changes to the labels and annotations the bound quota profile selects the namespace by
+	LockSelectorLabels bool
+}
+
+var _ webhook.CustomValidator = &NamespaceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Namespace.
+func (v *NamespaceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		namespacelog.Error(nil, "received invalid object type", "expected", "Namespace", "got", fmt.Sprintf("%T", obj))
+		return nil, fmt.Errorf("expected a Namespace object but got %T", obj)
 	}
+	namespacelog.Info("validating namespace creation", "name", namespace.GetName())
 
-	return nil
+	return nil, v.validate(ctx, nil, namespace, "create")
 }
 
-func removeLabel(ns *v1.Namespace) {
-	namespacelog.Info("removing quota profile labels", "namespace", ns.GetName())
-	delete(ns.Labels, v1alpha1.QuotaProfileLabelKey)
-	delete(ns.Labels, v1alpha1.QuotaProfileLastUpdateTimestamp)
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Namespace.
+func (v *NamespaceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNamespace, ok := oldObj.(*v1.Namespace)
+	if !ok {
+		namespacelog.Error(nil, "received invalid object type", "expected", "Namespace", "got", fmt.Sprintf("%T", oldObj))
+		return nil, fmt.Errorf("expected a Namespace object for the oldObj but got %T", oldObj)
+	}
+	namespace, ok := newObj.(*v1.Namespace)
+	if !ok {
+		namespacelog.Error(nil, "received invalid object type", "expected", "Namespace", "got", fmt.Sprintf("%T", newObj))
+		return nil, fmt.Errorf("expected a Namespace object for the newObj but got %T", newObj)
+	}
+	namespacelog.Info("validating namespace update", "name", namespace.GetName())
+
+	return nil, v.validate(ctx, oldNamespace, namespace, "update")
 }
 
-func (d *NamespaceCustomDefaulter) resolveConflict(ctx context.Context, quotaProfile *v1alpha1.QuotaProfile, ns *v1.Namespace) error {
-	existingProfileID := ns.Labels[v1alpha1.QuotaProfileLabelKey]
-	existingProfileNamespace, existingProfileName := splitProfileID(existingProfileID)
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Namespace.
+func (v *NamespaceCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
 
-	existingProfile := &v1alpha1.QuotaProfile{}
-	if err := d.c.Get(ctx, types.NamespacedName{Name: existingProfileName, Namespace: existingProfileNamespace}, existingProfile); err != nil {
-		namespacelog.Error(err, "failed to get existing quota profile", "profile", existingProfileID)
+func (v *NamespaceCustomValidator) validate(ctx context.Context, oldNamespace, namespace *v1.Namespace, operation string) error {
+	changed, err := v.protectedLabelChanges(ctx, oldNamespace, namespace)
+	if err != nil {
+		namespacelog.Error(err, "failed to compute protected label changes", "namespace", namespace.GetName())
 		return err
 	}
 
-	if (existingProfile == &v1alpha1.QuotaProfile{}) {
-		namespacelog.Info("existing profile not found, using new profile", "namespace", ns.GetName(), "profile", quotaProfile.Name)
-		setQuotaProfileLabels(ns, quotaProfile)
-		return nil
+	var changedAnnotations []string
+	if v.LockSelectorLabels && oldNamespace != nil {
+		selectorChanged, annotationsChanged, err := v.selectorChanges(ctx, oldNamespace, namespace)
+		if err != nil {
+			namespacelog.Error(err, "failed to compute selector label changes", "namespace", namespace.GetName())
+			return err
+		}
+		changed = append(changed, selectorChanged...)
+		changedAnnotations = annotationsChanged
 	}
 
-	if existingProfile.Spec.Precedence > quotaProfile.Spec.Precedence {
-		namespacelog.Info("keeping existing profile due to higher precedence", "namespace", ns.GetName(), "existing", existingProfileID, "existingPrecedence", existingProfile.Spec.Precedence, "newPrecedence", quotaProfile.Spec.Precedence)
-		setQuotaProfileLabels(ns, existingProfile)
+	if len(changed) == 0 && len(changedAnnotations) == 0 {
 		return nil
 	}
 
-	namespacelog.Info("using new profile due to higher or equal precedence", "namespace", ns.GetName(), "new", quotaProfile.Name, "existingPrecedence", existingProfile.Spec.Precedence, "newPrecedence", quotaProfile.Spec.Precedence)
-	setQuotaProfileLabels(ns, quotaProfile)
+	if !v.Authorizer.isAllowed(ctx, namespace, operation) {
+		namespacelog.Info("unauthorized label change", "namespace", namespace.GetName(), "labels", changed, "annotations", changedAnnotations)
+		var what []string
+		if len(changed) > 0 {
+			what = append(what, "the labels "+strings.Join(changed, ", "))
+		}
+		if len(changedAnnotations) > 0 {
+			what = append(what, "the annotations "+strings.Join(changedAnnotations, ", "))
+		}
+		return fmt.Errorf("only the operator and break-glass users are allowed to change %s of namespaces", strings.Join(what, " and "))
+	}
 
+	namespacelog.Info("protected label change validated successfully", "namespace", namespace.GetName(), "labels", changed, "annotations", changedAnnotations)
 	return nil
 }
 
-func splitProfileID(profileID string) (string, string) {
-	parts := strings.Split(profileID, ".")
-	if len(parts) != 2 {
-		namespacelog.Info("invalid profile ID format", "profileID", profileID)
-		return "", ""
+// protectedLabelChanges returns the operator owned labels whose values in the namespace differ from the
+// values the defaulter would have set. The defaulter runs before this webhook, so the labels are recomputed
+// from the previous values and anything else must have been changed by the requesting user.
+func (v *NamespaceCustomValidator) protectedLabelChanges(ctx context.Context, oldNamespace, namespace *v1.Namespace) ([]string, error) {
+	var oldLabels map[string]string
+	if oldNamespace != nil {
+		oldLabels = oldNamespace.Labels
+	}
+
+	expected := namespace.DeepCopy()
+	if expected.Labels == nil {
+		expected.Labels = map[string]string{}
+	}
+	for _, key := range protectedLabels {
+		if value, ok := oldLabels[key]; ok {
+			expected.Labels[key] = value
+		} else {
+			delete(expected.Labels, key)
+		}
+	}
+
+	if err := v.defaulter.Default(ctx, expected); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, key := range protectedLabels {
+		// the defaulter refreshes the timestamp whenever it applies a profile, so only its presence can be compared then
+		if key == v1alpha1.QuotaProfileLastUpdateTimestamp && expected.Labels[key] != oldLabels[key] {
+			_, expectedFound := expected.Labels[key]
+			_, found := namespace.Labels[key]
+			if expectedFound != found {
+				changed = append(changed, key)
+			}
+			continue
+		}
+
+		if namespace.Labels[key] != expected.Labels[key] {
+			changed = append(changed, key)
+		}
+	}
+	return changed, nil
+}
+
+// selectorChanges returns the labels the bound quota profile selects the namespace by that were changed or
+// removed, or that were added or changed so that a label expression no longer matches, and the annotations it
+// selects the namespace by that were changed or removed. The name and the age of a namespace cannot change.
+func (v *NamespaceCustomValidator) selectorChanges(ctx context.Context, oldNamespace, namespace *v1.Namespace) ([]string, []string, error) {
+	profileID := oldNamespace.Labels[v1alpha1.QuotaProfileLabelKey]
+	if profileID == "" {
+		return nil, nil, nil
 	}
-	return parts[0], parts[1]
+
+	profileNamespace, profileName := quota.SplitProfileID(profileID)
+	quotaProfile := &v1alpha1.QuotaProfile{}
+	if err := v.defaulter.c.Get(ctx, types.NamespacedName{Name: profileName, Namespace: profileNamespace}, quotaProfile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		if v.defaulter.FailOpen {
+			namespacelog.Error(err, "failed to get quota profile, skipping selector label check", "namespace", namespace.GetName(), "profile", profileID)
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	var changed []string
+	for key, value := range quotaProfile.Spec.NamespaceSelector.MatchLabels {
+		if oldNamespace.Labels[key] == value && namespace.Labels[key] != value {
+			changed = append(changed, key)
+		}
+	}
+	for _, requirement := range quotaProfile.Spec.NamespaceSelector.MatchExpressions {
+		if index.RequirementMismatch(requirement, oldNamespace.Labels) == "" && index.RequirementMismatch(requirement, namespace.Labels) != "" {
+			changed = append(changed, requirement.Key)
+		}
+	}
+	changed = lo.Uniq(changed)
+	sort.Strings(changed)
+
+	var changedAnnotations []string
+	for key, value := range quotaProfile.Spec.NamespaceSelector.MatchAnnotations {
+		if oldNamespace.Annotations[key] == value && namespace.Annotations[key] != value {
+			changedAnnotations = append(changedAnnotations, key)
+		}
+	}
+	sort.Strings(changedAnnotations)
+	return changed, changedAnnotations, nil
+}
+
+// protectedLabels are the namespace labels that only the operator may change
+var protectedLabels = []string{
+	v1alpha1.QuotaProfileLabelKey,
+	v1alpha1.QuotaProfileLastUpdateTimestamp,
+	v1alpha1.QuotaProfileRetainedLabelKey,
+	v1alpha1.QuotaProfileGenerationLabelKey,
+}
+
+func removeLabel(ns *v1.Namespace) {
+	namespacelog.Info("removing quota profile labels", "namespace", ns.GetName())
+	delete(ns.Labels, v1alpha1.QuotaProfileLabelKey)
+	delete(ns.Labels, v1alpha1.QuotaProfileLastUpdateTimestamp)
+	delete(ns.Labels, v1alpha1.QuotaProfileRetainedLabelKey)
 }
 
 func setQuotaProfileLabels(ns *v1.Namespace, quotaProfile *v1alpha1.QuotaProfile) {
 	namespacelog.Info("setting quota profile labels", "namespace", ns.GetName(), "quotaProfile", quotaProfile.Name)
-	ns.Labels[v1alpha1.QuotaProfileLabelKey] = quotaProfile.Namespace + "." + quotaProfile.Name
+	ns.Labels[v1alpha1.QuotaProfileLabelKey] = quota.ProfileID(quotaProfile.Namespace, quotaProfile.Name)
 	ns.Labels[v1alpha1.QuotaProfileLastUpdateTimestamp] = fmt.Sprintf("%d", time.Now().UnixMicro())
+	delete(ns.Labels, v1alpha1.QuotaProfileRetainedLabelKey)
 }
@@ -17,21 +17,30 @@ limitations under the License.
 package v1
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func setupFakeClientWithScheme() *runtime.Scheme {
@@ -41,10 +50,6 @@ func setupFakeClientWithScheme() *runtime.Scheme {
 	return s
 }
 
-func getProfileID(namespace, profile string) string {
-	return fmt.Sprintf("%s.%s", namespace, profile)
-}
-
 var _ = Describe("Namespace Webhook", func() {
 	var (
 		ns             *v1.Namespace
@@ -116,6 +121,10 @@ var _ = Describe("Namespace Webhook", func() {
 		fakeClient = fake.NewClientBuilder().
 			WithScheme(s).
 			WithObjects(qp, qpIrrelevant).
+			WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileMatchNameField, index.QuotaProfileMatchName).
+			WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileSelectorLabelKeyField, index.QuotaProfileSelectorLabelKeys).
+			WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileSelectorAnnotationKeyField, index.QuotaProfileSelectorAnnotationKeys).
+			WithIndex(&quotav1alpha1.QuotaProfile{}, index.QuotaProfileMatchNamePrefixField, index.QuotaProfileMatchNamePrefix).
 			Build()
 
 		defaulter = NamespaceCustomDefaulter{
@@ -127,7 +136,7 @@ var _ = Describe("Namespace Webhook", func() {
 		It("should set the quota profile label for correct quota profile", func() {
 			err := defaulter.Default(ctx, ns)
 			Expect(err).NotTo(HaveOccurred(), "Expected no error when setting quota profile label")
-			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, getProfileID(qp.Namespace, qp.Name)))
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qp.Namespace, qp.Name)))
 		})
 
 		It("should set the quota profile label for nameselector quota profile", func() {
@@ -135,7 +144,202 @@ var _ = Describe("Namespace Webhook", func() {
 			Expect(err).NotTo(HaveOccurred(), "Expected no error when creating nameselector quota profile")
 			err = defaulter.Default(ctx, ns)
 			Expect(err).NotTo(HaveOccurred(), "Expected no error when setting quota profile label")
-			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, getProfileID(qpNameSelector.Namespace, qpNameSelector.Name)))
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qpNameSelector.Namespace, qpNameSelector.Name)))
+		})
+
+		It("should set the quota profile label for annotation selector quota profile", func() {
+			qpAnnotation := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "owner-quota-profile", Namespace: "default-3"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					Precedence: 20,
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{
+						MatchAnnotations: map[string]string{"owner": "alice"},
+					},
+				},
+			}
+			Expect(fakeClient.Create(ctx, qpAnnotation)).To(Succeed())
+
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qp.Namespace, qp.Name)))
+
+			ns.Annotations = map[string]string{"owner": "alice"}
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qpAnnotation.Namespace, qpAnnotation.Name)))
+		})
+
+		It("should only select namespaces by name prefix once they reach the minimum age", func() {
+			qpPrefix := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "prefix-quota-profile", Namespace: "default-3"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					Precedence: 20,
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{
+						MatchNamePrefix: lo.ToPtr("test-"),
+						MinAge:          &metav1.Duration{Duration: time.Hour},
+					},
+				},
+			}
+			Expect(fakeClient.Create(ctx, qpPrefix)).To(Succeed())
+
+			// namespaces that are being created have no creation timestamp yet
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qp.Namespace, qp.Name)))
+
+			ns.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qpPrefix.Namespace, qpPrefix.Name)))
+		})
+
+		It("should pick the lexically smaller profile of equally specific profiles with the same precedence", func() {
+			qp.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+			Expect(fakeClient.Update(ctx, qp)).To(Succeed())
+			qpTeam := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-quota-profile", Namespace: "a-team", CreationTimestamp: metav1.Now()},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					Precedence:        qp.Spec.Precedence,
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+				},
+			}
+			Expect(fakeClient.Create(ctx, qpTeam)).To(Succeed())
+
+			ns.Labels["team"] = "a"
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qpTeam.Namespace, qpTeam.Name)))
+		})
+
+		It("should prefer a name prefix over a label selector with a higher precedence", func() {
+			qpPrefix := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "prefix-quota-profile", Namespace: "default-3"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchNamePrefix: lo.ToPtr("test-")},
+				},
+			}
+			Expect(fakeClient.Create(ctx, qpPrefix)).To(Succeed())
+
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qpPrefix.Namespace, qpPrefix.Name)))
+		})
+	})
+
+	Context("When QuotaProfiles cannot be looked up under Defaulting Webhook", func() {
+		var failingClient client.Client
+
+		BeforeEach(func() {
+			failingClient = fake.NewClientBuilder().
+				WithScheme(s).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						return errors.New("cache is not synced")
+					},
+				}).
+				Build()
+		})
+
+		It("should reject the namespace by default", func() {
+			defaulter = NamespaceCustomDefaulter{c: failingClient}
+			Expect(defaulter.Default(ctx, ns)).To(MatchError(ContainSubstring("cache is not synced")))
+		})
+
+		It("should admit the namespace unchanged when failing open", func() {
+			defaulter = NamespaceCustomDefaulter{c: failingClient, FailOpen: true}
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			Expect(ns.Labels).To(Equal(map[string]string{"environment": "test"}))
+		})
+	})
+
+	Context("When validating Namespace labels under Validating Webhook", func() {
+		var (
+			validator NamespaceCustomValidator
+			oldNs     *v1.Namespace
+		)
+
+		requestBy := func(username string, groups ...string) context.Context {
+			return admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+				},
+			})
+		}
+
+		// update applies the change to a copy of the old namespace and runs the defaulter like the API server would
+		update := func(change func(*v1.Namespace)) *v1.Namespace {
+			newNs := oldNs.DeepCopy()
+			change(newNs)
+			Expect(defaulter.Default(ctx, newNs)).To(Succeed())
+			return newNs
+		}
+
+		BeforeEach(func() {
+			validator = NamespaceCustomValidator{
+				defaulter: &defaulter,
+				Authorizer: &ManagedObjectAuthorizer{
+					OperatorServiceAccount: "system:serviceaccount:operator-system:controller-manager",
+					BreakGlassGroups:       []string{"cluster-admins"},
+				},
+			}
+			Expect(defaulter.Default(ctx, ns)).To(Succeed())
+			oldNs = ns.DeepCopy()
+		})
+
+		It("should allow tenants to change unrelated labels", func() {
+			newNs := update(func(n *v1.Namespace) { n.Labels["team"] = "a" })
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().NotTo(HaveOccurred())
+		})
+
+		It("should reject tenants switching to another quota profile", func() {
+			qpIrrelevant.Spec.Precedence = 100
+			Expect(fakeClient.Update(ctx, qpIrrelevant)).To(Succeed())
+
+			// the defaulter binds the namespace to the profile that selects it, whatever label the tenant set
+			newNs := update(func(n *v1.Namespace) {
+				n.Labels[quotav1alpha1.QuotaProfileLabelKey] = quota.ProfileID(qpIrrelevant.Namespace, qpIrrelevant.Name)
+			})
+			Expect(newNs.Labels).To(HaveKeyWithValue(quotav1alpha1.QuotaProfileLabelKey, quota.ProfileID(qp.Namespace, qp.Name)))
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().NotTo(HaveOccurred())
+
+			By("rejecting the label when it reaches the validator changed")
+			newNs = oldNs.DeepCopy()
+			newNs.Labels[quotav1alpha1.QuotaProfileLabelKey] = quota.ProfileID(qpIrrelevant.Namespace, qpIrrelevant.Name)
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().To(HaveOccurred())
+		})
+
+		It("should reject tenants creating a namespace with retained labels", func() {
+			newNs := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "escape", Labels: map[string]string{
+				quotav1alpha1.QuotaProfileLabelKey:         quota.ProfileID(qpIrrelevant.Namespace, qpIrrelevant.Name),
+				quotav1alpha1.QuotaProfileRetainedLabelKey: "true",
+			}}}
+			Expect(defaulter.Default(ctx, newNs)).To(Succeed())
+			Expect(validator.ValidateCreate(requestBy("tenant"), newNs)).Error().To(HaveOccurred())
+		})
+
+		It("should allow the operator and cluster admins to change the quota profile label", func() {
+			newNs := update(func(n *v1.Namespace) { n.Labels[quotav1alpha1.QuotaProfileGenerationLabelKey] = "2" })
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().To(HaveOccurred())
+			Expect(validator.ValidateUpdate(requestBy("system:serviceaccount:operator-system:controller-manager"), oldNs, newNs)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateUpdate(requestBy("admin", "cluster-admins"), oldNs, newNs)).Error().NotTo(HaveOccurred())
+		})
+
+		It("should only lock the selector labels when enabled", func() {
+			newNs := update(func(n *v1.Namespace) { delete(n.Labels, "environment") })
+			Expect(newNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey))
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().NotTo(HaveOccurred())
+
+			validator.LockSelectorLabels = true
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().To(MatchError(ContainSubstring("environment")))
+		})
+
+		It("should lock the selector annotations when enabled", func() {
+			qp.Spec.NamespaceSelector.MatchAnnotations = map[string]string{"owner": "team-a"}
+			Expect(fakeClient.Update(ctx, qp)).To(Succeed())
+			oldNs.Annotations = map[string]string{"owner": "team-a"}
+
+			newNs := update(func(n *v1.Namespace) { n.Annotations["owner"] = "team-b" })
+			Expect(newNs.Labels).NotTo(HaveKey(quotav1alpha1.QuotaProfileLabelKey))
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().NotTo(HaveOccurred())
+
+			validator.LockSelectorLabels = true
+			Expect(validator.ValidateUpdate(requestBy("tenant"), oldNs, newNs)).Error().To(MatchError(
+				"only the operator and break-glass users are allowed to change the annotations owner of namespaces"))
+			Expect(validator.ValidateUpdate(requestBy("admin", "cluster-admins"), oldNs, newNs)).Error().NotTo(HaveOccurred())
 		})
 	})
 
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var objecttemplatelog = logf.Log.WithName("objecttemplate-resource")
+
+// SetupObjectTemplateWebhooksWithManager registers a webhook for every kind that QuotaProfiles can template.
+// Only the operator and the break-glass users of the authorizer may mutate managed objects of these kinds.
+func SetupObjectTemplateWebhooksWithManager(mgr ctrl.Manager, authorizer *ManagedObjectAuthorizer) error {
+	for _, obj := range []runtime.Object{&networkingv1.NetworkPolicy{}, &rbacv1.RoleBinding{}, &policyv1.PodDisruptionBudget{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).
+			WithValidator(&ObjectTemplateCustomValidator{Authorizer: authorizer}).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-networking-k8s-io-v1-networkpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.k8s.io,resources=networkpolicies,verbs=create;update;delete,versions=v1,name=vnetworkpolicy-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-rbac-authorization-k8s-io-v1-rolebinding,mutating=false,failurePolicy=fail,sideEffects=None,groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=create;update;delete,versions=v1,name=vrolebinding-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-policy-v1-poddisruptionbudget,mutating=false,failurePolicy=fail,sideEffects=None,groups=policy,resources=poddisruptionbudgets,verbs=create;update;delete,versions=v1,name=vpoddisruptionbudget-v1.kb.io,admissionReviewVersions=v1
+
+// ObjectTemplateCustomValidator struct is responsible for validating the objects rendered from the object
+// templates of QuotaProfiles, e.g. NetworkPolicies, when they are created, updated, or deleted.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+type ObjectTemplateCustomValidator struct {
+	// Authorizer decides who may mutate managed objects, everyone is denied when it is nil
+	Authorizer *ManagedObjectAuthorizer
+}
+
+var _ webhook.CustomValidator = &ObjectTemplateCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the templated kinds.
+func (v *ObjectTemplateCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, nil, obj, "create")
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the templated kinds.
+func (v *ObjectTemplateCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, oldObj, newObj, "update")
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the templated kinds.
+func (v *ObjectTemplateCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(ctx, nil, obj, "delete")
+}
+
+// validate denies the operation on objects managed by a quota profile unless the authorizer allows the user.
+// For updates, oldObj is the object before the update, an object that loses its profile label is still managed.
+func (v *ObjectTemplateCustomValidator) validate(ctx context.Context, oldObj, obj runtime.Object, operation string) error {
+	o, ok := obj.(client.Object)
+	if !ok {
+		objecttemplatelog.Error(nil, "received invalid object type", "got", fmt.Sprintf("%T", obj))
+		return fmt.Errorf("expected a Kubernetes object but got %T", obj)
+	}
+	kind := fmt.Sprintf("%T", obj)
+	if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind != "" {
+		kind = gvk.Kind
+	}
+	objecttemplatelog.Info("validating object "+operation, "kind", kind, "name", o.GetName(), "namespace", o.GetNamespace())
+
+	wasManaged := false
+	if old, ok := oldObj.(client.Object); ok {
+		wasManaged = isManagedByQuotaProfile(old.GetLabels())
+	}
+	if !wasManaged && !isManagedByQuotaProfile(o.GetLabels()) {
+		objecttemplatelog.Info("object is not managed by quota profile, skipping validation", "kind", kind, "name", o.GetName(), "namespace", o.GetNamespace())
+		return nil
+	}
+
+	if !v.Authorizer.isAllowed(ctx, obj, operation) {
+		objecttemplatelog.Info("unauthorized request", "kind", kind, "name", o.GetName(), "namespace", o.GetNamespace())
+		return fmt.Errorf("only the operator and break-glass users are allowed to %s managed %s objects", operation, kind)
+	}
+
+	objecttemplatelog.Info("object "+operation+" validated successfully", "kind", kind, "name", o.GetName(), "namespace", o.GetNamespace())
+	return nil
+}
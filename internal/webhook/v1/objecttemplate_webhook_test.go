@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("ObjectTemplate Webhook", func() {
+	var (
+		ctx          context.Context
+		managedObj   *networkingv1.NetworkPolicy
+		unmanagedObj *networkingv1.NetworkPolicy
+		validator    ObjectTemplateCustomValidator
+	)
+
+	requestBy := func(username string, groups ...string) context.Context {
+		return admission.NewContextWithRequest(context.TODO(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		managedObj = &networkingv1.NetworkPolicy{
+			TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "networking.k8s.io/v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default-tenant-0-networkpolicy",
+				Namespace: "team-a",
+				Labels:    map[string]string{v1alpha1.QuotaProfileLabelKey: "default.tenant"},
+			},
+		}
+		unmanagedObj = &networkingv1.NetworkPolicy{
+			TypeMeta:   metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "networking.k8s.io/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "allow-ingress", Namespace: "team-a"},
+		}
+
+		ctx = requestBy("test-user")
+		validator = ObjectTemplateCustomValidator{}
+	})
+
+	Context("When creating, updating or deleting templated objects under Validating Webhook", func() {
+		It("Should deny mutations by users if the object is managed by the operator", func() {
+			Expect(validator.ValidateCreate(ctx, managedObj)).Error().To(MatchError(ContainSubstring("managed NetworkPolicy objects")))
+			Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().To(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, managedObj)).Error().To(HaveOccurred())
+		})
+
+		It("Should allow mutations by users if the object is not managed by the operator", func() {
+			Expect(validator.ValidateCreate(ctx, unmanagedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, unmanagedObj, unmanagedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, unmanagedObj)).Error().ToNot(HaveOccurred())
+		})
+
+		It("Should deny updates by users that remove the profile label of a managed object", func() {
+			Expect(validator.ValidateUpdate(ctx, managedObj, unmanagedObj)).Error().To(HaveOccurred())
+		})
+
+		It("Should protect every templated kind", func() {
+			rb := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{
+				Name:   "default-tenant-0-rolebinding",
+				Labels: map[string]string{v1alpha1.QuotaProfileLabelKey: "default.tenant"},
+			}}
+			Expect(validator.ValidateDelete(ctx, rb)).Error().To(HaveOccurred())
+		})
+	})
+
+	Context("When authorizing mutations of managed templated objects", func() {
+		var recorder *record.FakeRecorder
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			validator = ObjectTemplateCustomValidator{Authorizer: &ManagedObjectAuthorizer{
+				OperatorServiceAccount: "system:serviceaccount:operator-system:controller-manager",
+				BreakGlassUsers:        []string{"admin@example.com"},
+				Recorder:               recorder,
+			}}
+		})
+
+		It("Should allow the operator service account", func() {
+			ctx := requestBy("system:serviceaccount:operator-system:controller-manager")
+			Expect(validator.ValidateCreate(ctx, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should allow break-glass users and record an event", func() {
+			Expect(validator.ValidateDelete(requestBy("admin@example.com"), managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(BreakGlassEventReason), ContainSubstring("admin@example.com"))))
+		})
+	})
+})
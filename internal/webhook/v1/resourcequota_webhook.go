@@ -19,7 +19,6 @@ package v1
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
@@ -35,10 +34,11 @@ import (
 var resourcequotalog = logf.Log.WithName("resourcequota-resource")
 
 // SetupResourceQuotaWebhookWithManager registers the webhook for ResourceQuota in the manager.
-func SetupResourceQuotaWebhookWithManager(mgr ctrl.Manager) error {
+// Only the operator and the break-glass users of the authorizer may mutate managed resource quotas.
+func SetupResourceQuotaWebhookWithManager(mgr ctrl.Manager, authorizer *ManagedObjectAuthorizer) error {
 	resourcequotalog.Info("setting up resourcequota webhook with manager")
 	return ctrl.NewWebhookManagedBy(mgr).For(&v1.ResourceQuota{}).
-		WithValidator(&ResourceQuotaCustomValidator{}).
+		WithValidator(&ResourceQuotaCustomValidator{Authorizer: authorizer}).
 		Complete()
 }
 
@@ -52,6 +52,8 @@ func SetupResourceQuotaWebhookWithManager(mgr ctrl.Manager) error {
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type ResourceQuotaCustomValidator struct {
+	// Authorizer decides who may mutate managed resource quotas, everyone is denied when it is nil
+	Authorizer *ManagedObjectAuthorizer
 }
 
 var _ webhook.CustomValidator = &ResourceQuotaCustomValidator{}
@@ -70,9 +72,9 @@ func (v *ResourceQuotaCustomValidator) ValidateCreate(ctx context.Context, obj r
 		return nil, nil
 	}
 
-	if !isServiceAccount(ctx) {
+	if !v.Authorizer.isAllowed(ctx, resourcequota, "create") {
 		resourcequotalog.Info("unauthorized request", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
-		return nil, fmt.Errorf("only service accounts are allowed to create managed resource quotas")
+		return nil, fmt.Errorf("only the operator and break-glass users are allowed to create managed resource quotas")
 	}
 
 	resourcequotalog.Info("resourcequota creation validated successfully", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
@@ -81,6 +83,11 @@ func (v *ResourceQuotaCustomValidator) ValidateCreate(ctx context.Context, obj r
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ResourceQuota.
 func (v *ResourceQuotaCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldResourceQuota, ok := oldObj.(*v1.ResourceQuota)
+	if !ok {
+		resourcequotalog.Error(nil, "received invalid object type", "expected", "ResourceQuota", "got", fmt.Sprintf("%T", oldObj))
+		return nil, fmt.Errorf("expected a ResourceQuota object for the oldObj but got %T", oldObj)
+	}
 	resourcequota, ok := newObj.(*v1.ResourceQuota)
 	if !ok {
 		resourcequotalog.Error(nil, "received invalid object type", "expected", "ResourceQuota", "got", fmt.Sprintf("%T", newObj))
@@ -88,14 +95,15 @@ func (v *ResourceQuotaCustomValidator) ValidateUpdate(ctx context.Context, oldOb
 	}
 	resourcequotalog.Info("validating resourcequota update", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
 
-	if !isManagedByQuotaProfile(resourcequota.GetLabels()) {
+	// removing the profile label in the same update would otherwise release the object from the protection
+	if !isManagedByQuotaProfile(oldResourceQuota.GetLabels()) && !isManagedByQuotaProfile(resourcequota.GetLabels()) {
 		resourcequotalog.Info("resourcequota is not managed by quota profile, skipping validation", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
 		return nil, nil
 	}
 
-	if !isServiceAccount(ctx) {
+	if !v.Authorizer.isAllowed(ctx, resourcequota, "update") {
 		resourcequotalog.Info("unauthorized request", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
-		return nil, fmt.Errorf("only service accounts are allowed to update managed resource quotas")
+		return nil, fmt.Errorf("only the operator and break-glass users are allowed to update managed resource quotas")
 	}
 
 	resourcequotalog.Info("resourcequota update validated successfully", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
@@ -116,9 +124,9 @@ func (v *ResourceQuotaCustomValidator) ValidateDelete(ctx context.Context, obj r
 		return nil, nil
 	}
 
-	if !isServiceAccount(ctx) {
+	if !v.Authorizer.isAllowed(ctx, resourcequota, "delete") {
 		resourcequotalog.Info("unauthorized request", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
-		return nil, fmt.Errorf("only service accounts are allowed to delete managed resource quotas")
+		return nil, fmt.Errorf("only the operator and break-glass users are allowed to delete managed resource quotas")
 	}
 
 	resourcequotalog.Info("resourcequota deletion validated successfully", "name", resourcequota.GetName(), "namespace", resourcequota.GetNamespace())
@@ -131,12 +139,3 @@ func isManagedByQuotaProfile(labels map[string]string) bool {
 	}
 	return labels[v1alpha1.QuotaProfileLabelKey] != ""
 }
-
-func isServiceAccount(ctx context.Context) bool {
-	req, err := admission.RequestFromContext(ctx)
-	if err != nil {
-		resourcequotalog.Error(err, "failed to get request from context")
-		return false
-	}
-	return strings.HasPrefix(req.UserInfo.Username, "system:serviceaccount:")
-}
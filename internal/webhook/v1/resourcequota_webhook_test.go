@@ -26,6 +26,7 @@ import (
 	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
@@ -98,6 +99,10 @@ var _ = Describe("ResourceQuota Webhook", func() {
 			Expect(validator.ValidateUpdate(ctx, unmanagedObj, unmanagedObj)).Error().ToNot(HaveOccurred())
 		})
 
+		It("Should deny update by user that removes the profile label of a managed ResourceQuota", func() {
+			Expect(validator.ValidateUpdate(ctx, managedObj, unmanagedObj)).Error().To(HaveOccurred())
+		})
+
 		It("Should deny deletion by user if a ResourceQuota is managed by operator", func() {
 			Expect(validator.ValidateDelete(ctx, managedObj)).Error().To(HaveOccurred())
 		})
@@ -106,4 +111,64 @@ var _ = Describe("ResourceQuota Webhook", func() {
 			Expect(validator.ValidateDelete(ctx, unmanagedObj)).Error().ToNot(HaveOccurred())
 		})
 	})
+	Context("When authorizing mutations of managed ResourceQuotas", func() {
+		var recorder *record.FakeRecorder
+
+		requestBy := func(username string, groups ...string) context.Context {
+			return admission.NewContextWithRequest(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+				},
+			})
+		}
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			validator = ResourceQuotaCustomValidator{Authorizer: &ManagedObjectAuthorizer{
+				OperatorServiceAccount: "system:serviceaccount:operator-system:controller-manager",
+				BreakGlassUsers:        []string{"admin@example.com"},
+				BreakGlassGroups:       []string{"sre"},
+				Recorder:               recorder,
+			}}
+		})
+
+		It("Should allow the operator service account", func() {
+			ctx := requestBy("system:serviceaccount:operator-system:controller-manager")
+			Expect(validator.ValidateCreate(ctx, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should deny other service accounts", func() {
+			ctx := requestBy("system:serviceaccount:tenant:ci-bot", "system:serviceaccounts")
+			Expect(validator.ValidateCreate(ctx, managedObj)).Error().To(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().To(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, managedObj)).Error().To(HaveOccurred())
+		})
+
+		It("Should let kube-controller-manager delete the managed objects of a bound namespace being deleted", func() {
+			for _, username := range []string{
+				"system:serviceaccount:kube-system:namespace-controller",
+				"system:serviceaccount:kube-system:generic-garbage-collector",
+				"system:kube-controller-manager",
+			} {
+				ctx := requestBy(username, "system:serviceaccounts", "system:serviceaccounts:kube-system")
+				Expect(validator.ValidateDelete(ctx, managedObj)).Error().ToNot(HaveOccurred(), username)
+				Expect(validator.ValidateCreate(ctx, managedObj)).Error().To(HaveOccurred(), username)
+				Expect(validator.ValidateUpdate(ctx, managedObj, managedObj)).Error().To(HaveOccurred(), username)
+			}
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should allow break-glass users and record an event", func() {
+			Expect(validator.ValidateDelete(requestBy("admin@example.com"), managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(BreakGlassEventReason), ContainSubstring("admin@example.com"))))
+		})
+
+		It("Should allow members of break-glass groups and record an event", func() {
+			Expect(validator.ValidateUpdate(requestBy("jane", "developers", "sre"), managedObj, managedObj)).Error().ToNot(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("jane")))
+		})
+	})
 })
@@ -41,6 +41,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	v1 "k8s.io/api/core/v1"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -74,6 +77,9 @@ var _ = BeforeSuite(func() {
 	err = admissionv1.AddToScheme(scheme)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = quotav1alpha1.AddToScheme(scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	// +kubebuilder:scaffold:scheme
 
 	By("bootstrapping test environment")
@@ -114,13 +120,22 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = SetupNamespaceWebhookWithManager(mgr)
+	err = index.SetupIndexes(ctx, mgr.GetFieldIndexer())
+	Expect(err).NotTo(HaveOccurred())
+
+	err = SetupNamespaceWebhookWithManager(mgr, NamespaceWebhookOptions{Authorizer: &ManagedObjectAuthorizer{}})
+	Expect(err).NotTo(HaveOccurred())
+
+	err = SetupResourceQuotaWebhookWithManager(mgr, &ManagedObjectAuthorizer{})
+	Expect(err).NotTo(HaveOccurred())
+
+	err = SetupLimitRangeWebhookWithManager(mgr, &ManagedObjectAuthorizer{})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = SetupResourceQuotaWebhookWithManager(mgr)
+	err = SetupObjectTemplateWebhooksWithManager(mgr, &ManagedObjectAuthorizer{})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = SetupLimitRangeWebhookWithManager(mgr)
+	err = SetupAggregateQuotaWebhooksWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:webhook
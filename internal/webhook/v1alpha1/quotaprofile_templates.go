@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// validateObjectTemplates checks that the user of the admission request could create the RoleBindings of the
+// profile themselves, the operator would otherwise create them on the user's behalf in every selected namespace.
+// Every template is then created with a server-side dry run in the namespace of the profile, so that the API server
+// validates it like the controller's create would, the CRD schema only checks its apiVersion and kind.
+func validateObjectTemplates(ctx context.Context, c client.Client, quotaprofile *quotav1alpha1.QuotaProfile) (field.ErrorList, error) {
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec", "objectTemplates")
+
+	templates := make([]*unstructured.Unstructured, len(quotaprofile.Spec.ObjectTemplates))
+	for i, t := range quotaprofile.Spec.ObjectTemplates {
+		template, err := decodeObjectTemplate(t)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("template"), string(t.Template.Raw), err.Error()))
+			continue
+		}
+		templates[i] = template
+	}
+
+	var user *authenticationv1.UserInfo
+	for i, template := range templates {
+		if template == nil || template.GroupVersionKind() != rbacv1.SchemeGroupVersion.WithKind("RoleBinding") {
+			continue
+		}
+		if user == nil {
+			req, err := admission.RequestFromContext(ctx)
+			if err != nil {
+				quotaprofilelog.Error(err, "failed to get request from context")
+				return nil, err
+			}
+			user = &req.UserInfo
+		}
+
+		roleBinding := &rbacv1.RoleBinding{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template.Object, roleBinding); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("template"), template.GetKind(), err.Error()))
+			continue
+		}
+		denied, err := reviewRoleBinding(ctx, c, *user, bindingNamespace(quotaprofile), roleBinding.RoleRef)
+		if err != nil {
+			quotaprofilelog.Error(err, "failed to review access of user", "user", user.Username)
+			return nil, err
+		}
+		if denied != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("template", "roleRef"), denied))
+		}
+	}
+	if len(allErrs) > 0 {
+		return allErrs, nil
+	}
+
+	for i, template := range templates {
+		obj := template.DeepCopy()
+		obj.SetNamespace(quotaprofile.Namespace)
+		obj.SetGenerateName(quotaprofile.Name + "-")
+		if err := c.Create(ctx, obj, client.DryRunAll); err != nil {
+			if !apierrors.IsInvalid(err) && !apierrors.IsBadRequest(err) && !apierrors.IsForbidden(err) && !apierrors.IsNotFound(err) {
+				quotaprofilelog.Error(err, "failed to dry-run object template", "kind", template.GetKind())
+				return nil, err
+			}
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("template"), template.GetKind(), err.Error()))
+		}
+	}
+	return allErrs, nil
+}
+
+// bindingNamespace returns the namespace the RoleBindings of the profile are reviewed in, the selected namespace for
+// a profile that selects it by name and all namespaces otherwise, as any namespace may be selected later.
+func bindingNamespace(quotaprofile *quotav1alpha1.QuotaProfile) string {
+	return lo.FromPtr(quotaprofile.Spec.NamespaceSelector.MatchName)
+}
+
+// reviewRoleBinding checks with SubjectAccessReviews that the user may create RoleBindings and bind the role in the
+// namespace. It returns why the user may not, or an empty string when the user may.
+func reviewRoleBinding(ctx context.Context, c client.Client, user authenticationv1.UserInfo, namespace string, roleRef rbacv1.RoleRef) (string, error) {
+	where := "in namespace " + namespace
+	if namespace == "" {
+		where = "in all namespaces"
+	}
+
+	roleResource := "roles"
+	if roleRef.Kind == "ClusterRole" {
+		roleResource = "clusterroles"
+	}
+	for _, attributes := range []authorizationv1.ResourceAttributes{
+		{Namespace: namespace, Verb: "create", Group: rbacv1.GroupName, Resource: "rolebindings"},
+		{Namespace: namespace, Verb: "bind", Group: rbacv1.GroupName, Resource: roleResource, Name: roleRef.Name},
+	} {
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				Groups: user.Groups,
+				UID:    user.UID,
+				Extra: lo.MapValues(user.Extra, func(value authenticationv1.ExtraValue, _ string) authorizationv1.ExtraValue {
+					return authorizationv1.ExtraValue(value)
+				}),
+				ResourceAttributes: &attributes,
+			},
+		}
+		if err := c.Create(ctx, review); err != nil {
+			return "", err
+		}
+		if !review.Status.Allowed {
+			quotaprofilelog.Info("denying role binding template", "user", user.Username, "verb", attributes.Verb, "resource", attributes.Resource, "name", attributes.Name)
+			if attributes.Verb == "create" {
+				return fmt.Sprintf("user %s is not allowed to create rolebindings %s", user.Username, where), nil
+			}
+			return fmt.Sprintf("user %s is not allowed to bind %s %s %s", user.Username, roleRef.Kind, roleRef.Name, where), nil
+		}
+	}
+	return "", nil
+}
+
+// decodeObjectTemplate returns the object of the template, which is held as raw JSON in admission requests.
+func decodeObjectTemplate(t quotav1alpha1.ObjectTemplate) (*unstructured.Unstructured, error) {
+	if len(t.Template.Raw) == 0 && t.Template.Object != nil {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(t.Template.Object)
+		if err != nil {
+			return nil, err
+		}
+		return &unstructured.Unstructured{Object: content}, nil
+	}
+
+	obj, _, err := unstructured.UnstructuredJSONScheme.Decode(t.Template.Raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("expected an object but got %T", obj)
+	}
+	return u, nil
+}
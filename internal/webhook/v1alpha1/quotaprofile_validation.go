@@ -0,0 +1,494 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+// The rules below follow the ResourceQuota and LimitRange validation of the Kubernetes API server,
+// so that profiles which would fail at creation time of the managed objects are rejected at admission.
+
+var (
+	// standardQuotaResources are the resource names a ResourceQuota accepts without a domain prefix.
+	standardQuotaResources = sets.New(
+		string(v1.ResourcePods),
+		string(v1.ResourceServices),
+		string(v1.ResourceReplicationControllers),
+		string(v1.ResourceQuotas),
+		string(v1.ResourceSecrets),
+		string(v1.ResourceConfigMaps),
+		string(v1.ResourcePersistentVolumeClaims),
+		string(v1.ResourceServicesNodePorts),
+		string(v1.ResourceServicesLoadBalancers),
+		string(v1.ResourceCPU),
+		string(v1.ResourceMemory),
+		string(v1.ResourceEphemeralStorage),
+		string(v1.ResourceRequestsCPU),
+		string(v1.ResourceRequestsMemory),
+		string(v1.ResourceRequestsStorage),
+		string(v1.ResourceRequestsEphemeralStorage),
+		string(v1.ResourceLimitsCPU),
+		string(v1.ResourceLimitsMemory),
+		string(v1.ResourceLimitsEphemeralStorage),
+	)
+
+	// podComputeQuotaResources are the resources that can be tracked by the Terminating, NotTerminating
+	// and PriorityClass scopes.
+	podComputeQuotaResources = sets.New(
+		string(v1.ResourcePods),
+		string(v1.ResourceCPU),
+		string(v1.ResourceMemory),
+		string(v1.ResourceEphemeralStorage),
+		string(v1.ResourceRequestsCPU),
+		string(v1.ResourceRequestsMemory),
+		string(v1.ResourceRequestsEphemeralStorage),
+		string(v1.ResourceLimitsCPU),
+		string(v1.ResourceLimitsMemory),
+		string(v1.ResourceLimitsEphemeralStorage),
+	)
+
+	// standardContainerResources are the resource names a LimitRange accepts for Pods and Containers.
+	standardContainerResources = sets.New(
+		string(v1.ResourceCPU),
+		string(v1.ResourceMemory),
+		string(v1.ResourceEphemeralStorage),
+	)
+
+	supportedQuotaScopes = sets.New(
+		v1.ResourceQuotaScopeTerminating,
+		v1.ResourceQuotaScopeNotTerminating,
+		v1.ResourceQuotaScopeBestEffort,
+		v1.ResourceQuotaScopeNotBestEffort,
+		v1.ResourceQuotaScopePriorityClass,
+		v1.ResourceQuotaScopeCrossNamespacePodAffinity,
+	)
+
+	supportedLimitTypes = sets.New(
+		v1.LimitTypePod,
+		v1.LimitTypeContainer,
+		v1.LimitTypePersistentVolumeClaim,
+	)
+)
+
+// ValidateQuotaProfileSpec validates the label selector of the namespace selector, the ResourceQuota and
+// LimitRange specs, the budget and the aggregate hard limits embedded in a QuotaProfile.
+func ValidateQuotaProfileSpec(spec *quotav1alpha1.QuotaProfileSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	selector := &metav1.LabelSelector{MatchLabels: spec.NamespaceSelector.MatchLabels, MatchExpressions: spec.NamespaceSelector.MatchExpressions}
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(selector, metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("namespaceSelector"))...)
+
+	for i := range spec.ResourceQuotaSpecs {
+		allErrs = append(allErrs, validateResourceQuotaSpec(&spec.ResourceQuotaSpecs[i], fldPath.Child("resourceQuotaSpecs").Index(i))...)
+	}
+
+	for i := range spec.LimitRangeSpecs {
+		allErrs = append(allErrs, validateLimitRangeSpec(&spec.LimitRangeSpecs[i], fldPath.Child("limitRangeSpecs").Index(i))...)
+	}
+
+	if spec.Budget != nil {
+		allErrs = append(allErrs, validateQuotaBudget(spec.Budget, fldPath.Child("budget"))...)
+	}
+
+	aggregatePath := fldPath.Child("aggregateHard")
+	for name, quantity := range spec.AggregateHard {
+		resPath := aggregatePath.Key(string(name))
+		allErrs = append(allErrs, validateQuotaResourceName(string(name), resPath)...)
+		allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+	}
+
+	if spec.Recommendation != nil {
+		allErrs = append(allErrs, validateRecommendationPolicy(spec.Recommendation, fldPath.Child("recommendation"))...)
+	}
+
+	if spec.QuotaRequests != nil {
+		allErrs = append(allErrs, validateQuotaRequestPolicy(spec.QuotaRequests, fldPath.Child("quotaRequests"))...)
+	}
+
+	return allErrs
+}
+
+// validateRecommendationPolicy checks that the min and max bounds are valid quota resources and that no min is
+// above the max of the same resource.
+func validateRecommendationPolicy(policy *quotav1alpha1.RecommendationPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, bound := range []struct {
+		name string
+		list v1.ResourceList
+	}{{"min", policy.Min}, {"max", policy.Max}} {
+		for name, quantity := range bound.list {
+			resPath := fldPath.Child(bound.name).Key(string(name))
+			allErrs = append(allErrs, validateQuotaResourceName(string(name), resPath)...)
+			allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+		}
+	}
+
+	for _, name := range sets.List(sets.KeySet(policy.Min)) {
+		lower := policy.Min[name]
+		if upper, ok := policy.Max[name]; ok && lower.Cmp(upper) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("min").Key(string(name)), lower.String(), fmt.Sprintf("must be less than or equal to max %s", upper.String())))
+		}
+	}
+
+	return allErrs
+}
+
+// validateQuotaRequestPolicy checks that the ceiling uses valid quota resources and that only resources with a
+// ceiling are approved automatically.
+func validateQuotaRequestPolicy(policy *quotav1alpha1.QuotaRequestPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, quantity := range policy.Ceiling {
+		resPath := fldPath.Child("ceiling").Key(string(name))
+		allErrs = append(allErrs, validateQuotaResourceName(string(name), resPath)...)
+		allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+	}
+
+	for name, quantity := range policy.AutoApprove {
+		resPath := fldPath.Child("autoApprove").Key(string(name))
+		if _, ok := policy.Ceiling[name]; !ok {
+			allErrs = append(allErrs, field.Invalid(resPath, quantity.String(), fmt.Sprintf("resource %s has no ceiling", name)))
+			continue
+		}
+		allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+	}
+
+	return allErrs
+}
+
+// validateQuotaBudget checks that the budget can be rendered into ResourceQuotas and that the fixed
+// allocations only use resources of the budget and fit into it.
+func validateQuotaBudget(budget *quotav1alpha1.QuotaBudget, fldPath *field.Path) field.ErrorList {
+	allErrs := validateResourceQuotaSpec(&v1.ResourceQuotaSpec{Hard: budget.Hard}, fldPath)
+
+	allocated := v1.ResourceList{}
+	allocationsPath := fldPath.Child("allocations")
+	for _, namespace := range sets.List(sets.KeySet(budget.Allocations)) {
+		nsPath := allocationsPath.Key(namespace)
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(nsPath, namespace, msg))
+		}
+
+		for name, quantity := range budget.Allocations[namespace] {
+			resPath := nsPath.Key(string(name))
+			if _, ok := budget.Hard[name]; !ok {
+				allErrs = append(allErrs, field.Invalid(resPath, quantity.String(), fmt.Sprintf("resource %s is not part of the budget", name)))
+				continue
+			}
+			allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+
+			total := allocated[name]
+			total.Add(quantity)
+			allocated[name] = total
+		}
+	}
+
+	for name, total := range allocated {
+		if hard := budget.Hard[name]; total.Cmp(hard) > 0 {
+			allErrs = append(allErrs, field.Invalid(allocationsPath, total.String(), fmt.Sprintf("allocations of %s add up to more than the budget of %s", name, hard.String())))
+		}
+	}
+
+	return allErrs
+}
+
+func validateResourceQuotaSpec(spec *v1.ResourceQuotaSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	hardPath := fldPath.Child("hard")
+	for name, quantity := range spec.Hard {
+		resPath := hardPath.Key(string(name))
+		allErrs = append(allErrs, validateQuotaResourceName(string(name), resPath)...)
+		allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+	}
+
+	scopes := sets.New(spec.Scopes...)
+	scopesPath := fldPath.Child("scopes")
+	for _, scope := range spec.Scopes {
+		allErrs = append(allErrs, validateQuotaScope(scope, spec.Hard, scopesPath)...)
+	}
+
+	if spec.ScopeSelector != nil {
+		selectorPath := fldPath.Child("scopeSelector", "matchExpressions")
+		for i, req := range spec.ScopeSelector.MatchExpressions {
+			reqPath := selectorPath.Index(i)
+			allErrs = append(allErrs, validateQuotaScope(req.ScopeName, spec.Hard, reqPath.Child("scopeName"))...)
+			allErrs = append(allErrs, validateScopedResourceSelectorRequirement(req, reqPath)...)
+			scopes.Insert(req.ScopeName)
+		}
+	}
+
+	if scopes.HasAll(v1.ResourceQuotaScopeTerminating, v1.ResourceQuotaScopeNotTerminating) {
+		allErrs = append(allErrs, field.Invalid(scopesPath, spec.Scopes, "conflicting scopes Terminating and NotTerminating"))
+	}
+	if scopes.HasAll(v1.ResourceQuotaScopeBestEffort, v1.ResourceQuotaScopeNotBestEffort) {
+		allErrs = append(allErrs, field.Invalid(scopesPath, spec.Scopes, "conflicting scopes BestEffort and NotBestEffort"))
+	}
+
+	return allErrs
+}
+
+// validateQuotaScope checks that the scope is known and that every hard resource can be tracked by it.
+func validateQuotaScope(scope v1.ResourceQuotaScope, hard v1.ResourceList, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !supportedQuotaScopes.Has(scope) {
+		return append(allErrs, field.NotSupported(fldPath, scope, sets.List(supportedQuotaScopes)))
+	}
+
+	for name := range hard {
+		if !isResourceTrackedByScope(scope, string(name)) {
+			allErrs = append(allErrs, field.Invalid(fldPath, scope, fmt.Sprintf("unsupported scope applied to resource %s", name)))
+		}
+	}
+
+	return allErrs
+}
+
+func isResourceTrackedByScope(scope v1.ResourceQuotaScope, name string) bool {
+	switch scope {
+	case v1.ResourceQuotaScopeBestEffort, v1.ResourceQuotaScopeNotBestEffort:
+		return name == string(v1.ResourcePods) || name == "count/pods"
+	case v1.ResourceQuotaScopeTerminating, v1.ResourceQuotaScopeNotTerminating, v1.ResourceQuotaScopePriorityClass:
+		return podComputeQuotaResources.Has(name) || name == "count/pods" ||
+			strings.HasPrefix(name, "requests.hugepages-") || isExtendedQuotaResource(name)
+	default:
+		return true
+	}
+}
+
+func validateScopedResourceSelectorRequirement(req v1.ScopedResourceSelectorRequirement, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch req.ScopeName {
+	case v1.ResourceQuotaScopeTerminating, v1.ResourceQuotaScopeNotTerminating,
+		v1.ResourceQuotaScopeBestEffort, v1.ResourceQuotaScopeNotBestEffort,
+		v1.ResourceQuotaScopeCrossNamespacePodAffinity:
+		if req.Operator != v1.ScopeSelectorOpExists {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("operator"), req.Operator,
+				"must be 'Exists' when scope is any of ResourceQuotaScopeTerminating, ResourceQuotaScopeNotTerminating, ResourceQuotaScopeBestEffort, ResourceQuotaScopeNotBestEffort or ResourceQuotaScopeCrossNamespacePodAffinity"))
+		}
+	}
+
+	switch req.Operator {
+	case v1.ScopeSelectorOpIn, v1.ScopeSelectorOpNotIn:
+		if len(req.Values) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("values"), "must be at least one value when `operator` is 'In' or 'NotIn' for scope selector"))
+		}
+	case v1.ScopeSelectorOpExists, v1.ScopeSelectorOpDoesNotExist:
+		if len(req.Values) != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("values"), req.Values, "must be no value when `operator` is 'Exist' or 'DoesNotExist' for scope selector"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operator"), req.Operator,
+			[]v1.ScopeSelectorOperator{v1.ScopeSelectorOpIn, v1.ScopeSelectorOpNotIn, v1.ScopeSelectorOpExists, v1.ScopeSelectorOpDoesNotExist}))
+	}
+
+	return allErrs
+}
+
+func validateLimitRangeSpec(spec *v1.LimitRangeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	limitTypes := sets.New[v1.LimitType]()
+
+	for i, limit := range spec.Limits {
+		idxPath := fldPath.Child("limits").Index(i)
+
+		if !supportedLimitTypes.Has(limit.Type) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("type"), limit.Type, sets.List(supportedLimitTypes)))
+			continue
+		}
+		if limitTypes.Has(limit.Type) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("type"), limit.Type))
+		}
+		limitTypes.Insert(limit.Type)
+
+		if limit.Type == v1.LimitTypePod {
+			if len(limit.Default) > 0 {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("default"), "may not be specified when `type` is 'Pod'"))
+			}
+			if len(limit.DefaultRequest) > 0 {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("defaultRequest"), "may not be specified when `type` is 'Pod'"))
+			}
+		}
+
+		lists := map[string]v1.ResourceList{
+			"max":                  limit.Max,
+			"min":                  limit.Min,
+			"default":              limit.Default,
+			"defaultRequest":       limit.DefaultRequest,
+			"maxLimitRequestRatio": limit.MaxLimitRequestRatio,
+		}
+		keys := sets.New[string]()
+		for _, listName := range sets.List(sets.KeySet(lists)) {
+			for name, quantity := range lists[listName] {
+				resPath := idxPath.Child(listName).Key(string(name))
+				allErrs = append(allErrs, validateLimitRangeResourceName(limit.Type, string(name), resPath)...)
+				allErrs = append(allErrs, validateNonNegativeQuantity(quantity, resPath)...)
+				if isExtendedResource(string(name)) && quantity.MilliValue()%1000 != 0 {
+					allErrs = append(allErrs, field.Invalid(resPath, quantity.String(), "must be an integer"))
+				}
+				keys.Insert(string(name))
+			}
+		}
+
+		if limit.Type == v1.LimitTypePersistentVolumeClaim {
+			_, minFound := limit.Min[v1.ResourceStorage]
+			_, maxFound := limit.Max[v1.ResourceStorage]
+			if !minFound && !maxFound {
+				allErrs = append(allErrs, field.Required(idxPath, "either minimum or maximum storage value is required, but neither was provided"))
+			}
+		}
+
+		for _, name := range sets.List(keys) {
+			allErrs = append(allErrs, validateLimitRangeItemBounds(limit, v1.ResourceName(name), idxPath)...)
+		}
+	}
+
+	return allErrs
+}
+
+// validateLimitRangeItemBounds checks that min <= defaultRequest <= default <= max, that the
+// maxLimitRequestRatio is achievable and that resources that cannot be overcommitted are not.
+func validateLimitRangeItemBounds(limit v1.LimitRangeItem, name v1.ResourceName, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	minQ, minFound := limit.Min[name]
+	maxQ, maxFound := limit.Max[name]
+	defaultQ, defaultFound := limit.Default[name]
+	defaultRequestQ, defaultRequestFound := limit.DefaultRequest[name]
+	ratioQ, ratioFound := limit.MaxLimitRequestRatio[name]
+
+	key := string(name)
+	if minFound && maxFound && minQ.Cmp(maxQ) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("min").Key(key), minQ.String(), fmt.Sprintf("min value %s is greater than max value %s", minQ.String(), maxQ.String())))
+	}
+	if defaultRequestFound && minFound && minQ.Cmp(defaultRequestQ) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("defaultRequest").Key(key), defaultRequestQ.String(), fmt.Sprintf("min value %s is greater than default request value %s", minQ.String(), defaultRequestQ.String())))
+	}
+	if defaultRequestFound && maxFound && defaultRequestQ.Cmp(maxQ) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("defaultRequest").Key(key), defaultRequestQ.String(), fmt.Sprintf("default request value %s is greater than max value %s", defaultRequestQ.String(), maxQ.String())))
+	}
+	if defaultRequestFound && defaultFound && defaultRequestQ.Cmp(defaultQ) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("defaultRequest").Key(key), defaultRequestQ.String(), fmt.Sprintf("default request value %s is greater than default limit value %s", defaultRequestQ.String(), defaultQ.String())))
+	}
+	if defaultFound && minFound && minQ.Cmp(defaultQ) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("default").Key(key), defaultQ.String(), fmt.Sprintf("min value %s is greater than default value %s", minQ.String(), defaultQ.String())))
+	}
+	if defaultFound && maxFound && defaultQ.Cmp(maxQ) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("default").Key(key), defaultQ.String(), fmt.Sprintf("default value %s is greater than max value %s", defaultQ.String(), maxQ.String())))
+	}
+	if !isOvercommitAllowed(key) {
+		if defaultFound && defaultRequestFound && defaultQ.Cmp(defaultRequestQ) != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("defaultRequest").Key(key), defaultRequestQ.String(), fmt.Sprintf("default value %s must equal to defaultRequest value %s in %s", defaultQ.String(), defaultRequestQ.String(), key)))
+		}
+		if ratioFound {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("maxLimitRequestRatio").Key(key), fmt.Sprintf("may not be specified for %s, it cannot be overcommitted", key)))
+		}
+	}
+	if ratioFound {
+		if ratioQ.Cmp(resource.MustParse("1")) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxLimitRequestRatio").Key(key), ratioQ.String(), fmt.Sprintf("ratio %s is less than 1", ratioQ.String())))
+		}
+		if minFound && maxFound && minQ.Sign() > 0 {
+			maxRatio := float64(maxQ.MilliValue()) / float64(minQ.MilliValue())
+			if ratioQ.AsApproximateFloat64() > maxRatio {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("maxLimitRequestRatio").Key(key), ratioQ.String(), fmt.Sprintf("ratio %s is greater than max/min = %f", ratioQ.String(), maxRatio)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateQuotaResourceName(name string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, msg := range validation.IsQualifiedName(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	if len(allErrs) != 0 {
+		return allErrs
+	}
+
+	if !strings.Contains(name, "/") && !standardQuotaResources.Has(name) &&
+		!strings.HasPrefix(name, "hugepages-") && !strings.HasPrefix(name, "requests.hugepages-") {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, "must be a standard resource for quota"))
+	}
+
+	return allErrs
+}
+
+func validateLimitRangeResourceName(limitType v1.LimitType, name string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, msg := range validation.IsQualifiedName(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	if len(allErrs) != 0 {
+		return allErrs
+	}
+
+	switch limitType {
+	case v1.LimitTypePersistentVolumeClaim:
+		if name != string(v1.ResourceStorage) {
+			allErrs = append(allErrs, field.NotSupported(fldPath, name, []string{string(v1.ResourceStorage)}))
+		}
+	default:
+		if !strings.Contains(name, "/") && !standardContainerResources.Has(name) && !strings.HasPrefix(name, "hugepages-") {
+			allErrs = append(allErrs, field.Invalid(fldPath, name, "must be a standard limit type or fully qualified"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateNonNegativeQuantity(quantity resource.Quantity, fldPath *field.Path) field.ErrorList {
+	if quantity.Sign() < 0 {
+		return field.ErrorList{field.Invalid(fldPath, quantity.String(), "must be greater than or equal to 0")}
+	}
+	return nil
+}
+
+// isExtendedResource reports whether the name is an extended resource, e.g. nvidia.com/gpu.
+func isExtendedResource(name string) bool {
+	return strings.Contains(name, "/") && !strings.Contains(name, "kubernetes.io/") && !strings.HasPrefix(name, "requests.")
+}
+
+// isOvercommitAllowed reports whether the limit of the resource may be greater than its request,
+// which is not the case for huge pages and extended resources.
+func isOvercommitAllowed(name string) bool {
+	return !isExtendedResource(name) && !strings.HasPrefix(name, "hugepages-")
+}
+
+// isExtendedQuotaResource reports whether the name is a requests quota for an extended resource,
+// e.g. requests.nvidia.com/gpu.
+func isExtendedQuotaResource(name string) bool {
+	return strings.HasPrefix(name, "requests.") && strings.Contains(name, "/") &&
+		!strings.Contains(strings.TrimPrefix(name, "requests."), "kubernetes.io/")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateLimitRangeSpec(t *testing.T) {
+	quantities := func(values ...string) v1.ResourceList {
+		list := v1.ResourceList{}
+		for i := 0; i < len(values); i += 2 {
+			list[v1.ResourceName(values[i])] = resource.MustParse(values[i+1])
+		}
+		return list
+	}
+
+	tests := []struct {
+		name  string
+		limit v1.LimitRangeItem
+		// wantErr is a substring of the error, empty when the limit is valid.
+		wantErr string
+	}{
+		{
+			name: "container limits within bounds",
+			limit: v1.LimitRangeItem{
+				Type:                 v1.LimitTypeContainer,
+				Min:                  quantities("cpu", "100m", "memory", "64Mi"),
+				Max:                  quantities("cpu", "2", "memory", "2Gi"),
+				Default:              quantities("cpu", "500m", "memory", "256Mi"),
+				DefaultRequest:       quantities("cpu", "200m", "memory", "128Mi"),
+				MaxLimitRequestRatio: quantities("cpu", "4"),
+			},
+		},
+		{
+			name:    "min greater than max",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypeContainer, Min: quantities("cpu", "3"), Max: quantities("cpu", "2")},
+			wantErr: "spec.limits[0].min[cpu]",
+		},
+		{
+			name:    "maxLimitRequestRatio below 1",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypeContainer, MaxLimitRequestRatio: quantities("cpu", "500m")},
+			wantErr: "ratio 500m is less than 1",
+		},
+		{
+			name:    "default on a Pod limit",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypePod, Default: quantities("cpu", "1")},
+			wantErr: "spec.limits[0].default: Forbidden",
+		},
+		{
+			name:  "PersistentVolumeClaim limit with max storage",
+			limit: v1.LimitRangeItem{Type: v1.LimitTypePersistentVolumeClaim, Max: quantities("storage", "10Gi")},
+		},
+		{
+			name:  "PersistentVolumeClaim limit with min storage",
+			limit: v1.LimitRangeItem{Type: v1.LimitTypePersistentVolumeClaim, Min: quantities("storage", "1Gi")},
+		},
+		{
+			name:    "PersistentVolumeClaim limit without min or max storage",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypePersistentVolumeClaim, Default: quantities("storage", "1Gi")},
+			wantErr: "either minimum or maximum storage value is required",
+		},
+		{
+			name:    "PersistentVolumeClaim limit on cpu",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypePersistentVolumeClaim, Max: quantities("storage", "10Gi", "cpu", "1")},
+			wantErr: "spec.limits[0].max[cpu]: Unsupported value",
+		},
+		{
+			name: "extended resource with equal default and defaultRequest",
+			limit: v1.LimitRangeItem{
+				Type:           v1.LimitTypeContainer,
+				Default:        quantities("nvidia.com/gpu", "1"),
+				DefaultRequest: quantities("nvidia.com/gpu", "1"),
+			},
+		},
+		{
+			name: "extended resource with a default greater than defaultRequest",
+			limit: v1.LimitRangeItem{
+				Type:           v1.LimitTypeContainer,
+				Default:        quantities("nvidia.com/gpu", "2"),
+				DefaultRequest: quantities("nvidia.com/gpu", "1"),
+			},
+			wantErr: "default value 2 must equal to defaultRequest value 1 in nvidia.com/gpu",
+		},
+		{
+			name: "huge pages with a default greater than defaultRequest",
+			limit: v1.LimitRangeItem{
+				Type:           v1.LimitTypeContainer,
+				Default:        quantities("hugepages-2Mi", "4Mi"),
+				DefaultRequest: quantities("hugepages-2Mi", "2Mi"),
+			},
+			wantErr: "spec.limits[0].defaultRequest[hugepages-2Mi]",
+		},
+		{
+			name:    "extended resource with maxLimitRequestRatio",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypeContainer, MaxLimitRequestRatio: quantities("nvidia.com/gpu", "2")},
+			wantErr: "spec.limits[0].maxLimitRequestRatio[nvidia.com/gpu]: Forbidden",
+		},
+		{
+			name:    "fractional extended resource",
+			limit:   v1.LimitRangeItem{Type: v1.LimitTypeContainer, Max: quantities("nvidia.com/gpu", "500m")},
+			wantErr: "must be an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{tt.limit}}
+			errs := validateLimitRangeSpec(spec, field.NewPath("spec"))
+			if tt.wantErr == "" {
+				if len(errs) != 0 {
+					t.Fatalf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if !strings.Contains(errs.ToAggregate().Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestValidateLimitRangeSpecDuplicateType(t *testing.T) {
+	spec := &v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{
+		{Type: v1.LimitTypeContainer, Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		{Type: v1.LimitTypeContainer, Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+	}}
+
+	errs := validateLimitRangeSpec(spec, field.NewPath("spec"))
+	if len(errs) != 1 || errs[0].Type != field.ErrorTypeDuplicate || errs[0].Field != "spec.limits[1].type" {
+		t.Fatalf("expected a duplicate type error, got %v", errs)
+	}
+}
@@ -19,8 +19,13 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -28,6 +33,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
+	"github.com/abdullah599/namespace-quota-operator/internal/quota"
 	"github.com/samber/lo"
 )
 
@@ -56,6 +63,9 @@ func SetupQuotaProfileWebhookWithManager(mgr ctrl.Manager) error {
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type QuotaProfileCustomValidator struct {
+	// Offline skips the checks that need the API server, the access review of RoleBinding templates and the
+	// dry run of the object templates, for tools that validate profiles without a cluster
+	Offline bool
 }
 
 var _ webhook.CustomValidator = &QuotaProfileCustomValidator{}
@@ -92,21 +102,21 @@ func (v *QuotaProfileCustomValidator) validate(ctx context.Context, obj runtime.
 	}
 	quotaprofilelog.Info("validating quotaprofile", "name", quotaprofile.GetName(), "namespace", quotaprofile.GetNamespace())
 
-	if quotaprofile.Spec.NamespaceSelector.MatchLabels == nil && quotaprofile.Spec.NamespaceSelector.MatchName == nil {
-		quotaprofilelog.Info("validation failed", "reason", "no selector specified")
-		return nil, fmt.Errorf("one of namespaceSelector.matchLabels or namespaceSelector.matchName must be set")
+	// the selector invariants and the precedence range are enforced by the CEL rules of the CRD schema,
+	// this webhook only checks what needs other objects or the Kubernetes validation of the embedded specs
+	if errs := ValidateQuotaProfileSpec(&quotaprofile.Spec, field.NewPath("spec")); len(errs) > 0 {
+		quotaprofilelog.Info("validation failed", "reason", "invalid resource specs", "errors", errs.ToAggregate().Error())
+		return nil, apierrors.NewInvalid(quotav1alpha1.GroupVersion.WithKind("QuotaProfile").GroupKind(), quotaprofile.Name, errs)
 	}
 
-	if quotaprofile.Spec.NamespaceSelector.MatchLabels != nil && quotaprofile.Spec.NamespaceSelector.MatchName != nil {
-		quotaprofilelog.Info("validation failed", "reason", "both selectors specified")
-		return nil, fmt.Errorf("only one of namespaceSelector.matchLabels or namespaceSelector.matchName can be set")
-	}
-
-	// this is a limitation of this operator, and can be removed in the future
-	if quotaprofile.Spec.NamespaceSelector.MatchLabels != nil {
-		if len(quotaprofile.Spec.NamespaceSelector.MatchLabels) > 1 {
-			quotaprofilelog.Info("validation failed", "reason", "too many labels", "count", len(quotaprofile.Spec.NamespaceSelector.MatchLabels))
-			return nil, fmt.Errorf("only one label can be used in namespaceSelector.matchLabels")
+	if !v.Offline {
+		errs, err := validateObjectTemplates(ctx, C, quotaprofile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate object templates: %w", err)
+		}
+		if len(errs) > 0 {
+			quotaprofilelog.Info("validation failed", "reason", "invalid object templates", "errors", errs.ToAggregate().Error())
+			return nil, apierrors.NewInvalid(quotav1alpha1.GroupVersion.WithKind("QuotaProfile").GroupKind(), quotaprofile.Name, errs)
 		}
 	}
 
@@ -117,38 +127,107 @@ func (v *QuotaProfileCustomValidator) validate(ctx context.Context, obj runtime.
 		return nil, fmt.Errorf("failed to list quota profiles: %w", err)
 	}
 
-	// if this quota has name in selector, check if other profiles have same name
-	if quotaprofile.Spec.NamespaceSelector.MatchName != nil {
-		for _, profile := range quotaProfiles.Items {
-			// skip if the profile is the same
-			if profile.Namespace == quotaprofile.Namespace && profile.Name == quotaprofile.Name {
-				continue
-			}
-			if profile.Spec.NamespaceSelector.MatchName != nil && *profile.Spec.NamespaceSelector.MatchName == *quotaprofile.Spec.NamespaceSelector.MatchName {
-				quotaprofilelog.Info("validation failed", "reason", "duplicate matchName", "matchName", *quotaprofile.Spec.NamespaceSelector.MatchName)
-				return nil, fmt.Errorf("quota profile with matchName %s already exists: %s/%s", *quotaprofile.Spec.NamespaceSelector.MatchName, profile.Namespace, profile.Name)
-			}
+	warnings, err := validateOverlaps(quotaprofile, quotaProfiles.Items)
+	if err != nil {
+		quotaprofilelog.Info("validation failed", "reason", "overlapping selectors rejected by conflict policy")
+		return nil, err
+	}
+
+	usageWarnings, err := v.validateUsage(ctx, quotaprofile)
+	if err != nil {
+		quotaprofilelog.Info("validation failed", "reason", "hard limits below current usage")
+		return nil, err
+	}
+	warnings = append(warnings, usageWarnings...)
+
+	quotaprofilelog.Info("validation successful", "name", quotaprofile.GetName(), "namespace", quotaprofile.GetNamespace())
+	return warnings, nil
+}
+
+// validateOverlaps warns about the other profiles whose selectors may select the same namespaces as the profile
+// with the same precedence, for those namespaces the winner is decided by index.Compare. The overlaps are
+// rejected instead when either profile has the Reject conflict policy.
+func validateOverlaps(quotaprofile *quotav1alpha1.QuotaProfile, quotaProfiles []quotav1alpha1.QuotaProfile) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var rejected []string
+	for _, profile := range quotaProfiles {
+		if profile.Namespace == quotaprofile.Namespace && profile.Name == quotaprofile.Name {
+			continue
+		}
+		if profile.Spec.Precedence != quotaprofile.Spec.Precedence || !index.SelectorsOverlap(profile.Spec.NamespaceSelector, quotaprofile.Spec.NamespaceSelector) {
+			continue
 		}
+		quotaprofilelog.Info("overlapping namespace selectors", "quotaProfile", quotaprofile.Name, "other", index.ProfileRef(&profile))
+		overlap := fmt.Sprintf("namespaceSelector %s overlaps with %s of quota profile %s with the same precedence %d",
+			index.SelectorString(quotaprofile.Spec.NamespaceSelector), index.SelectorString(profile.Spec.NamespaceSelector), index.ProfileRef(&profile), profile.Spec.Precedence)
+		if quotaprofile.Spec.ConflictPolicy == quotav1alpha1.ConflictPolicyReject || profile.Spec.ConflictPolicy == quotav1alpha1.ConflictPolicyReject {
+			rejected = append(rejected, overlap)
+			continue
+		}
+
+		winner := quotaprofile
+		result, rule := index.Compare(quotaprofile, &profile)
+		if result < 0 {
+			winner = &profile
+		}
+		warnings = append(warnings, fmt.Sprintf("%s, namespaces selected by both are bound to quota profile %s: %s", overlap, index.ProfileRef(winner), rule))
+	}
+	if len(rejected) > 0 {
+		return nil, fmt.Errorf("%s, which the conflict policy Reject does not allow", strings.Join(rejected, "; "))
+	}
+	return warnings, nil
+}
+
+// validateUsage compares the new hard limits against the current usage of the resource quotas managed by the
+// profile, including the budget share, the applied recommendations and the approved QuotaRequests of their
+// namespaces, like the controller renders them. Depending on the shrink policy, exceeded limits are returned as
+// warnings or reject the profile.
+func (v *QuotaProfileCustomValidator) validateUsage(ctx context.Context, quotaprofile *quotav1alpha1.QuotaProfile) (admission.Warnings, error) {
+	rqs := &v1.ResourceQuotaList{}
+	if err := C.List(ctx, rqs, client.MatchingLabels{quotav1alpha1.QuotaProfileLabelKey: quota.ProfileID(quotaprofile.Namespace, quotaprofile.Name)}); err != nil {
+		quotaprofilelog.Error(err, "failed to list managed resource quotas")
+		return nil, fmt.Errorf("failed to list managed resource quotas: %w", err)
 	}
 
-	// if this quota has labels in selector, check if other profiles have same labels
-	if quotaprofile.Spec.NamespaceSelector.MatchLabels != nil {
-		keys := lo.Keys(quotaprofile.Spec.NamespaceSelector.MatchLabels)
-		for _, profile := range quotaProfiles.Items {
-			// skip if the profile is the same
-			if profile.Namespace == quotaprofile.Namespace && profile.Name == quotaprofile.Name {
-				continue
+	indexes := lo.SliceToMap(lo.Range(quota.ResourceQuotaCount(quotaprofile)), func(i int) (string, int) {
+		return quota.ResourceQuotaName(quotaprofile.Namespace, quotaprofile.Name, i), i
+	})
+	adjustments := map[string]quota.Adjustments{}
+	var exceeded []string
+	for _, rq := range rqs.Items {
+		i, managed := indexes[rq.Name]
+		if !managed {
+			continue
+		}
+		if _, ok := adjustments[rq.Namespace]; !ok {
+			nsAdjustments, err := quota.AdjustmentsFor(ctx, C, quotaprofile, rq.Namespace)
+			if err != nil {
+				quotaprofilelog.Error(err, "failed to compute hard limits", "namespace", rq.Namespace)
+				return nil, fmt.Errorf("failed to compute the hard limits of namespace %s: %w", rq.Namespace, err)
 			}
-			if profile.Spec.NamespaceSelector.MatchLabels != nil {
-				profileKeys := lo.Keys(profile.Spec.NamespaceSelector.MatchLabels)
-				if profileKeys[0] == keys[0] {
-					quotaprofilelog.Info("validation failed", "reason", "duplicate matchLabels", "matchLabels", quotaprofile.Spec.NamespaceSelector.MatchLabels)
-					return nil, fmt.Errorf("quota profile with matchLabels %v already exists: %s/%s", quotaprofile.Spec.NamespaceSelector.MatchLabels, profile.Namespace, profile.Name)
-				}
+			adjustments[rq.Namespace] = nsAdjustments
+		}
+		for resourceName, hard := range quota.Hard(quotaprofile, i, adjustments[rq.Namespace]) {
+			used, ok := rq.Status.Used[resourceName]
+			if ok && used.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("namespace %s uses %s of %s, above the new hard limit %s", rq.Namespace, used.String(), resourceName, hard.String()))
 			}
 		}
 	}
 
-	quotaprofilelog.Info("validation successful", "name", quotaprofile.GetName(), "namespace", quotaprofile.GetNamespace())
-	return nil, nil
+	if len(exceeded) == 0 {
+		return nil, nil
+	}
+	sort.Strings(exceeded)
+
+	switch quotaprofile.Spec.ShrinkPolicy {
+	case quotav1alpha1.QuotaShrinkPolicyBlock:
+		return nil, fmt.Errorf("hard limits are below the current usage: %s", strings.Join(exceeded, "; "))
+	case quotav1alpha1.QuotaShrinkPolicyMax:
+		return lo.Map(exceeded, func(e string, _ int) string {
+			return e + ", the hard limit will be raised to the current usage"
+		}), nil
+	default:
+		return exceeded, nil
+	}
 }
@@ -20,11 +20,25 @@ import (
 	"context"
 
 	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/abdullah599/namespace-quota-operator/internal/index"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var _ = Describe("QuotaProfile Webhook", func() {
@@ -92,19 +106,111 @@ var _ = Describe("QuotaProfile Webhook", func() {
 	})
 
 	Context("When creating QuotaProfile", func() {
-		It("Should deny creation if no namespace selector is specified", func() {
-			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{}
-			Expect(validator.ValidateCreate(ctx, obj)).Error().To(HaveOccurred())
+		var originalClient client.Client
+
+		BeforeEach(func() {
+			s := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+			Expect(quotav1alpha1.AddToScheme(s)).To(Succeed())
+
+			byName := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "by-name", Namespace: "default"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchName: ptr("taken-ns")},
+				},
+			}
+			byLabel := &quotav1alpha1.QuotaProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "by-label", Namespace: "other"},
+				Spec: quotav1alpha1.QuotaProfileSpec{
+					NamespaceSelector: quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}},
+				},
+			}
+
+			originalClient = C
+			C = fake.NewClientBuilder().WithScheme(s).WithObjects(byName, byLabel).Build()
 		})
 
-		It("Should deny creation if both matchLabels and matchName are specified", func() {
-			obj.Spec.NamespaceSelector.MatchName = ptr("test-ns")
-			Expect(validator.ValidateCreate(ctx, obj)).Error().To(HaveOccurred())
+		AfterEach(func() {
+			C = originalClient
 		})
 
-		It("Should deny creation if matchLabels has more than one label", func() {
-			obj.Spec.NamespaceSelector.MatchLabels["extra"] = "label"
-			Expect(validator.ValidateCreate(ctx, obj)).Error().To(HaveOccurred())
+		It("Should report another profile that selects the same namespace name by the conflict policy", func() {
+			obj.Spec.Precedence = 0
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchName: ptr("taken-ns")}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("namespaceSelector name=taken-ns overlaps with name=taken-ns of quota profile default/by-name")))
+
+			By("rejecting conflicts")
+			obj.Spec.ConflictPolicy = quotav1alpha1.ConflictPolicyReject
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("conflict policy Reject")))
+
+			By("selecting another namespace")
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchName: ptr("other-ns")}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeEmpty())
+		})
+
+		It("Should report another profile that selects by the same labels by the conflict policy", func() {
+			obj.Spec.Precedence = 0
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "a"}}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("namespaceSelector label team=a overlaps with label team=a of quota profile other/by-label")))
+
+			By("rejecting conflicts")
+			obj.Spec.ConflictPolicy = quotav1alpha1.ConflictPolicyReject
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("conflict policy Reject")))
+
+			By("selecting another value of the same label")
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchLabels: map[string]string{"team": "b"}}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeEmpty())
+
+			By("excluding the value of the other profile with an expression")
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"a"}},
+			}}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeEmpty())
+		})
+
+		It("Should warn about overlapping selectors with the same precedence", func() {
+			obj.Spec.Precedence = 0
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{
+				MatchAnnotations: map[string]string{"owner": "alice"},
+				MatchNamePrefix:  ptr("team-"),
+			}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(And(
+				ContainSubstring("overlaps with label team=a of quota profile other/by-label"),
+				ContainSubstring("same precedence 0"),
+				ContainSubstring("selects the namespace by name prefix team-"),
+			)))
+
+			By("raising the precedence")
+			obj.Spec.Precedence = 10
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeEmpty())
+		})
+
+		It("Should deny overlapping selectors with the same precedence when a profile rejects conflicts", func() {
+			obj.Spec.Precedence = 0
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchAnnotations: map[string]string{"owner": "alice"}}
+			obj.Spec.ConflictPolicy = quotav1alpha1.ConflictPolicyReject
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(And(
+				ContainSubstring("overlaps with label team=a of quota profile other/by-label"),
+				ContainSubstring("conflict policy Reject"),
+			)))
+
+			By("rejecting conflicts on the existing profile instead")
+			obj.Spec.ConflictPolicy = quotav1alpha1.ConflictPolicyAllow
+			byLabel := &quotav1alpha1.QuotaProfile{}
+			Expect(C.Get(ctx, client.ObjectKey{Namespace: "other", Name: "by-label"}, byLabel)).To(Succeed())
+			byLabel.Spec.ConflictPolicy = quotav1alpha1.ConflictPolicyReject
+			Expect(C.Update(ctx, byLabel)).To(Succeed())
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("conflict policy Reject")))
+
+			By("raising the precedence")
+			obj.Spec.Precedence = 10
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
 		})
 
 		It("Should allow creation with valid matchLabels", func() {
@@ -117,14 +223,18 @@ var _ = Describe("QuotaProfile Webhook", func() {
 			}
 			Expect(validator.ValidateCreate(ctx, obj)).Error().ToNot(HaveOccurred())
 		})
-	})
 
-	Context("When updating QuotaProfile", func() {
-		It("Should deny update if removing namespace selector", func() {
-			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{}
-			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(HaveOccurred())
+		It("Should allow creation with allocations within the budget", func() {
+			obj.Spec.Budget = &quotav1alpha1.QuotaBudget{
+				Hard:        v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("10")},
+				Strategy:    quotav1alpha1.BudgetStrategyFixed,
+				Allocations: map[string]v1.ResourceList{"team-a": {v1.ResourceRequestsCPU: resource.MustParse("10")}},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().ToNot(HaveOccurred())
 		})
+	})
 
+	Context("When updating QuotaProfile", func() {
 		It("Should allow update with valid changes", func() {
 			obj.Spec.Precedence = 20
 			obj.Spec.ResourceQuotaSpecs[0].Hard[v1.ResourceCPU] = resource.MustParse("2")
@@ -132,6 +242,279 @@ var _ = Describe("QuotaProfile Webhook", func() {
 		})
 	})
 
+	Context("When validating the embedded specs", func() {
+		It("Should deny creation if a label expression sets values with the Exists operator", func() {
+			obj.Spec.NamespaceSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpExists, Values: []string{"gold"}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.namespaceSelector.matchExpressions[0].values"))
+		})
+
+		It("Should deny creation if a LimitRange min is greater than max", func() {
+			obj.Spec.LimitRangeSpecs[0].Limits[0].Min = v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.limitRangeSpecs[0].limits[0].min[cpu]"))
+		})
+
+		It("Should deny creation if a LimitRange defaultRequest is greater than default", func() {
+			obj.Spec.LimitRangeSpecs[0].Limits[0].DefaultRequest[v1.ResourceMemory] = resource.MustParse("1Gi")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.limitRangeSpecs[0].limits[0].defaultRequest[memory]"))
+		})
+
+		It("Should deny creation if a ResourceQuota uses an unknown resource name", func() {
+			obj.Spec.ResourceQuotaSpecs[0].Hard["cpus"] = resource.MustParse("1")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.resourceQuotaSpecs[0].hard[cpus]"))
+		})
+
+		It("Should deny creation if a ResourceQuota scope does not fit the hard resources", func() {
+			obj.Spec.ResourceQuotaSpecs[0].Scopes = []v1.ResourceQuotaScope{v1.ResourceQuotaScopeBestEffort}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.resourceQuotaSpecs[0].scopes"))
+		})
+
+		It("Should deny creation if the aggregate hard limits use an unknown resource name", func() {
+			obj.Spec.AggregateHard = v1.ResourceList{"cpus": resource.MustParse("1")}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.aggregateHard[cpus]"))
+		})
+
+		It("Should deny creation if a recommendation min is above its max", func() {
+			obj.Spec.Recommendation = &quotav1alpha1.RecommendationPolicy{
+				Min: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("4")},
+				Max: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("2")},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.recommendation.min[requests.cpu]"))
+		})
+	})
+
+	Context("When validating the budget", func() {
+		BeforeEach(func() {
+			obj.Spec.Budget = &quotav1alpha1.QuotaBudget{
+				Hard:     v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("10")},
+				Strategy: quotav1alpha1.BudgetStrategyFixed,
+				Allocations: map[string]v1.ResourceList{
+					"team-a": {v1.ResourceRequestsCPU: resource.MustParse("4")},
+				},
+			}
+		})
+
+		It("Should deny creation if the budget uses an unknown resource name", func() {
+			obj.Spec.Budget.Hard["cpus"] = resource.MustParse("1")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.budget.hard[cpus]"))
+		})
+
+		It("Should deny creation if an allocation uses a resource outside the budget", func() {
+			obj.Spec.Budget.Allocations["team-a"][v1.ResourceRequestsMemory] = resource.MustParse("1Gi")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("resource requests.memory is not part of the budget"))
+		})
+
+		It("Should deny creation if the allocations exceed the budget", func() {
+			obj.Spec.Budget.Allocations["team-b"] = v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("7")}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("allocations of requests.cpu add up to more than the budget of 10"))
+		})
+	})
+
+	Context("When updating QuotaProfile below the current usage", func() {
+		var originalClient client.Client
+
+		BeforeEach(func() {
+			s := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+			Expect(quotav1alpha1.AddToScheme(s)).To(Succeed())
+
+			rq := &v1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default-test-profile-0-rq",
+					Namespace: "team-a",
+					Labels:    map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.test-profile"},
+				},
+				Status: v1.ResourceQuotaStatus{
+					Used: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			}
+
+			originalClient = C
+			C = fake.NewClientBuilder().WithScheme(s).WithObjects(rq).Build()
+		})
+
+		AfterEach(func() {
+			C = originalClient
+		})
+
+		It("Should warn about exceeded namespaces with the Warn policy", func() {
+			obj.Spec.ShrinkPolicy = quotav1alpha1.QuotaShrinkPolicyWarn
+			warnings, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("team-a"))
+		})
+
+		It("Should deny update with the Block policy", func() {
+			obj.Spec.ShrinkPolicy = quotav1alpha1.QuotaShrinkPolicyBlock
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(HaveOccurred())
+		})
+
+		It("Should warn that the hard limit is raised with the Max policy", func() {
+			obj.Spec.ShrinkPolicy = quotav1alpha1.QuotaShrinkPolicyMax
+			warnings, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("raised to the current usage")))
+		})
+
+		It("Should add the resources of approved quota requests to the hard limits", func() {
+			obj.Spec.ShrinkPolicy = quotav1alpha1.QuotaShrinkPolicyBlock
+			obj.Spec.QuotaRequests = &quotav1alpha1.QuotaRequestPolicy{Ceiling: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}}
+			Expect(C.Create(ctx, &quotav1alpha1.QuotaRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "load-test", Namespace: "team-a"},
+				Spec:       quotav1alpha1.QuotaRequestSpec{Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+				Status:     quotav1alpha1.QuotaRequestStatus{Phase: quotav1alpha1.QuotaRequestApproved, QuotaProfile: "default/test-profile"},
+			})).To(Succeed())
+
+			warnings, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should compare the budget resource quota with the share of the namespace", func() {
+			s := C.Scheme()
+			C = fake.NewClientBuilder().WithScheme(s).
+				WithIndex(&v1.Namespace{}, index.NamespaceLabelField, index.NamespaceLabels).
+				WithObjects(
+					&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.test-profile"}}},
+					&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.test-profile"}}},
+					&v1.ResourceQuota{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "default-test-profile-1-rq",
+							Namespace: "team-a",
+							Labels:    map[string]string{quotav1alpha1.QuotaProfileLabelKey: "default.test-profile"},
+						},
+						Status: v1.ResourceQuotaStatus{Used: v1.ResourceList{v1.ResourceRequestsMemory: resource.MustParse("3Gi")}},
+					},
+				).Build()
+			obj.Spec.Budget = &quotav1alpha1.QuotaBudget{Hard: v1.ResourceList{v1.ResourceRequestsMemory: resource.MustParse("4Gi")}}
+
+			warnings, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf("namespace team-a uses 3Gi of requests.memory, above the new hard limit 2Gi"))
+		})
+	})
+
+	Context("When the QuotaProfile has object templates", func() {
+		var (
+			originalClient client.Client
+			reviews        []authorizationv1.SubjectAccessReviewSpec
+			dryRuns        []string
+		)
+
+		requestBy := func(username string) context.Context {
+			return admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: username, Groups: []string{"system:authenticated"}},
+				},
+			})
+		}
+
+		roleBindingTemplate := func(roleName string) quotav1alpha1.ObjectTemplate {
+			return quotav1alpha1.ObjectTemplate{Template: runtime.RawExtension{Raw: []byte(`{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind": "RoleBinding",
+				"roleRef": {"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "` + roleName + `"},
+				"subjects": [{"kind": "Group", "name": "team-a"}]
+			}`)}}
+		}
+
+		BeforeEach(func() {
+			s := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+			Expect(quotav1alpha1.AddToScheme(s)).To(Succeed())
+			reviews, dryRuns = nil, nil
+
+			// alice may bind the view ClusterRole only, the API server rejects PodDisruptionBudgets without a selector
+			originalClient = C
+			C = fake.NewClientBuilder().WithScheme(s).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+						reviews = append(reviews, review.Spec)
+						attributes := review.Spec.ResourceAttributes
+						review.Status.Allowed = review.Spec.User == "alice" && (attributes.Verb == "create" || attributes.Name == "view")
+						return nil
+					}
+					dryRuns = append(dryRuns, obj.GetObjectKind().GroupVersionKind().Kind+" "+obj.GetNamespace())
+					if u, ok := obj.(*unstructured.Unstructured); ok && u.GetKind() == "PodDisruptionBudget" {
+						if _, found, _ := unstructured.NestedMap(u.Object, "spec", "selector"); !found {
+							return apierrors.NewInvalid(policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget").GroupKind(), u.GetName(),
+								field.ErrorList{field.Required(field.NewPath("spec", "selector"), "")})
+						}
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+		})
+
+		AfterEach(func() {
+			C = originalClient
+		})
+
+		It("Should allow a RoleBinding of a role the user may bind in all namespaces", func() {
+			obj.Spec.ObjectTemplates = []quotav1alpha1.ObjectTemplate{roleBindingTemplate("view")}
+			Expect(validator.ValidateCreate(requestBy("alice"), obj)).Error().NotTo(HaveOccurred())
+
+			Expect(reviews).To(HaveLen(2))
+			Expect(reviews[1].ResourceAttributes.Verb).To(Equal("bind"))
+			Expect(reviews[1].ResourceAttributes.Resource).To(Equal("clusterroles"))
+			Expect(reviews[1].ResourceAttributes.Name).To(Equal("view"))
+			Expect(reviews[1].ResourceAttributes.Namespace).To(BeEmpty())
+			Expect(dryRuns).To(ConsistOf("RoleBinding default"))
+		})
+
+		It("Should deny a RoleBinding of a role the user may not bind", func() {
+			obj.Spec.NamespaceSelector = quotav1alpha1.NamespaceSelector{MatchName: ptr("kube-system")}
+			obj.Spec.ObjectTemplates = []quotav1alpha1.ObjectTemplate{roleBindingTemplate("cluster-admin")}
+			_, err := validator.ValidateCreate(requestBy("alice"), obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.objectTemplates[0].template.roleRef: Forbidden: user alice is not allowed to bind ClusterRole cluster-admin in namespace kube-system"))
+			Expect(reviews[1].ResourceAttributes.Namespace).To(Equal("kube-system"))
+			Expect(dryRuns).To(BeEmpty())
+		})
+
+		It("Should deny a RoleBinding when the user may not create RoleBindings", func() {
+			obj.Spec.ObjectTemplates = []quotav1alpha1.ObjectTemplate{roleBindingTemplate("view")}
+			_, err := validator.ValidateCreate(requestBy("bob"), obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("user bob is not allowed to create rolebindings in all namespaces"))
+		})
+
+		It("Should deny templates the API server rejects", func() {
+			obj.Spec.ObjectTemplates = []quotav1alpha1.ObjectTemplate{{Template: runtime.RawExtension{Raw: []byte(`{
+				"apiVersion": "policy/v1",
+				"kind": "PodDisruptionBudget",
+				"spec": {"maxUnavailable": 1}
+			}`)}}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.objectTemplates[0].template"))
+			Expect(err.Error()).To(ContainSubstring("spec.selector: Required value"))
+			Expect(reviews).To(BeEmpty())
+		})
+	})
+
 })
 
 func ptr(s string) *string {
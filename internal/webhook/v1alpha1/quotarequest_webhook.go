@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+	"github.com/samber/lo"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var quotarequestlog = logf.Log.WithName("quotarequest-resource")
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// SetupQuotaRequestWebhookWithManager registers the webhook for QuotaRequest in the manager.
+func SetupQuotaRequestWebhookWithManager(mgr ctrl.Manager) error {
+	quotarequestlog.Info("setting up quotarequest webhook with manager")
+	return ctrl.NewWebhookManagedBy(mgr).For(&quotav1alpha1.QuotaRequest{}).
+		WithValidator(&QuotaRequestCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-quota-dev-operator-v1alpha1-quotarequest,mutating=false,failurePolicy=fail,sideEffects=None,groups=quota.dev.operator,resources=quotarequests,verbs=create;update,versions=v1alpha1,name=vquotarequest-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// QuotaRequestCustomValidator struct is responsible for validating the QuotaRequest resource
+// when it is created, updated, or deleted. Only users with the approve verb on quotarequests in the namespace
+// may set the approved-by annotation, and only to their own username.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+type QuotaRequestCustomValidator struct {
+	// Client creates the SubjectAccessReviews of approvers
+	Client client.Client
+}
+
+var _ webhook.CustomValidator = &QuotaRequestCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type QuotaRequest.
+func (v *QuotaRequestCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	quotaRequest, ok := obj.(*quotav1alpha1.QuotaRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a QuotaRequest object but got %T", obj)
+	}
+	return nil, v.validateApprover(ctx, quotaRequest, "")
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type QuotaRequest.
+func (v *QuotaRequestCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldRequest, ok := oldObj.(*quotav1alpha1.QuotaRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a QuotaRequest object for the oldObj but got %T", oldObj)
+	}
+	quotaRequest, ok := newObj.(*quotav1alpha1.QuotaRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a QuotaRequest object for the newObj but got %T", newObj)
+	}
+	return nil, v.validateApprover(ctx, quotaRequest, oldRequest.Annotations[quotav1alpha1.QuotaRequestApprovedByAnnotationKey])
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type QuotaRequest.
+func (v *QuotaRequestCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateApprover checks that a user who sets or changes the approved-by annotation sets it to their own
+// username and is allowed to approve quota requests in the namespace.
+func (v *QuotaRequestCustomValidator) validateApprover(ctx context.Context, quotaRequest *quotav1alpha1.QuotaRequest, oldApprover string) error {
+	approver := quotaRequest.Annotations[quotav1alpha1.QuotaRequestApprovedByAnnotationKey]
+	if approver == "" || approver == oldApprover {
+		return nil
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		quotarequestlog.Error(err, "failed to get request from context")
+		return err
+	}
+	user := req.UserInfo
+	fldPath := field.NewPath("metadata", "annotations").Key(quotav1alpha1.QuotaRequestApprovedByAnnotationKey)
+	if approver != user.Username {
+		return apierrors.NewInvalid(quotav1alpha1.GroupVersion.WithKind("QuotaRequest").GroupKind(), quotaRequest.Name, field.ErrorList{
+			field.Invalid(fldPath, approver, fmt.Sprintf("must be the username of the approver, %s", user.Username)),
+		})
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra: lo.MapValues(user.Extra, func(value authenticationv1.ExtraValue, _ string) authorizationv1.ExtraValue {
+				return authorizationv1.ExtraValue(value)
+			}),
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: quotaRequest.Namespace,
+				Verb:      "approve",
+				Group:     quotav1alpha1.GroupVersion.Group,
+				Resource:  "quotarequests",
+				Name:      quotaRequest.Name,
+			},
+		},
+	}
+	if err := v.Client.Create(ctx, review); err != nil {
+		quotarequestlog.Error(err, "failed to review access of approver", "user", user.Username)
+		return err
+	}
+	if !review.Status.Allowed {
+		quotarequestlog.Info("denying approval of quota request", "namespace", quotaRequest.Namespace, "name", quotaRequest.Name, "user", user.Username)
+		return apierrors.NewForbidden(quotav1alpha1.GroupVersion.WithResource("quotarequests").GroupResource(), quotaRequest.Name,
+			fmt.Errorf("user %s is not allowed to approve quota requests in namespace %s", user.Username, quotaRequest.Namespace))
+	}
+	return nil
+}
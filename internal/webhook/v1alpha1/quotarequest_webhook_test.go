@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	quotav1alpha1 "github.com/abdullah599/namespace-quota-operator/api/v1alpha1"
+)
+
+var _ = Describe("QuotaRequest Webhook", func() {
+	var (
+		validator QuotaRequestCustomValidator
+		obj       *quotav1alpha1.QuotaRequest
+		reviews   []authorizationv1.SubjectAccessReviewSpec
+	)
+
+	requestBy := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.TODO(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: username, Groups: []string{"system:authenticated"}},
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		reviews = nil
+
+		// only alice may approve quota requests
+		c := fake.NewClientBuilder().WithScheme(s).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+					reviews = append(reviews, review.Spec)
+					review.Status.Allowed = review.Spec.User == "alice"
+					return nil
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+		validator = QuotaRequestCustomValidator{Client: c}
+
+		obj = &quotav1alpha1.QuotaRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "more-cpu", Namespace: "team-a"},
+			Spec: quotav1alpha1.QuotaRequestSpec{
+				Resources: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("4")},
+			},
+		}
+	})
+
+	It("Should allow creation without the approved-by annotation", func() {
+		_, err := validator.ValidateCreate(requestBy("bob"), obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviews).To(BeEmpty())
+	})
+
+	It("Should allow an approver to approve with their own username", func() {
+		newObj := obj.DeepCopy()
+		newObj.Annotations = map[string]string{quotav1alpha1.QuotaRequestApprovedByAnnotationKey: "alice"}
+		_, err := validator.ValidateUpdate(requestBy("alice"), obj, newObj)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reviews).To(HaveLen(1))
+		Expect(reviews[0].ResourceAttributes.Verb).To(Equal("approve"))
+		Expect(reviews[0].ResourceAttributes.Resource).To(Equal("quotarequests"))
+		Expect(reviews[0].ResourceAttributes.Namespace).To(Equal("team-a"))
+	})
+
+	It("Should deny users without the approve verb", func() {
+		newObj := obj.DeepCopy()
+		newObj.Annotations = map[string]string{quotav1alpha1.QuotaRequestApprovedByAnnotationKey: "bob"}
+		_, err := validator.ValidateUpdate(requestBy("bob"), obj, newObj)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("user bob is not allowed to approve quota requests in namespace team-a"))
+	})
+
+	It("Should deny approvals in the name of another user", func() {
+		obj.Annotations = map[string]string{quotav1alpha1.QuotaRequestApprovedByAnnotationKey: "alice"}
+		_, err := validator.ValidateCreate(requestBy("bob"), obj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(reviews).To(BeEmpty())
+	})
+
+	It("Should allow other updates of approved requests", func() {
+		obj.Annotations = map[string]string{quotav1alpha1.QuotaRequestApprovedByAnnotationKey: "alice"}
+		newObj := obj.DeepCopy()
+		newObj.Labels = map[string]string{"team": "a"}
+		_, err := validator.ValidateUpdate(requestBy("bob"), obj, newObj)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
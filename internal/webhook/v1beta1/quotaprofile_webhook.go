@@ -0,0 +1,36 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	quotav1beta1 "github.com/abdullah599/namespace-quota-operator/api/v1beta1"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var quotaprofilelog = logf.Log.WithName("quotaprofile-resource")
+
+// SetupQuotaProfileWebhookWithManager registers the conversion webhook for QuotaProfile in the manager.
+// The other versions of QuotaProfile convert to and from v1beta1, which is the storage version.
+func SetupQuotaProfileWebhookWithManager(mgr ctrl.Manager) error {
+	quotaprofilelog.Info("setting up quotaprofile conversion webhook with manager")
+	return ctrl.NewWebhookManagedBy(mgr).For(&quotav1beta1.QuotaProfile{}).
+		Complete()
+}